@@ -19,7 +19,6 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
-	"sigs.k8s.io/cli-utils/pkg/util/factory"
 )
 
 func GetApplyRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
@@ -56,9 +55,21 @@ func GetApplyRunner(factory cmdutil.Factory, invFactory inventory.InventoryClien
 		"Background", "Propagation policy for pruning")
 	cmd.Flags().DurationVar(&r.pruneTimeout, "prune-timeout", time.Duration(0),
 		"Timeout threshold for waiting for all pruned resources to be deleted")
+	cmd.Flags().IntVar(&r.applyConcurrency, "apply-concurrency", 1,
+		"Maximum number of independent resources to apply in parallel.")
+	cmd.Flags().BoolVar(&r.rollbackOnFailure, "rollback-on-failure", false,
+		"If true, revert all applied resources if any resource fails to apply or reconcile.")
+	cmd.Flags().DurationVar(&r.hookTimeout, "hook-timeout", apply.DefaultHookTimeout,
+		"Timeout threshold for waiting for each hook to complete.")
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+	cmd.Flags().BoolVar(&r.inventoryLock, flagutils.InventoryLockFlag, false,
+		"If true, hold a lock on the inventory during the apply, so other clients can't change it at the same time.")
+
+	cmd.Flags().StringVar(&r.applier, "applier", "",
+		"Identity of the applier recorded in the inventory revisions. Defaults to the user and host running the apply.")
+	flagutils.AddStatusEngineFlag(cmd, &r.statusEngine)
 
 	r.Command = cmd
 	return r
@@ -85,6 +96,12 @@ type ApplyRunner struct {
 	prunePropagationPolicy string
 	pruneTimeout           time.Duration
 	inventoryPolicy        string
+	applyConcurrency       int
+	rollbackOnFailure      bool
+	hookTimeout            time.Duration
+	inventoryLock          bool
+	statusEngine           string
+	applier                string
 }
 
 func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
@@ -132,7 +149,7 @@ func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
-	statusPoller, err := factory.NewStatusPoller(r.factory)
+	statusPoller, err := flagutils.NewStatusPoller(r.factory, r.statusEngine)
 	if err != nil {
 		return err
 	}
@@ -140,6 +157,10 @@ func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	locker, err := flagutils.NewInventoryLocker(r.factory, r.inventoryLock)
+	if err != nil {
+		return err
+	}
 
 	// Run the applier. It will return a channel where we can receive updates
 	// to keep track of progress and any issues.
@@ -159,6 +180,11 @@ func (r *ApplyRunner) RunE(cmd *cobra.Command, args []string) error {
 		PrunePropagationPolicy: prunePropPolicy,
 		PruneTimeout:           r.pruneTimeout,
 		InventoryPolicy:        inventoryPolicy,
+		ApplyConcurrency:       r.applyConcurrency,
+		RollbackOnFailure:      r.rollbackOnFailure,
+		HookTimeout:            r.hookTimeout,
+		InventoryLocker:        locker,
+		Applier:                r.applier,
 	})
 
 	// The printer will print updates from the channel. It will block
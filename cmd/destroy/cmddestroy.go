@@ -18,7 +18,6 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
-	"sigs.k8s.io/cli-utils/pkg/util/factory"
 )
 
 // GetDestroyRunner creates and returns the DestroyRunner which stores the cobra command.
@@ -46,6 +45,12 @@ func GetDestroyRunner(factory cmdutil.Factory, invFactory inventory.InventoryCli
 		"Timeout threshold for waiting for all deleted resources to complete deletion")
 	cmd.Flags().StringVar(&r.deletePropagationPolicy, "delete-propagation-policy",
 		"Background", "Propagation policy for deletion")
+	cmd.Flags().BoolVar(&r.inventoryLock, flagutils.InventoryLockFlag, false,
+		"If true, hold a lock on the inventory during the destroy, so other clients can't change it at the same time.")
+	cmd.Flags().BoolVar(&r.inventoryChildren, flagutils.InventoryChildrenFlag, false,
+		"If true, also destroy the child inventories declared by the inventory, before the inventory itself.")
+
+	flagutils.AddStatusEngineFlag(cmd, &r.statusEngine)
 
 	r.Command = cmd
 	return r
@@ -70,6 +75,9 @@ type DestroyRunner struct {
 	deleteTimeout           time.Duration
 	deletePropagationPolicy string
 	inventoryPolicy         string
+	inventoryLock           bool
+	inventoryChildren       bool
+	statusEngine            string
 }
 
 func (r *DestroyRunner) RunE(cmd *cobra.Command, args []string) error {
@@ -102,7 +110,7 @@ func (r *DestroyRunner) RunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	statusPoller, err := factory.NewStatusPoller(r.factory)
+	statusPoller, err := flagutils.NewStatusPoller(r.factory, r.statusEngine)
 	if err != nil {
 		return err
 	}
@@ -110,6 +118,10 @@ func (r *DestroyRunner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	locker, err := flagutils.NewInventoryLocker(r.factory, r.inventoryLock)
+	if err != nil {
+		return err
+	}
 	d, err := apply.NewDestroyer(r.factory, invClient, statusPoller)
 	if err != nil {
 		return err
@@ -122,6 +134,8 @@ func (r *DestroyRunner) RunE(cmd *cobra.Command, args []string) error {
 		DeletePropagationPolicy: deletePropPolicy,
 		InventoryPolicy:         inventoryPolicy,
 		EmitStatusEvents:        printStatusEvents,
+		InventoryLocker:         locker,
+		DestroyChildren:         r.inventoryChildren,
 	})
 
 	// The printer will print updates from the channel. It will block
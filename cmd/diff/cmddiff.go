@@ -4,114 +4,151 @@
 package diff
 
 import (
-	"io/ioutil"
-	"os"
+	"context"
+	"fmt"
 
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"k8s.io/cli-runtime/pkg/resource"
-	"k8s.io/klog/v2"
-	"k8s.io/kubectl/pkg/cmd/diff"
-	"k8s.io/kubectl/pkg/cmd/util"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/apply/diff"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/util/factory"
 )
 
-const tmpDirPrefix = "diff-cmd"
-
-// NewCmdDiff returns cobra command to implement client-side diff of package
-// directory. For each local config file, get the resource in the cluster
-// and diff the local config resource against the resource in the cluster.
-func NewCmdDiff(f util.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
-	options := diff.NewDiffOptions(ioStreams)
+// GetDiffRunner creates and returns the DiffRunner which stores the cobra command.
+func GetDiffRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *DiffRunner {
+	r := &DiffRunner{
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+		ioStreams:  ioStreams,
+	}
 	cmd := &cobra.Command{
 		Use:                   "diff (DIRECTORY | STDIN)",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Diff local config against cluster applied version"),
-		Args:                  cobra.MaximumNArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			cleanupFunc, err := Initialize(options, f, args)
-			defer cleanupFunc()
-			util.CheckErr(err)
-			util.CheckErr(options.Run())
-		},
+		Long: i18n.T(`Run a server-side dry-run of the apply, and show a unified diff between
+each resource in the cluster and the result of the dry-run. Resources that
+would be pruned are included in the diff.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: r.RunE,
 	}
 
-	return cmd
+	cmd.Flags().BoolVar(&r.noPrune, "no-prune", false, "If true, do not show objects that would be pruned.")
+	cmd.Flags().BoolVar(&r.serverSideOptions.ServerSideApply, "server-side", false,
+		"If true, diff the result of a server-side apply.")
+	cmd.Flags().BoolVar(&r.serverSideOptions.ForceConflicts, "force-conflicts", false,
+		"If true during server-side diff, do not report field conflicts.")
+	cmd.Flags().StringVar(&r.serverSideOptions.FieldManager, "field-manager", common.DefaultFieldManager,
+		"If true during server-side diff, sets field owner.")
+	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
+		"It determines the behavior when the resources don't belong to current inventory. Available options "+
+			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+
+	r.Command = cmd
+	return r
+}
+
+// DiffCommand creates the DiffRunner, returning the cobra command associated with it.
+func DiffCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetDiffRunner(f, invFactory, loader, ioStreams).Command
 }
 
-// Initialize fills in the DiffOptions in preparation for DiffOptions.Run().
-// Returns a cleanup function for removing temp files after expanding stdin, or
-// error if there is an error filling in the options or if there
-// is not one argument that is a directory.
-func Initialize(o *diff.DiffOptions, f util.Factory, args []string) (func(), error) {
-	cleanupFunc := func() {}
-	// Validate the only argument is a (package) directory path.
-	filenameFlags, err := common.DemandOneDirectory(args)
+// DiffRunner encapsulates data necessary to run the diff command.
+type DiffRunner struct {
+	Command    *cobra.Command
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+	loader     manifestreader.ManifestLoader
+	ioStreams  genericclioptions.IOStreams
+
+	serverSideOptions common.ServerSideOptions
+	noPrune           bool
+	inventoryPolicy   string
+}
+
+// RunE is the function run from the cobra command.
+func (r *DiffRunner) RunE(cmd *cobra.Command, args []string) error {
+	inventoryPolicy, err := flagutils.ConvertInventoryPolicy(r.inventoryPolicy)
 	if err != nil {
-		return cleanupFunc, err
+		return err
 	}
-	// Process input from stdin
-	if len(args) == 0 {
-		tmpDir, err := createTempDir()
-		if err != nil {
-			return cleanupFunc, err
-		}
-		cleanupFunc = func() {
-			os.RemoveAll(tmpDir)
-		}
-		filenameFlags.Filenames = &[]string{tmpDir}
-		klog.V(6).Infof("stdin diff command temp dir: %s", tmpDir)
-		if err := common.FilterInputFile(os.Stdin, tmpDir); err != nil {
-			return cleanupFunc, err
-		}
-	} else {
-		// We do not want to diff the inventory object. So we expand
-		// the config file paths, excluding the inventory object.
-		filenameFlags, err = common.ExpandPackageDir(filenameFlags)
-		if err != nil {
-			return cleanupFunc, err
-		}
-	}
-	o.FilenameOptions = filenameFlags.ToOptions()
 
-	o.OpenAPISchema, err = f.OpenAPISchema()
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
 	if err != nil {
-		return cleanupFunc, err
+		return err
 	}
-
-	o.DiscoveryClient, err = f.ToDiscoveryClient()
+	objs, err := reader.Read()
 	if err != nil {
-		return cleanupFunc, err
+		return err
 	}
 
-	o.DynamicClient, err = f.DynamicClient()
+	inv, objs, err := r.loader.InventoryInfo(objs)
 	if err != nil {
-		return cleanupFunc, err
+		return err
 	}
 
-	o.DryRunVerifier = resource.NewDryRunVerifier(o.DynamicClient, o.DiscoveryClient)
-
-	o.CmdNamespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	statusPoller, err := factory.NewStatusPoller(r.factory)
 	if err != nil {
-		return cleanupFunc, err
+		return err
 	}
 
-	o.Builder = f.NewBuilder()
-
-	// We don't support server-side apply diffing yet.
-	o.ServerSideApply = false
-	o.ForceConflicts = false
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
 
-	return cleanupFunc, nil
+	d, err := diff.NewDiffer(r.factory, invClient, statusPoller)
+	if err != nil {
+		return err
+	}
+	diffs, err := d.Run(context.Background(), inv, objs, diff.Options{
+		ServerSideOptions: r.serverSideOptions,
+		NoPrune:           r.noPrune,
+		InventoryPolicy:   inventoryPolicy,
+	})
+	if err != nil {
+		return err
+	}
+	return printDiffs(r.ioStreams, diffs)
 }
 
-func createTempDir() (string, error) {
-	// Create a temporary file with the passed prefix in
-	// the default temporary directory.
-	tmpDir, err := ioutil.TempDir("", tmpDirPrefix)
-	if err != nil {
-		return "", err
+// printDiffs writes the unified diff for each object to Out, and the
+// objects that could not be diffed to ErrOut. Like kubectl diff, a
+// DiffError is returned if any of the objects differ, so the command
+// exits with status 1. An error is returned instead if any of the
+// objects could not be diffed.
+func printDiffs(ioStreams genericclioptions.IOStreams, diffs []diff.ObjectDiff) error {
+	var failed int
+	var differ []object.ObjMetadata
+	for _, d := range diffs {
+		if d.Error != nil {
+			failed++
+			fmt.Fprintf(ioStreams.ErrOut, "%s/%s diff failed: %s\n",
+				d.Identifier.GroupKind.Kind, d.Identifier.Name, d.Error)
+			continue
+		}
+		u, err := d.Unified()
+		if err != nil {
+			return err
+		}
+		if u != "" {
+			differ = append(differ, d.Identifier)
+		}
+		fmt.Fprint(ioStreams.Out, u)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d resource(s) failed to diff", failed)
+	}
+	if len(differ) > 0 {
+		return diff.DiffError{Identifiers: differ}
 	}
-	return tmpDir, nil
+	return nil
 }
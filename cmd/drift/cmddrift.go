@@ -0,0 +1,126 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/cmd/printers"
+	"sigs.k8s.io/cli-utils/pkg/apply/drift"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// GetDriftRunner creates and returns the DriftRunner which stores the cobra command.
+func GetDriftRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *DriftRunner {
+	r := &DriftRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "drift (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Report resources that have been changed in the cluster since they were applied"),
+		Long: i18n.T(`Compare the resources in the inventory with the local configuration,
+and report the fields that have been changed in the cluster and who changed them.
+Exits with a non-zero exit code if any of the resources have drifted.`),
+		RunE: r.RunE,
+	}
+
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.fieldManager, "field-manager", common.DefaultFieldManager,
+		"The client owner of the fields being applied.")
+
+	r.Command = cmd
+	return r
+}
+
+// DriftCommand creates the DriftRunner, returning the cobra command associated with it.
+func DriftCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetDriftRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// DriftRunner encapsulates data necessary to run the drift command.
+type DriftRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+	loader     manifestreader.ManifestLoader
+
+	output       string
+	fieldManager string
+}
+
+func (r *DriftRunner) RunE(cmd *cobra.Command, args []string) error {
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	inv, objs, err := r.loader.InventoryInfo(objs)
+	if err != nil {
+		return err
+	}
+
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	d, err := drift.NewDetector(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+	ch := d.Run(context.Background(), inv, objs, drift.Options{
+		FieldManager: r.fieldManager,
+	})
+
+	// Keep track of the resources that have drifted while the
+	// events are passed on to the printer.
+	var drifted []object.ObjMetadata
+	printerCh := make(chan event.Event)
+	go func() {
+		defer close(printerCh)
+		for e := range ch {
+			if e.Type == event.DriftType && e.DriftEvent.Error == nil &&
+				(e.DriftEvent.Operation == event.Drifted || e.DriftEvent.Operation == event.DriftMissing) {
+				drifted = append(drifted, e.DriftEvent.Identifier)
+			}
+			printerCh <- e
+		}
+	}()
+
+	printer := printers.GetPrinter(r.output, r.ioStreams)
+	err = printer.Print(printerCh, common.DryRunNone, false)
+	// The printer returns early on errors. Drain the remaining events,
+	// so neither the goroutine above nor the detector is blocked
+	// sending them.
+	for range printerCh {
+	}
+	if err != nil {
+		return err
+	}
+	if len(drifted) > 0 {
+		return drift.DriftError{Identifiers: drifted}
+	}
+	return nil
+}
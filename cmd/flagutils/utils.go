@@ -6,14 +6,24 @@ package flagutils
 import (
 	"fmt"
 
+	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/util/factory"
 )
 
 const (
 	InventoryPolicyFlag   = "inventory-policy"
 	InventoryPolicyStrict = "strict"
 	InventoryPolicyAdopt  = "adopt"
+	InventoryLockFlag     = "inventory-lock"
+	InventoryChildrenFlag = "inventory-children"
+	StatusRulesFlag       = "status-rules"
+	StatusEngineFlag      = "status-engine"
+	StatusEnginePoll      = "poll"
+	StatusEngineWatch     = "watch"
 )
 
 // ConvertPropagationPolicy converts a propagationPolicy described as a
@@ -44,6 +54,39 @@ func ConvertInventoryPolicy(policy string) (inventory.InventoryPolicy, error) {
 	}
 }
 
+// NewInventoryLocker returns the InventoryLocker that holds the lock of
+// the inventory in a Lease if locking is enabled, or nil if it is not.
+func NewInventoryLocker(factory cmdutil.Factory, enabled bool) (inventory.InventoryLocker, error) {
+	if !enabled {
+		return nil, nil
+	}
+	return inventory.NewLeaseLocker(factory, "")
+}
+
+// AddStatusEngineFlag adds the flag selecting the status engine to the
+// passed command, storing its value in the passed string.
+func AddStatusEngineFlag(cmd *cobra.Command, engine *string) {
+	cmd.Flags().StringVar(engine, StatusEngineFlag, StatusEnginePoll,
+		fmt.Sprintf("How the status of resources is computed, must be one of %q or %q. "+
+			"With %q, resources are watched and status is computed as soon as they change.",
+			StatusEnginePoll, StatusEngineWatch, StatusEngineWatch))
+}
+
+// NewStatusPoller returns the Poller for the given status engine. The
+// poll engine lists the resources at every polling period, while the
+// watch engine watches them and computes status as soon as they change.
+func NewStatusPoller(f cmdutil.Factory, engine string) (poller.Poller, error) {
+	switch engine {
+	case StatusEnginePoll:
+		return factory.NewStatusPoller(f)
+	case StatusEngineWatch:
+		return factory.NewStatusWatcher(f)
+	default:
+		return nil, fmt.Errorf("status engine must be one of %s, %s",
+			StatusEnginePoll, StatusEngineWatch)
+	}
+}
+
 // PathFromArgs returns the path which is a positional arg from args list
 // returns "-" if there is length of args is 0, which implies no path is provided
 func PathFromArgs(args []string) string {
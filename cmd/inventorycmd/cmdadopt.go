@@ -0,0 +1,116 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetAdoptRunner creates and returns the AdoptRunner which stores the cobra command.
+func GetAdoptRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *AdoptRunner {
+	r := &AdoptRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "adopt (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Add live resources to the inventory of a package"),
+		Long: i18n.T(`Add the live resources of a package to its inventory, and set their
+owning-inventory annotation, without applying them. By default all the resources
+of the package are adopted; use --resource to adopt only some of them. Resources
+owned by another inventory are only adopted with --take-ownership. To hand a
+resource from one package to another, release it from the old package first.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: r.RunE,
+	}
+	addResourceFlag(cmd, &r.resources,
+		"Resource of the package to adopt, in the form KIND[.GROUP]/NAME. Can be repeated.")
+	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyAdopt,
+		"It determines the behavior when the resources don't belong to current inventory. Available options "+
+			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+	cmd.Flags().BoolVar(&r.takeOwnership, "take-ownership", false,
+		"If true, also adopt resources owned by another inventory.")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false,
+		"If true, only print the resources that would be adopted.")
+
+	r.Command = cmd
+	return r
+}
+
+// AdoptCommand creates the AdoptRunner, returning the cobra command associated with it.
+func AdoptCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetAdoptRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// AdoptRunner encapsulates data necessary to run the adopt command.
+type AdoptRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+	loader     manifestreader.ManifestLoader
+
+	resources       []string
+	inventoryPolicy string
+	takeOwnership   bool
+	dryRun          bool
+}
+
+func (r *AdoptRunner) RunE(cmd *cobra.Command, args []string) error {
+	policy, err := flagutils.ConvertInventoryPolicy(r.inventoryPolicy)
+	if err != nil {
+		return err
+	}
+	if r.takeOwnership {
+		policy = inventory.AdoptAll
+	}
+	inv, ids, err := readPackage(cmd, r.loader, flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	if len(r.resources) > 0 {
+		ids, err = selectResources(ids, r.resources)
+		if err != nil {
+			return err
+		}
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	adopter, err := inventory.NewAdopter(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+	dryRun := common.DryRunNone
+	suffix := ""
+	if r.dryRun {
+		dryRun = common.DryRunClient
+		suffix = " (dry-run)"
+	}
+	adopted, err := adopter.Adopt(inv, ids, policy, dryRun)
+	if err != nil {
+		return err
+	}
+	for _, id := range adopted {
+		fmt.Fprintf(r.ioStreams.Out, "%s adopted%s\n", id, suffix)
+	}
+	fmt.Fprintf(r.ioStreams.Out, "%d resource(s) adopted into inventory %s/%s%s\n",
+		len(adopted), inv.Namespace(), inv.Name(), suffix)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetDoctorRunner creates and returns the DoctorRunner which stores the cobra command.
+func GetDoctorRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *DoctorRunner {
+	r := &DoctorRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "doctor (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Check the inventory of a package for inconsistencies"),
+		Long: i18n.T(`Check the inventory of a package for inconsistencies, and optionally fix them:
+  - duplicate inventory objects with the same inventory id are merged,
+  - resources whose type is no longer served by the cluster are removed,
+  - resources owned by another inventory are removed,
+  - resources owned by the inventory but missing from it are added.
+Resources are never changed or deleted. Every resource type that can be listed is
+searched for resources missing from the inventory, so this can take a while on
+large clusters.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: r.RunE,
+	}
+	cmd.Flags().BoolVar(&r.fix, "fix", false,
+		"If true, fix the problems found.")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false,
+		"If true, only print the problems that would be fixed.")
+
+	r.Command = cmd
+	return r
+}
+
+// DoctorCommand creates the DoctorRunner, returning the cobra command associated with it.
+func DoctorCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetDoctorRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// DoctorRunner encapsulates data necessary to run the doctor command.
+type DoctorRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+	loader     manifestreader.ManifestLoader
+
+	fix    bool
+	dryRun bool
+}
+
+func (r *DoctorRunner) RunE(cmd *cobra.Command, args []string) error {
+	inv, _, err := readPackage(cmd, r.loader, flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	doctor, err := inventory.NewDoctor(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+	problems, err := doctor.Check(inv)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Fprintf(r.ioStreams.Out, "no problems found in inventory %s/%s\n", inv.Namespace(), inv.Name())
+		return nil
+	}
+	w := printers.GetNewTabWriter(r.ioStreams.Out)
+	fmt.Fprintln(w, "PROBLEM\tNAMESPACE\tKIND\tNAME\tMESSAGE")
+	for _, p := range problems {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Type, p.Identifier.Namespace, p.Identifier.GroupKind,
+			p.Identifier.Name, p.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !r.fix {
+		return nil
+	}
+
+	dryRun := common.DryRunNone
+	suffix := ""
+	if r.dryRun {
+		dryRun = common.DryRunClient
+		suffix = " (dry-run)"
+	}
+	if err := doctor.Fix(inv, problems, dryRun); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.ioStreams.Out, "%d problem(s) fixed in inventory %s/%s%s\n",
+		len(problems), inv.Namespace(), inv.Name(), suffix)
+	return nil
+}
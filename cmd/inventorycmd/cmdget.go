@@ -0,0 +1,120 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// GetGetRunner creates and returns the GetRunner which stores the cobra command.
+func GetGetRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	ioStreams genericclioptions.IOStreams) *GetRunner {
+	r := &GetRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+	}
+	cmd := &cobra.Command{
+		Use:                   "get NAME",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Show the resources in an inventory"),
+		Long: i18n.T(`Show the resources in the inventory object with the passed name, along with
+the result of their last apply and their last observed status if the inventory
+stores them.`),
+		Args: cobra.ExactArgs(1),
+		RunE: r.RunE,
+	}
+	addInventoryKindFlag(cmd, &r.kind)
+
+	r.Command = cmd
+	return r
+}
+
+// GetCommand creates the GetRunner, returning the cobra command associated with it.
+func GetCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetGetRunner(f, invFactory, ioStreams).Command
+}
+
+// GetRunner encapsulates data necessary to run the get command.
+type GetRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+
+	kind string
+}
+
+func (r *GetRunner) RunE(_ *cobra.Command, args []string) error {
+	namespace, err := namespaceFromFlags(r.factory, false)
+	if err != nil {
+		return err
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	invs, err := listInventoryObjs(invClient, r.kind, namespace)
+	if err != nil {
+		return err
+	}
+	var inv inventory.InventoryInfo
+	for _, i := range invs {
+		if i.Name() == args[0] {
+			inv = i
+			break
+		}
+	}
+	if inv == nil {
+		return fmt.Errorf("inventory %s/%s not found", namespace, args[0])
+	}
+
+	objs, err := invClient.GetClusterObjs(inv, common.DryRunNone)
+	if err != nil {
+		return err
+	}
+	statuses, err := invClient.GetClusterObjStatuses(inv)
+	if err != nil {
+		return err
+	}
+	statusByID := make(map[object.ObjMetadata]inventory.ObjectStatus, len(statuses))
+	for _, s := range statuses {
+		statusByID[s.Identifier] = s
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		return objs[i].String() < objs[j].String()
+	})
+
+	fmt.Fprintf(r.ioStreams.Out, "inventory %s/%s (%s) has %d resources\n",
+		inv.Namespace(), inv.Name(), inv.ID(), len(objs))
+	if len(objs) == 0 {
+		return nil
+	}
+	w := printers.GetNewTabWriter(r.ioStreams.Out)
+	fmt.Fprintln(w, "NAMESPACE\tKIND\tNAME\tLAST-APPLY\tSTATUS")
+	for _, id := range objs {
+		s := statusByID[id]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", id.Namespace, id.GroupKind, id.Name,
+			valueOrNone(string(s.ApplyResult)), valueOrNone(s.Status.String()))
+	}
+	return w.Flush()
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// ConfigMapBackend is the name of the ConfigMap inventory backend.
+	ConfigMapBackend = "configmap"
+	// ShardedConfigMapBackend is the name of the sharded ConfigMap
+	// inventory backend.
+	ShardedConfigMapBackend = "sharded-configmap"
+
+	// defaultInventoryKind is the kind of the inventory objects of the
+	// ConfigMap inventory backends.
+	defaultInventoryKind = "ConfigMap"
+)
+
+// DefaultBackends returns the inventory storage backends known to kapply,
+// by name. Callers with other backends, for example a custom resource
+// inventory, pass them to InventoryCommand instead.
+func DefaultBackends() map[string]inventory.InventoryClientFactory {
+	return map[string]inventory.InventoryClientFactory{
+		ConfigMapBackend:        inventory.ClusterInventoryClientFactory{},
+		ShardedConfigMapBackend: inventory.ShardedInventoryClientFactory{},
+	}
+}
+
+// InventoryCommand returns the command grouping the commands that
+// operate on the inventory itself rather than on the objects in it.
+// The passed backends are the inventory storage backends that can be
+// migrated between, by name.
+func InventoryCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	backends map[string]inventory.InventoryClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: i18n.T("Inspect and maintain inventories"),
+		Long:  i18n.T("Inspect and maintain the inventories that track the applied resources."),
+	}
+	cmd.AddCommand(
+		ListCommand(f, invFactory, ioStreams),
+		GetCommand(f, invFactory, ioStreams),
+		OrphansCommand(f, invFactory, ioStreams),
+		MigrateCommand(f, backends, loader, ioStreams),
+		AdoptCommand(f, invFactory, loader, ioStreams),
+		ReleaseCommand(f, invFactory, loader, ioStreams),
+		DoctorCommand(f, invFactory, loader, ioStreams),
+	)
+	return cmd
+}
+
+// addInventoryKindFlag adds the flag for the kind of the inventory
+// objects to the passed command.
+func addInventoryKindFlag(cmd *cobra.Command, kind *string) {
+	cmd.Flags().StringVar(kind, "kind", defaultInventoryKind,
+		"Kind of the inventory objects, in the form kind.group for kinds that are not in the core group.")
+}
+
+// listInventoryObjs returns the inventory objects of the passed kind
+// in the passed namespace, or in all namespaces if it is empty.
+func listInventoryObjs(invClient inventory.InventoryClient, kind, namespace string) ([]inventory.InventoryInfo, error) {
+	lister, ok := invClient.(inventory.InventoryLister)
+	if !ok {
+		return nil, fmt.Errorf("inventory client does not support listing inventories")
+	}
+	objs, err := lister.ListClusterInventoryObjs(schema.ParseGroupKind(kind), namespace)
+	if err != nil {
+		return nil, err
+	}
+	invs := make([]inventory.InventoryInfo, 0, len(objs))
+	for _, obj := range objs {
+		invs = append(invs, inventory.WrapInventoryInfoObj(obj))
+	}
+	return invs, nil
+}
+
+// namespaceFromFlags returns the namespace selected with the kubeconfig
+// flags, or an empty string for all namespaces.
+func namespaceFromFlags(f cmdutil.Factory, allNamespaces bool) (string, error) {
+	if allNamespaces {
+		return "", nil
+	}
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	return namespace, err
+}
+
+// readPackage reads the package at the passed path, returning its
+// inventory and the identifiers of the other resources in it.
+func readPackage(cmd *cobra.Command, loader manifestreader.ManifestLoader,
+	path string) (inventory.InventoryInfo, []object.ObjMetadata, error) {
+	reader, err := loader.ManifestReader(cmd.InOrStdin(), path)
+	if err != nil {
+		return nil, nil, err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	inv, objs, err := loader.InventoryInfo(objs)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids, err := object.UnstructuredsToObjMetas(objs)
+	return inv, ids, err
+}
+
+// addResourceFlag adds the flag selecting resources by kind and name to
+// the passed command.
+func addResourceFlag(cmd *cobra.Command, resources *[]string, usage string) {
+	cmd.Flags().StringArrayVar(resources, "resource", []string{}, usage)
+}
+
+// selectResources returns the passed identifiers that match one of the
+// passed resources, in the form KIND[.GROUP]/NAME. Returns an error if
+// a resource does not match any of the identifiers.
+func selectResources(ids []object.ObjMetadata, resources []string) ([]object.ObjMetadata, error) {
+	var selected []object.ObjMetadata
+	for _, resource := range resources {
+		parts := strings.Split(resource, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid resource %q, must be in the form KIND[.GROUP]/NAME", resource)
+		}
+		gk := schema.ParseGroupKind(parts[0])
+		found := false
+		for _, id := range ids {
+			if strings.EqualFold(id.GroupKind.Kind, gk.Kind) && id.GroupKind.Group == gk.Group && id.Name == parts[1] {
+				selected = append(selected, id)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("resource %q not found", resource)
+		}
+	}
+	return object.Union(selected, nil), nil
+}
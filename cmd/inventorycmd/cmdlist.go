@@ -0,0 +1,87 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+)
+
+// GetListRunner creates and returns the ListRunner which stores the cobra command.
+func GetListRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	ioStreams genericclioptions.IOStreams) *ListRunner {
+	r := &ListRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+	}
+	cmd := &cobra.Command{
+		Use:                   "list",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("List the inventories in the cluster"),
+		Long: i18n.T(`List the inventory objects in the cluster, found by the inventory-id label,
+along with the number of resources in each inventory.`),
+		Args: cobra.NoArgs,
+		RunE: r.RunE,
+	}
+	cmd.Flags().BoolVarP(&r.allNamespaces, "all-namespaces", "A", false,
+		"If true, list the inventories in all namespaces.")
+	addInventoryKindFlag(cmd, &r.kind)
+
+	r.Command = cmd
+	return r
+}
+
+// ListCommand creates the ListRunner, returning the cobra command associated with it.
+func ListCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetListRunner(f, invFactory, ioStreams).Command
+}
+
+// ListRunner encapsulates data necessary to run the list command.
+type ListRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+
+	allNamespaces bool
+	kind          string
+}
+
+func (r *ListRunner) RunE(_ *cobra.Command, _ []string) error {
+	namespace, err := namespaceFromFlags(r.factory, r.allNamespaces)
+	if err != nil {
+		return err
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	invs, err := listInventoryObjs(invClient, r.kind, namespace)
+	if err != nil {
+		return err
+	}
+	if len(invs) == 0 {
+		fmt.Fprintln(r.ioStreams.Out, "no inventories found")
+		return nil
+	}
+	w := printers.GetNewTabWriter(r.ioStreams.Out)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tINVENTORY-ID\tRESOURCES")
+	for _, inv := range invs {
+		objs, err := invClient.GetClusterObjs(inv, common.DryRunNone)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", inv.Namespace(), inv.Name(), inv.ID(), len(objs))
+	}
+	return w.Flush()
+}
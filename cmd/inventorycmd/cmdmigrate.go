@@ -0,0 +1,135 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetMigrateRunner creates and returns the MigrateRunner which stores
+// the cobra command. The passed backends are the inventory storage
+// backends that can be migrated between, by name.
+func GetMigrateRunner(factory cmdutil.Factory, backends map[string]inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *MigrateRunner {
+	r := &MigrateRunner{
+		ioStreams: ioStreams,
+		factory:   factory,
+		backends:  backends,
+		loader:    loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "migrate (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Move an inventory to another storage backend"),
+		Long: i18n.T(`Move the inventory of a package to another storage backend without applying
+the package again. The new inventory is stored first, then the owning-inventory
+annotation of the resources is updated if the inventory id changes, and finally
+the old inventory is deleted. With --to-inventory, the new inventory is described
+by the inventory template of another package, which allows changing the name and
+id of the inventory. Without it, backends that store the inventory in the same
+object, like configmap and sharded-configmap, convert the inventory in place.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: r.RunE,
+	}
+
+	names := backendNames(backends)
+	cmd.Flags().StringVar(&r.from, "from", ConfigMapBackend,
+		fmt.Sprintf("Storage backend of the current inventory, must be one of %s", strings.Join(names, ",")))
+	cmd.Flags().StringVar(&r.to, "to", "",
+		fmt.Sprintf("Storage backend of the new inventory, must be one of %s", strings.Join(names, ",")))
+	cmd.Flags().StringVar(&r.toInventory, "to-inventory", "",
+		"Package directory with the inventory template of the new inventory. Defaults to the migrated package.")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false,
+		"If true, only print the changes that would be made.")
+	_ = cmd.MarkFlagRequired("to")
+
+	r.Command = cmd
+	return r
+}
+
+// MigrateCommand creates the MigrateRunner, returning the cobra command associated with it.
+func MigrateCommand(f cmdutil.Factory, backends map[string]inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetMigrateRunner(f, backends, loader, ioStreams).Command
+}
+
+// MigrateRunner encapsulates data necessary to run the migrate command.
+type MigrateRunner struct {
+	Command   *cobra.Command
+	ioStreams genericclioptions.IOStreams
+	factory   cmdutil.Factory
+	backends  map[string]inventory.InventoryClientFactory
+	loader    manifestreader.ManifestLoader
+
+	from        string
+	to          string
+	toInventory string
+	dryRun      bool
+}
+
+func (r *MigrateRunner) RunE(cmd *cobra.Command, args []string) error {
+	from, found := r.backends[r.from]
+	if !found {
+		return fmt.Errorf("unknown inventory backend %q", r.from)
+	}
+	to, found := r.backends[r.to]
+	if !found {
+		return fmt.Errorf("unknown inventory backend %q", r.to)
+	}
+
+	fromInv, _, err := readPackage(cmd, r.loader, flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	toInv := fromInv
+	if r.toInventory != "" {
+		toInv, _, err = readPackage(cmd, r.loader, r.toInventory)
+		if err != nil {
+			return err
+		}
+	}
+
+	migrator, err := inventory.NewMigrator(r.factory, from, to)
+	if err != nil {
+		return err
+	}
+	dryRun := common.DryRunNone
+	suffix := ""
+	if r.dryRun {
+		dryRun = common.DryRunClient
+		suffix = " (dry-run)"
+	}
+	result, err := migrator.Migrate(fromInv, toInv, dryRun)
+	if err != nil {
+		return err
+	}
+	out := r.ioStreams.Out
+	for _, id := range result.Annotated {
+		fmt.Fprintf(out, "%s owning inventory set to %s%s\n", id, toInv.ID(), suffix)
+	}
+	fmt.Fprintf(out, "inventory %s/%s (%s) migrated to %s/%s (%s) with %d resources%s\n",
+		fromInv.Namespace(), fromInv.Name(), r.from, toInv.Namespace(), toInv.Name(), r.to,
+		len(result.Objects), suffix)
+	return nil
+}
+
+func backendNames(backends map[string]inventory.InventoryClientFactory) []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
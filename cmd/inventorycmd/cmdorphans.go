@@ -0,0 +1,91 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+)
+
+// GetOrphansRunner creates and returns the OrphansRunner which stores the cobra command.
+func GetOrphansRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	ioStreams genericclioptions.IOStreams) *OrphansRunner {
+	r := &OrphansRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+	}
+	cmd := &cobra.Command{
+		Use:                   "orphans",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Find resources whose inventory no longer exists"),
+		Long: i18n.T(`Find the resources whose owning-inventory annotation refers to an inventory
+that no longer exists in the cluster. Every resource type that can be listed is
+searched, so this can take a while on large clusters.`),
+		Args: cobra.NoArgs,
+		RunE: r.RunE,
+	}
+	cmd.Flags().BoolVarP(&r.allNamespaces, "all-namespaces", "A", false,
+		"If true, search the resources in all namespaces, including cluster-scoped resources.")
+	addInventoryKindFlag(cmd, &r.kind)
+
+	r.Command = cmd
+	return r
+}
+
+// OrphansCommand creates the OrphansRunner, returning the cobra command associated with it.
+func OrphansCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetOrphansRunner(f, invFactory, ioStreams).Command
+}
+
+// OrphansRunner encapsulates data necessary to run the orphans command.
+type OrphansRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+
+	allNamespaces bool
+	kind          string
+}
+
+func (r *OrphansRunner) RunE(_ *cobra.Command, _ []string) error {
+	namespace, err := namespaceFromFlags(r.factory, r.allNamespaces)
+	if err != nil {
+		return err
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	// Resources may be owned by an inventory in another namespace, so
+	// the inventories are looked up in all namespaces.
+	invs, err := listInventoryObjs(invClient, r.kind, "")
+	if err != nil {
+		return err
+	}
+	owned, err := inventory.ListOwnedObjects(r.factory, namespace)
+	if err != nil {
+		return err
+	}
+	orphans := inventory.FindOrphans(invs, owned)
+	if len(orphans) == 0 {
+		fmt.Fprintln(r.ioStreams.Out, "no orphaned resources found")
+		return nil
+	}
+	w := printers.GetNewTabWriter(r.ioStreams.Out)
+	fmt.Fprintln(w, "NAMESPACE\tKIND\tNAME\tOWNING-INVENTORY")
+	for _, obj := range orphans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", obj.GetNamespace(), obj.GroupVersionKind().GroupKind(),
+			obj.GetName(), inventory.OwningInventory(obj))
+	}
+	return w.Flush()
+}
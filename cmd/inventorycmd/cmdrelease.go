@@ -0,0 +1,108 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventorycmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetReleaseRunner creates and returns the ReleaseRunner which stores the cobra command.
+func GetReleaseRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *ReleaseRunner {
+	r := &ReleaseRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "release (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Remove resources from the inventory of a package without deleting them"),
+		Long: i18n.T(`Remove resources from the inventory of a package, and remove their
+owning-inventory annotation, without deleting them. Released resources are no
+longer pruned or destroyed with the package, and can be adopted by another package.
+The resources to release are selected with --resource, and don't need to be in the
+package anymore.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: r.RunE,
+	}
+	addResourceFlag(cmd, &r.resources,
+		"Resource in the inventory to release, in the form KIND[.GROUP]/NAME. Can be repeated.")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false,
+		"If true, only print the resources that would be released.")
+	_ = cmd.MarkFlagRequired("resource")
+
+	r.Command = cmd
+	return r
+}
+
+// ReleaseCommand creates the ReleaseRunner, returning the cobra command associated with it.
+func ReleaseCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetReleaseRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// ReleaseRunner encapsulates data necessary to run the release command.
+type ReleaseRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+	loader     manifestreader.ManifestLoader
+
+	resources []string
+	dryRun    bool
+}
+
+func (r *ReleaseRunner) RunE(cmd *cobra.Command, args []string) error {
+	inv, _, err := readPackage(cmd, r.loader, flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	// The resources are selected from the inventory rather than the
+	// package, so resources already removed from the package can be
+	// released.
+	stored, err := invClient.GetClusterObjs(inv, common.DryRunNone)
+	if err != nil {
+		return err
+	}
+	ids, err := selectResources(stored, r.resources)
+	if err != nil {
+		return err
+	}
+	adopter, err := inventory.NewAdopter(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+	dryRun := common.DryRunNone
+	suffix := ""
+	if r.dryRun {
+		dryRun = common.DryRunClient
+		suffix = " (dry-run)"
+	}
+	released, err := adopter.Release(inv, ids, dryRun)
+	if err != nil {
+		return err
+	}
+	for _, id := range released {
+		fmt.Fprintf(r.ioStreams.Out, "%s released%s\n", id, suffix)
+	}
+	fmt.Fprintf(r.ioStreams.Out, "%d resource(s) released from inventory %s/%s%s\n",
+		len(released), inv.Namespace(), inv.Name(), suffix)
+	return nil
+}
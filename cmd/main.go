@@ -15,11 +15,16 @@ import (
 	"sigs.k8s.io/cli-utils/cmd/apply"
 	"sigs.k8s.io/cli-utils/cmd/destroy"
 	"sigs.k8s.io/cli-utils/cmd/diff"
+	"sigs.k8s.io/cli-utils/cmd/drift"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/cmd/initcmd"
+	"sigs.k8s.io/cli-utils/cmd/inventorycmd"
 	"sigs.k8s.io/cli-utils/cmd/preview"
 	"sigs.k8s.io/cli-utils/cmd/status"
+	"sigs.k8s.io/cli-utils/cmd/wait"
 	"sigs.k8s.io/cli-utils/pkg/errors"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
 	"sigs.k8s.io/cli-utils/pkg/util/factory"
 
@@ -50,13 +55,22 @@ func main() {
 	cmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	f := util.NewFactory(matchVersionKubeConfigFlags)
 
+	// Status rules for custom resources are used by every command that
+	// computes the status of resources.
+	var statusRules []string
+	flags.StringSliceVar(&statusRules, flagutils.StatusRulesFlag, nil,
+		"Files with rules to compute the status of custom resources.")
+	cmd.PersistentPreRunE = func(*cobra.Command, []string) error {
+		return registerStatusRules(statusRules)
+	}
+
 	ioStreams := genericclioptions.IOStreams{
 		In:     os.Stdin,
 		Out:    os.Stdout,
 		ErrOut: os.Stderr,
 	}
 
-	names := []string{"init", "apply", "preview", "diff", "destroy", "status"}
+	names := []string{"init", "apply", "preview", "diff", "destroy", "status", "drift", "wait", "inventory"}
 	initCmd := initcmd.NewCmdInit(f, ioStreams)
 	updateHelp(names, initCmd)
 	loader := manifestreader.NewManifestLoader(f)
@@ -65,14 +79,20 @@ func main() {
 	updateHelp(names, applyCmd)
 	previewCmd := preview.PreviewCommand(f, invFactory, loader, ioStreams)
 	updateHelp(names, previewCmd)
-	diffCmd := diff.NewCmdDiff(f, ioStreams)
+	diffCmd := diff.DiffCommand(f, invFactory, loader, ioStreams)
 	updateHelp(names, diffCmd)
 	destroyCmd := destroy.DestroyCommand(f, invFactory, loader, ioStreams)
 	updateHelp(names, destroyCmd)
 	statusCmd := status.StatusCommand(f, invFactory, loader)
 	updateHelp(names, statusCmd)
+	driftCmd := drift.DriftCommand(f, invFactory, loader, ioStreams)
+	updateHelp(names, driftCmd)
+	waitCmd := wait.WaitCommand(f, invFactory, loader, ioStreams)
+	updateHelp(names, waitCmd)
+	inventoryCmd := inventorycmd.InventoryCommand(f, invFactory, inventorycmd.DefaultBackends(), loader, ioStreams)
+	updateHelp(names, inventoryCmd)
 
-	cmd.AddCommand(initCmd, applyCmd, diffCmd, destroyCmd, previewCmd, statusCmd)
+	cmd.AddCommand(initCmd, applyCmd, diffCmd, destroyCmd, previewCmd, statusCmd, driftCmd, waitCmd, inventoryCmd)
 
 	logs.InitLogs()
 	defer logs.FlushLogs()
@@ -82,6 +102,20 @@ func main() {
 	}
 }
 
+// registerStatusRules registers the status rules in the passed files.
+func registerStatusRules(paths []string) error {
+	for _, path := range paths {
+		rules, err := kstatus.LoadRulesFile(path)
+		if err != nil {
+			return err
+		}
+		if err := kstatus.RegisterRules(rules...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // updateHelp replaces `kubectl` help messaging with `kapply` help messaging
 func updateHelp(names []string, c *cobra.Command) {
 	for i := range names {
@@ -57,6 +57,8 @@ func GetPreviewRunner(factory cmdutil.Factory, invFactory inventory.InventoryCli
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt))
+	cmd.Flags().BoolVar(&r.inventoryChildren, flagutils.InventoryChildrenFlag, false,
+		"If true during destroy preview, also preview the destroy of the child inventories declared by the inventory.")
 
 	r.Command = cmd
 	return r
@@ -80,6 +82,7 @@ type PreviewRunner struct {
 	serverSideOptions common.ServerSideOptions
 	output            string
 	inventoryPolicy   string
+	inventoryChildren bool
 }
 
 // RunE is the function run from the cobra command.
@@ -159,6 +162,7 @@ func (r *PreviewRunner) RunE(cmd *cobra.Command, args []string) error {
 		ch = d.Run(inv, apply.DestroyerOptions{
 			InventoryPolicy: inventoryPolicy,
 			DryRunStrategy:  drs,
+			DestroyChildren: r.inventoryChildren,
 		})
 	}
 
@@ -82,6 +82,92 @@ func (ef *formatter) FormatDeleteEvent(de event.DeleteEvent) error {
 	return nil
 }
 
+func (ef *formatter) FormatRollbackEvent(re event.RollbackEvent) error {
+	gk := re.Identifier.GroupKind
+	name := re.Identifier.Name
+
+	if re.Error != nil {
+		ef.print("%s rollback failed: %s", resourceIDToString(gk, name),
+			re.Error.Error())
+		return nil
+	}
+
+	switch re.Operation {
+	case event.RollbackRestored:
+		ef.print("%s rolled back", resourceIDToString(gk, name))
+	case event.RollbackDeleted:
+		ef.print("%s rolled back (deleted)", resourceIDToString(gk, name))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatHookEvent(he event.HookEvent) error {
+	gk := he.Identifier.GroupKind
+	name := he.Identifier.Name
+
+	if he.Error != nil {
+		if he.Operation == event.HookDeleted {
+			ef.print("%s %s hook deletion failed: %s", resourceIDToString(gk, name),
+				he.Phase, he.Error.Error())
+			return nil
+		}
+		ef.print("%s %s hook failed: %s", resourceIDToString(gk, name),
+			he.Phase, he.Error.Error())
+		return nil
+	}
+
+	switch he.Operation {
+	case event.HookCreated:
+		ef.print("%s %s hook created", resourceIDToString(gk, name), he.Phase)
+	case event.HookSucceeded:
+		ef.print("%s %s hook succeeded", resourceIDToString(gk, name), he.Phase)
+	case event.HookDeleted:
+		ef.print("%s %s hook deleted", resourceIDToString(gk, name), he.Phase)
+	case event.HookSkipped:
+		ef.print("%s %s hook skipped", resourceIDToString(gk, name), he.Phase)
+	}
+	return nil
+}
+
+func (ef *formatter) FormatDriftEvent(de event.DriftEvent) error {
+	gk := de.Identifier.GroupKind
+	name := de.Identifier.Name
+
+	if de.Error != nil {
+		ef.print("%s drift detection failed: %s", resourceIDToString(gk, name),
+			de.Error.Error())
+		return nil
+	}
+
+	switch de.Operation {
+	case event.InSync:
+		ef.print("%s in sync", resourceIDToString(gk, name))
+	case event.Drifted:
+		var fields []string
+		for _, f := range de.Fields {
+			if f.Manager == "" {
+				fields = append(fields, f.Field)
+				continue
+			}
+			fields = append(fields, fmt.Sprintf("%s (changed by %s)", f.Field, f.Manager))
+		}
+		ef.print("%s drifted: %s", resourceIDToString(gk, name), strings.Join(fields, ", "))
+	case event.DriftMissing:
+		ef.print("%s missing", resourceIDToString(gk, name))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatInventoryEvent(ie event.InventoryEvent) error {
+	indent := strings.Repeat("  ", ie.Depth)
+	if ie.Parent == "" {
+		ef.print("%sinventory %s/%s", indent, ie.Namespace, ie.Name)
+		return nil
+	}
+	ef.print("%sinventory %s/%s (child of %s)", indent, ie.Namespace, ie.Name, ie.Parent)
+	return nil
+}
+
 func (ef *formatter) FormatErrorEvent(_ event.ErrorEvent) error {
 	return nil
 }
@@ -215,6 +215,207 @@ func TestFormatter_FormatDeleteEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatRollbackEvent(t *testing.T) {
+	testCases := map[string]struct {
+		previewStrategy common.DryRunStrategy
+		event           event.RollbackEvent
+		expected        string
+	}{
+		"resource restored": {
+			previewStrategy: common.DryRunNone,
+			event: event.RollbackEvent{
+				Operation:  event.RollbackRestored,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Object:     createObject("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: "deployment.apps/my-dep rolled back",
+		},
+		"resource deleted": {
+			previewStrategy: common.DryRunNone,
+			event: event.RollbackEvent{
+				Operation:  event.RollbackDeleted,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: "deployment.apps/my-dep rolled back (deleted)",
+		},
+		"resource with rollback error": {
+			previewStrategy: common.DryRunNone,
+			event: event.RollbackEvent{
+				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
+				Error:      fmt.Errorf("this is a test"),
+			},
+			expected: "deployment.apps/my-dep rollback failed: this is a test",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, tc.previewStrategy)
+			err := formatter.FormatRollbackEvent(tc.event)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatHookEvent(t *testing.T) {
+	testCases := map[string]struct {
+		previewStrategy common.DryRunStrategy
+		event           event.HookEvent
+		expected        string
+	}{
+		"hook created": {
+			previewStrategy: common.DryRunNone,
+			event: event.HookEvent{
+				Operation:  event.HookCreated,
+				Phase:      "pre-apply",
+				Identifier: createIdentifier("batch", "Job", "default", "migrate"),
+				Object:     createObject("batch", "Job", "default", "migrate"),
+			},
+			expected: "job.batch/migrate pre-apply hook created",
+		},
+		"hook succeeded": {
+			previewStrategy: common.DryRunNone,
+			event: event.HookEvent{
+				Operation:  event.HookSucceeded,
+				Phase:      "post-apply",
+				Identifier: createIdentifier("batch", "Job", "default", "smoke"),
+			},
+			expected: "job.batch/smoke post-apply hook succeeded",
+		},
+		"hook failed": {
+			previewStrategy: common.DryRunNone,
+			event: event.HookEvent{
+				Operation:  event.HookFailed,
+				Phase:      "pre-apply",
+				Identifier: createIdentifier("batch", "Job", "default", "migrate"),
+				Error:      fmt.Errorf("job failed"),
+			},
+			expected: "job.batch/migrate pre-apply hook failed: job failed",
+		},
+		"hook deletion failed": {
+			previewStrategy: common.DryRunNone,
+			event: event.HookEvent{
+				Operation:  event.HookDeleted,
+				Phase:      "pre-prune",
+				Identifier: createIdentifier("batch", "Job", "default", "backup"),
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expected: "job.batch/backup pre-prune hook deletion failed: forbidden",
+		},
+		"hook skipped": {
+			previewStrategy: common.DryRunClient,
+			event: event.HookEvent{
+				Operation:  event.HookSkipped,
+				Phase:      "pre-apply",
+				Identifier: createIdentifier("batch", "Job", "default", "migrate"),
+			},
+			expected: "job.batch/migrate pre-apply hook skipped (preview)",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, tc.previewStrategy)
+			err := formatter.FormatHookEvent(tc.event)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatDriftEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.DriftEvent
+		expected string
+	}{
+		"in sync": {
+			event: event.DriftEvent{
+				Operation:  event.InSync,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: "deployment.apps/my-dep in sync",
+		},
+		"drifted": {
+			event: event.DriftEvent{
+				Operation:  event.Drifted,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Fields: []event.FieldDrift{
+					{Field: ".spec.replicas", Manager: "kubectl-edit"},
+					{Field: ".metadata.labels.app"},
+				},
+			},
+			expected: "deployment.apps/my-dep drifted: .spec.replicas (changed by kubectl-edit), .metadata.labels.app",
+		},
+		"missing": {
+			event: event.DriftEvent{
+				Operation:  event.DriftMissing,
+				Identifier: createIdentifier("", "ConfigMap", "default", "cm"),
+			},
+			expected: "configmap/cm missing",
+		},
+		"failed": {
+			event: event.DriftEvent{
+				Identifier: createIdentifier("", "ConfigMap", "default", "cm"),
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expected: "configmap/cm drift detection failed: forbidden",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatDriftEvent(tc.event)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
+func TestFormatter_FormatInventoryEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.InventoryEvent
+		expected string
+	}{
+		"root inventory": {
+			event: event.InventoryEvent{
+				Namespace: "platform",
+				Name:      "inventory-platform",
+				ID:        "platform",
+			},
+			expected: "inventory platform/inventory-platform\n",
+		},
+		"child inventory": {
+			event: event.InventoryEvent{
+				Namespace: "monitoring",
+				Name:      "inventory-monitoring",
+				ID:        "monitoring",
+				Parent:    "platform",
+				Depth:     1,
+			},
+			expected: "  inventory monitoring/inventory-monitoring (child of platform)\n",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatInventoryEvent(tc.event)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
 func createObject(group, kind, namespace, name string) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -32,12 +32,26 @@ func (jf *formatter) FormatApplyEvent(ae event.ApplyEvent) error {
 	eventInfo := jf.baseResourceEvent(ae.Identifier)
 	if ae.Error != nil {
 		eventInfo["error"] = ae.Error.Error()
+		if len(ae.Conflicts) > 0 {
+			eventInfo["conflicts"] = conflictsInfo(ae.Conflicts)
+		}
 		return jf.printEvent("apply", "resourceFailed", eventInfo)
 	}
 	eventInfo["operation"] = ae.Operation.String()
 	return jf.printEvent("apply", "resourceApplied", eventInfo)
 }
 
+func conflictsInfo(conflicts []event.FieldConflict) []map[string]interface{} {
+	var info []map[string]interface{}
+	for _, c := range conflicts {
+		info = append(info, map[string]interface{}{
+			"field":   c.Field,
+			"manager": c.Manager,
+		})
+	}
+	return info
+}
+
 func (jf *formatter) FormatStatusEvent(se event.StatusEvent) error {
 	return jf.printResourceStatus(se)
 }
@@ -69,6 +83,57 @@ func (jf *formatter) FormatDeleteEvent(de event.DeleteEvent) error {
 	return jf.printEvent("delete", "resourceDeleted", eventInfo)
 }
 
+func (jf *formatter) FormatRollbackEvent(re event.RollbackEvent) error {
+	eventInfo := jf.baseResourceEvent(re.Identifier)
+	if re.Error != nil {
+		eventInfo["error"] = re.Error.Error()
+		return jf.printEvent("rollback", "resourceFailed", eventInfo)
+	}
+	eventInfo["operation"] = re.Operation.String()
+	return jf.printEvent("rollback", "resourceRolledBack", eventInfo)
+}
+
+func (jf *formatter) FormatHookEvent(he event.HookEvent) error {
+	eventInfo := jf.baseResourceEvent(he.Identifier)
+	eventInfo["phase"] = he.Phase
+	if he.Error != nil {
+		eventInfo["error"] = he.Error.Error()
+		return jf.printEvent("hook", "resourceFailed", eventInfo)
+	}
+	eventInfo["operation"] = he.Operation.String()
+	return jf.printEvent("hook", "resourceHook", eventInfo)
+}
+
+func (jf *formatter) FormatDriftEvent(de event.DriftEvent) error {
+	eventInfo := jf.baseResourceEvent(de.Identifier)
+	if de.Error != nil {
+		eventInfo["error"] = de.Error.Error()
+		return jf.printEvent("drift", "resourceFailed", eventInfo)
+	}
+	eventInfo["operation"] = de.Operation.String()
+	if len(de.Fields) > 0 {
+		var fields []map[string]interface{}
+		for _, f := range de.Fields {
+			fields = append(fields, map[string]interface{}{
+				"field":   f.Field,
+				"manager": f.Manager,
+			})
+		}
+		eventInfo["fields"] = fields
+	}
+	return jf.printEvent("drift", "resourceDrift", eventInfo)
+}
+
+func (jf *formatter) FormatInventoryEvent(ie event.InventoryEvent) error {
+	return jf.printEvent("inventory", "inventoryStarted", map[string]interface{}{
+		"namespace":   ie.Namespace,
+		"name":        ie.Name,
+		"inventoryID": ie.ID,
+		"parent":      ie.Parent,
+		"depth":       ie.Depth,
+	})
+}
+
 func (jf *formatter) FormatErrorEvent(ee event.ErrorEvent) error {
 	return jf.printEvent("error", "error", map[string]interface{}{
 		"error": ee.Err.Error(),
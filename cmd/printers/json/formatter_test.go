@@ -5,6 +5,7 @@ package json
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -82,6 +83,39 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 				},
 			},
 		},
+		"resource failed with field conflicts": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Error:      fmt.Errorf("Apply failed with 2 conflicts"),
+				Conflicts: []event.FieldConflict{
+					{Field: ".spec.replicas", Manager: "hpa-controller"},
+					{Field: ".spec.template.spec.containers[name=\"app\"].image", Manager: "kubectl"},
+				},
+			},
+			expected: []map[string]interface{}{
+				{
+					"eventType": "resourceFailed",
+					"group":     "apps",
+					"kind":      "Deployment",
+					"name":      "my-dep",
+					"namespace": "default",
+					"error":     "Apply failed with 2 conflicts",
+					"conflicts": []interface{}{
+						map[string]interface{}{
+							"field":   ".spec.replicas",
+							"manager": "hpa-controller",
+						},
+						map[string]interface{}{
+							"field":   ".spec.template.spec.containers[name=\"app\"].image",
+							"manager": "kubectl",
+						},
+					},
+					"timestamp": "",
+					"type":      "apply",
+				},
+			},
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -103,6 +137,82 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 	}
 }
 
+func TestFormatter_FormatDriftEvent(t *testing.T) {
+	testCases := map[string]struct {
+		event    event.DriftEvent
+		expected map[string]interface{}
+	}{
+		"drifted": {
+			event: event.DriftEvent{
+				Operation:  event.Drifted,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Fields: []event.FieldDrift{
+					{Field: ".spec.replicas", Manager: "kubectl-edit"},
+				},
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceDrift",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"operation": "Drifted",
+				"fields": []interface{}{
+					map[string]interface{}{
+						"field":   ".spec.replicas",
+						"manager": "kubectl-edit",
+					},
+				},
+				"timestamp": "",
+				"type":      "drift",
+			},
+		},
+		"in sync": {
+			event: event.DriftEvent{
+				Operation:  event.InSync,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceDrift",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"operation": "InSync",
+				"timestamp": "",
+				"type":      "drift",
+			},
+		},
+		"failed": {
+			event: event.DriftEvent{
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Error:      fmt.Errorf("forbidden"),
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceFailed",
+				"group":     "apps",
+				"kind":      "Deployment",
+				"name":      "my-dep",
+				"namespace": "default",
+				"error":     "forbidden",
+				"timestamp": "",
+				"type":      "drift",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ioStreams, _, out, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+			formatter := NewFormatter(ioStreams, common.DryRunNone)
+			err := formatter.FormatDriftEvent(tc.event)
+			assert.NoError(t, err)
+
+			assertOutput(t, tc.expected, strings.TrimSpace(out.String()))
+		})
+	}
+}
+
 func TestFormatter_FormatStatusEvent(t *testing.T) {
 	testCases := map[string]struct {
 		previewStrategy common.DryRunStrategy
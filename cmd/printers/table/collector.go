@@ -16,11 +16,20 @@ import (
 )
 
 func newResourceStateCollector(resourceGroups []event.ActionGroup) *ResourceStateCollector {
-	resourceInfos := make(map[object.ObjMetadata]*ResourceInfo)
+	r := &ResourceStateCollector{
+		resourceInfos: make(map[object.ObjMetadata]*ResourceInfo),
+	}
+	r.addActionGroups(resourceGroups)
+	return r
+}
+
+// addActionGroups adds the resources in the passed action groups to
+// the collector.
+func (r *ResourceStateCollector) addActionGroups(resourceGroups []event.ActionGroup) {
 	for _, group := range resourceGroups {
 		action := group.Action
 		for _, identifier := range group.Identifiers {
-			resourceInfos[identifier] = &ResourceInfo{
+			r.resourceInfos[identifier] = &ResourceInfo{
 				identifier: identifier,
 				resourceStatus: &pe.ResourceStatus{
 					Identifier: identifier,
@@ -30,9 +39,6 @@ func newResourceStateCollector(resourceGroups []event.ActionGroup) *ResourceStat
 			}
 		}
 	}
-	return &ResourceStateCollector{
-		resourceInfos: resourceInfos,
-	}
 }
 
 // ResourceStateCollector consumes the events from the applier
@@ -79,6 +85,25 @@ type ResourceInfo struct {
 	// DeleteOpResult contains the result after
 	// a delete operation on a resource
 	DeleteOpResult event.DeleteEventOperation
+
+	// RollbackOpResult contains the result after
+	// a rollback operation on a resource
+	RollbackOpResult event.RollbackEventOperation
+
+	// HookOpResult contains the result after
+	// a hook has been run
+	HookOpResult event.HookEventOperation
+
+	// Conflicts contains the fields that could not be
+	// applied because of field manager conflicts
+	Conflicts []event.FieldConflict
+
+	// DriftOpResult contains the result after
+	// checking a resource for drift
+	DriftOpResult event.DriftEventOperation
+
+	// DriftFields contains the fields that have drifted
+	DriftFields []event.FieldDrift
 }
 
 // Identifier returns the identifier for the given resource.
@@ -167,12 +192,22 @@ func (r *ResourceStateCollector) processEvent(ev event.Event) error {
 	r.mux.Lock()
 	defer r.mux.Unlock()
 	switch ev.Type {
+	case event.InitType:
+		// Every inventory in a tree of nested inventories sends its
+		// own init event.
+		r.addActionGroups(ev.InitEvent.ActionGroups)
 	case event.StatusType:
 		r.processStatusEvent(ev.StatusEvent)
 	case event.ApplyType:
 		r.processApplyEvent(ev.ApplyEvent)
 	case event.PruneType:
 		r.processPruneEvent(ev.PruneEvent)
+	case event.RollbackType:
+		r.processRollbackEvent(ev.RollbackEvent)
+	case event.HookType:
+		r.processHookEvent(ev.HookEvent)
+	case event.DriftType:
+		r.processDriftEvent(ev.DriftEvent)
 	case event.ErrorType:
 		return ev.ErrorEvent.Err
 	}
@@ -201,6 +236,7 @@ func (r *ResourceStateCollector) processApplyEvent(e event.ApplyEvent) {
 		return
 	}
 	previous.ApplyOpResult = e.Operation
+	previous.Conflicts = e.Conflicts
 }
 
 // processPruneEvent handles event related to prune operations.
@@ -215,6 +251,47 @@ func (r *ResourceStateCollector) processPruneEvent(e event.PruneEvent) {
 	previous.PruneOpResult = e.Operation
 }
 
+// processRollbackEvent handles event related to rollback operations.
+func (r *ResourceStateCollector) processRollbackEvent(e event.RollbackEvent) {
+	identifier := e.Identifier
+	klog.V(7).Infof("processing rollback event for %s", identifier)
+	previous, found := r.resourceInfos[identifier]
+	if !found {
+		klog.V(4).Infof("%s rollback event not found in ResourceInfos; no processing", identifier)
+		return
+	}
+	previous.RollbackOpResult = e.Operation
+}
+
+// processHookEvent handles event related to hooks.
+func (r *ResourceStateCollector) processHookEvent(e event.HookEvent) {
+	identifier := e.Identifier
+	klog.V(7).Infof("processing hook event for %s", identifier)
+	previous, found := r.resourceInfos[identifier]
+	if !found {
+		klog.V(4).Infof("%s hook event not found in ResourceInfos; no processing", identifier)
+		return
+	}
+	// Keep the result of the hook itself after it has been deleted.
+	if e.Operation == event.HookDeleted {
+		return
+	}
+	previous.HookOpResult = e.Operation
+}
+
+// processDriftEvent handles event related to drift detection.
+func (r *ResourceStateCollector) processDriftEvent(e event.DriftEvent) {
+	identifier := e.Identifier
+	klog.V(7).Infof("processing drift event for %s", identifier)
+	previous, found := r.resourceInfos[identifier]
+	if !found {
+		klog.V(4).Infof("%s drift event not found in ResourceInfos; no processing", identifier)
+		return
+	}
+	previous.DriftOpResult = e.Operation
+	previous.DriftFields = e.Fields
+}
+
 // ResourceState contains the latest state for all the resources.
 type ResourceState struct {
 	resourceInfos ResourceInfos
@@ -245,12 +322,17 @@ func (r *ResourceStateCollector) LatestState() *ResourceState {
 	var resourceInfos ResourceInfos
 	for _, ri := range r.resourceInfos {
 		resourceInfos = append(resourceInfos, &ResourceInfo{
-			identifier:     ri.identifier,
-			resourceStatus: ri.resourceStatus,
-			ResourceAction: ri.ResourceAction,
-			ApplyOpResult:  ri.ApplyOpResult,
-			PruneOpResult:  ri.PruneOpResult,
-			DeleteOpResult: ri.DeleteOpResult,
+			identifier:       ri.identifier,
+			resourceStatus:   ri.resourceStatus,
+			ResourceAction:   ri.ResourceAction,
+			ApplyOpResult:    ri.ApplyOpResult,
+			PruneOpResult:    ri.PruneOpResult,
+			DeleteOpResult:   ri.DeleteOpResult,
+			RollbackOpResult: ri.RollbackOpResult,
+			HookOpResult:     ri.HookOpResult,
+			Conflicts:        ri.Conflicts,
+			DriftOpResult:    ri.DriftOpResult,
+			DriftFields:      ri.DriftFields,
 		})
 	}
 	sort.Sort(resourceInfos)
@@ -6,6 +6,7 @@ package table
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -96,6 +97,18 @@ var (
 				if resInfo.PruneOpResult != event.PruneUnspecified {
 					text = resInfo.PruneOpResult.String()
 				}
+			case event.HookAction:
+				if resInfo.HookOpResult != event.HookUnspecified {
+					text = resInfo.HookOpResult.String()
+				}
+			case event.DriftAction:
+				if resInfo.DriftOpResult != event.DriftUnspecified {
+					text = resInfo.DriftOpResult.String()
+				}
+			}
+			// A rollback replaces the result of the apply.
+			if resInfo.RollbackOpResult != event.RollbackUnspecified {
+				text = resInfo.RollbackOpResult.String()
 			}
 
 			if len(text) > width {
@@ -106,6 +119,52 @@ var (
 		},
 	}
 
+	messageColumn = table.MustColumn("message")
+
+	// messageColumnDef extends the message column to list the field
+	// conflicts for resources that could not be applied because of
+	// them, and the drifted fields for resources that have drifted.
+	// For all other resources, the message from the latest status
+	// is printed.
+	messageColumnDef = table.ColumnDef{
+		ColumnName:   messageColumn.ColumnName,
+		ColumnHeader: messageColumn.ColumnHeader,
+		ColumnWidth:  messageColumn.ColumnWidth,
+		PrintResourceFunc: func(w io.Writer, width int, r table.Resource) (int,
+			error) {
+			resInfo, ok := r.(*ResourceInfo)
+			if !ok {
+				return messageColumn.PrintResource(w, width, r)
+			}
+			var text string
+			switch {
+			case len(resInfo.Conflicts) > 0:
+				var conflicts []string
+				for _, c := range resInfo.Conflicts {
+					conflicts = append(conflicts, fmt.Sprintf("%s (%s)", c.Field, c.Manager))
+				}
+				text = "conflicts: " + strings.Join(conflicts, ", ")
+			case len(resInfo.DriftFields) > 0:
+				var fields []string
+				for _, f := range resInfo.DriftFields {
+					if f.Manager == "" {
+						fields = append(fields, f.Field)
+						continue
+					}
+					fields = append(fields, fmt.Sprintf("%s (%s)", f.Field, f.Manager))
+				}
+				text = "drifted: " + strings.Join(fields, ", ")
+			default:
+				return messageColumn.PrintResource(w, width, r)
+			}
+			if len(text) > width {
+				text = text[:width]
+			}
+			_, err := fmt.Fprint(w, text)
+			return len(text), err
+		},
+	}
+
 	columns = []table.ColumnDefinition{
 		table.MustColumn("namespace"),
 		table.MustColumn("resource"),
@@ -113,7 +172,7 @@ var (
 		table.MustColumn("status"),
 		table.MustColumn("conditions"),
 		table.MustColumn("age"),
-		table.MustColumn("message"),
+		messageColumnDef,
 	}
 )
 
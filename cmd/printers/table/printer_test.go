@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/print/table"
 )
 
@@ -48,6 +49,14 @@ func TestActionColumnDef(t *testing.T) {
 			columnWidth:    15,
 			expectedOutput: "Pruned",
 		},
+		"drifted": {
+			resource: &ResourceInfo{
+				ResourceAction: event.DriftAction,
+				DriftOpResult:  event.Drifted,
+			},
+			columnWidth:    15,
+			expectedOutput: "Drifted",
+		},
 		"trimmed output": {
 			resource: &ResourceInfo{
 				ResourceAction: event.ApplyAction,
@@ -72,3 +81,69 @@ func TestActionColumnDef(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageColumnDef(t *testing.T) {
+	testCases := map[string]struct {
+		resource       table.Resource
+		columnWidth    int
+		expectedOutput string
+	}{
+		"status message": {
+			resource: &ResourceInfo{
+				resourceStatus: &pe.ResourceStatus{
+					Message: "Resource is current",
+				},
+			},
+			columnWidth:    40,
+			expectedOutput: "Resource is current",
+		},
+		"field conflicts": {
+			resource: &ResourceInfo{
+				resourceStatus: &pe.ResourceStatus{
+					Message: "Resource is current",
+				},
+				Conflicts: []event.FieldConflict{
+					{Field: ".spec.replicas", Manager: "hpa-controller"},
+					{Field: ".data.key", Manager: "kubectl"},
+				},
+			},
+			columnWidth:    80,
+			expectedOutput: "conflicts: .spec.replicas (hpa-controller), .data.key (kubectl)",
+		},
+		"trimmed field conflicts": {
+			resource: &ResourceInfo{
+				Conflicts: []event.FieldConflict{
+					{Field: ".spec.replicas", Manager: "hpa-controller"},
+				},
+			},
+			columnWidth:    20,
+			expectedOutput: "conflicts: .spec.rep",
+		},
+		"drifted fields": {
+			resource: &ResourceInfo{
+				ResourceAction: event.DriftAction,
+				DriftOpResult:  event.Drifted,
+				DriftFields: []event.FieldDrift{
+					{Field: ".spec.replicas", Manager: "kubectl-edit"},
+					{Field: ".metadata.labels.app"},
+				},
+			},
+			columnWidth:    80,
+			expectedOutput: "drifted: .spec.replicas (kubectl-edit), .metadata.labels.app",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := messageColumnDef.PrintResource(&buf, tc.columnWidth, tc.resource)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if want, got := tc.expectedOutput, buf.String(); want != got {
+				t.Errorf("expected %q, but got %q", want, got)
+			}
+		})
+	}
+}
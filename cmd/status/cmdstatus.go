@@ -13,6 +13,7 @@ import (
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/cmd/status/printers"
+	statusprinter "sigs.k8s.io/cli-utils/cmd/status/printers/printer"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -22,15 +23,17 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
-	"sigs.k8s.io/cli-utils/pkg/util/factory"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 func GetStatusRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory, loader manifestreader.ManifestLoader) *StatusRunner {
 	r := &StatusRunner{
-		factory:           factory,
-		invFactory:        invFactory,
-		loader:            loader,
-		pollerFactoryFunc: pollerFactoryFunc,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	r.pollerFactoryFunc = func(f cmdutil.Factory) (poller.Poller, error) {
+		return flagutils.NewStatusPoller(f, r.statusEngine)
 	}
 	c := &cobra.Command{
 		Use:  "status (DIRECTORY | STDIN)",
@@ -43,6 +46,9 @@ func GetStatusRunner(factory cmdutil.Factory, invFactory inventory.InventoryClie
 	c.Flags().StringVar(&r.output, "output", "events", "Output format.")
 	c.Flags().DurationVar(&r.timeout, "timeout", 0,
 		"How long to wait before exiting")
+	c.Flags().BoolVar(&r.inventoryChildren, flagutils.InventoryChildrenFlag, false,
+		"If true, also print the status of the child inventories declared by the inventory.")
+	flagutils.AddStatusEngineFlag(c, &r.statusEngine)
 
 	r.Command = c
 	return r
@@ -65,6 +71,9 @@ type StatusRunner struct {
 	timeout   time.Duration
 	output    string
 
+	inventoryChildren bool
+	statusEngine      string
+
 	pollerFactoryFunc func(cmdutil.Factory) (poller.Poller, error)
 }
 
@@ -103,6 +112,23 @@ func (r *StatusRunner) runE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// The objects of the child inventories are printed under their
+	// inventory.
+	var invTree *statusprinter.Inventory
+	if r.inventoryChildren {
+		tree, err := inventory.NewInventoryTree(invClient, inv)
+		if err != nil {
+			return err
+		}
+		if tree.HasChildren() {
+			invTree, err = printerInventory(invClient, tree)
+			if err != nil {
+				return err
+			}
+			identifiers = object.Union(nil, invTree.AllIdentifiers())
+		}
+	}
+
 	// Exit here if the inventory is empty.
 	if len(identifiers) == 0 {
 		_, _ = fmt.Fprint(cmd.OutOrStdout(), "no resources found in the inventory\n")
@@ -157,9 +183,34 @@ func (r *StatusRunner) runE(cmd *cobra.Command, args []string) error {
 		UseCache:     true,
 	})
 
+	if invPrinter, ok := printer.(statusprinter.InventoryPrinter); ok && invTree != nil {
+		return invPrinter.PrintInventory(eventChannel, invTree, cancelFunc)
+	}
 	return printer.Print(eventChannel, identifiers, cancelFunc)
 }
 
+// printerInventory returns the passed tree of inventories, with the
+// objects stored in each inventory in the cluster.
+func printerInventory(invClient inventory.InventoryClient, tree *inventory.InventoryTree) (*statusprinter.Inventory, error) {
+	identifiers, err := invClient.GetClusterObjs(tree.Info, common.DryRunNone)
+	if err != nil {
+		return nil, err
+	}
+	inv := &statusprinter.Inventory{
+		Namespace:   tree.Info.Namespace(),
+		Name:        tree.Info.Name(),
+		Identifiers: identifiers,
+	}
+	for _, child := range tree.Children {
+		childInv, err := printerInventory(invClient, child)
+		if err != nil {
+			return nil, err
+		}
+		inv.Children = append(inv.Children, childInv)
+	}
+	return inv, nil
+}
+
 // desiredStatusNotifierFunc returns an Observer function for the
 // ResourceStatusCollector that will cancel the context (using the cancelFunc)
 // when all resources have reached the desired status.
@@ -190,7 +241,3 @@ func allKnownNotifierFunc(cancelFunc context.CancelFunc) collector.ObserverFunc
 		cancelFunc()
 	}
 }
-
-func pollerFactoryFunc(f cmdutil.Factory) (poller.Poller, error) {
-	return factory.NewStatusPoller(f)
-}
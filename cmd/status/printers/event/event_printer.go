@@ -9,6 +9,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/cmd/status/printers/printer"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -18,6 +19,9 @@ import (
 // status information as a list of events as they happen.
 type eventPrinter struct {
 	ioStreams genericclioptions.IOStreams
+	// prefixes contains the prefix printed before the objects of child
+	// inventories. It is the path of the inventory in the tree.
+	prefixes map[object.ObjMetadata]string
 }
 
 // NewEventPrinter returns a new instance of the eventPrinter.
@@ -52,16 +56,45 @@ func (ep *eventPrinter) Print(ch <-chan pollevent.Event, identifiers []object.Ob
 	return err
 }
 
+// PrintInventory is like Print, but the status events of the objects
+// of child inventories are prefixed with the path of their inventory
+// in the tree.
+func (ep *eventPrinter) PrintInventory(ch <-chan pollevent.Event, inv *printer.Inventory,
+	cancelFunc collector.ObserverFunc) error {
+	ep.prefixes = make(map[object.ObjMetadata]string)
+	for _, id := range inv.Identifiers {
+		ep.prefixes[id] = ""
+	}
+	for _, child := range inv.Children {
+		ep.addPrefixes(child, nil)
+	}
+	return ep.Print(ch, inv.AllIdentifiers(), cancelFunc)
+}
+
+func (ep *eventPrinter) addPrefixes(inv *printer.Inventory, path []string) {
+	path = append(path, inv.Name)
+	prefix := fmt.Sprintf("[%s] ", strings.Join(path, " > "))
+	for _, id := range inv.Identifiers {
+		if _, found := ep.prefixes[id]; !found {
+			ep.prefixes[id] = prefix
+		}
+	}
+	for _, child := range inv.Children {
+		ep.addPrefixes(child, path)
+	}
+}
+
 func (ep *eventPrinter) printStatusEvent(se pollevent.Event) {
 	switch se.EventType {
 	case pollevent.ResourceUpdateEvent:
 		id := se.Resource.Identifier
+		fmt.Fprint(ep.ioStreams.Out, ep.prefixes[id])
 		printResourceStatus(id, se, ep.ioStreams)
 	case pollevent.ErrorEvent:
 		id := se.Resource.Identifier
 		gk := id.GroupKind
-		fmt.Fprintf(ep.ioStreams.Out, "%s error: %s\n", resourceIDToString(gk, id.Name),
-			se.Error.Error())
+		fmt.Fprintf(ep.ioStreams.Out, "%s%s error: %s\n", ep.prefixes[id],
+			resourceIDToString(gk, id.Name), se.Error.Error())
 	}
 }
 
@@ -23,3 +23,31 @@ type Printer interface {
 	// program terminates.
 	Print(ch <-chan event.Event, identifiers []object.ObjMetadata, cancelFunc collector.ObserverFunc) error
 }
+
+// Inventory is an inventory in a tree of nested inventories, with the
+// objects stored in it.
+type Inventory struct {
+	Namespace   string
+	Name        string
+	Identifiers []object.ObjMetadata
+	Children    []*Inventory
+}
+
+// AllIdentifiers returns the identifiers of the objects stored in the
+// inventory and in all its descendants.
+func (i *Inventory) AllIdentifiers() []object.ObjMetadata {
+	ids := append([]object.ObjMetadata{}, i.Identifiers...)
+	for _, child := range i.Children {
+		ids = append(ids, child.AllIdentifiers()...)
+	}
+	return ids
+}
+
+// InventoryPrinter is implemented by printers that can print the status
+// of the objects of nested inventories under the inventory they belong
+// to.
+type InventoryPrinter interface {
+	// PrintInventory is like Print, but the status of the objects of
+	// the child inventories is printed under their inventory.
+	PrintInventory(ch <-chan event.Event, inv *Inventory, cancelFunc collector.ObserverFunc) error
+}
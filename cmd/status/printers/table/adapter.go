@@ -4,8 +4,14 @@
 package table
 
 import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/cmd/status/printers/printer"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/aggregator"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/print/table"
 )
@@ -15,6 +21,9 @@ import (
 // needed by the BaseTablePrinter.
 type CollectorAdapter struct {
 	collector *collector.ResourceStatusCollector
+	// inventory is the tree of nested inventories of the objects, if
+	// the objects of child inventories are printed under them.
+	inventory *printer.Inventory
 }
 
 type ResourceInfo struct {
@@ -52,8 +61,34 @@ func (rss *ResourceState) Error() error {
 	return rss.err
 }
 
+// InventoryInfo is a child inventory in a tree of nested inventories.
+// Its status is the aggregated status of its objects, and its
+// subresources are its objects and its child inventories.
+type InventoryInfo struct {
+	resourceStatus *pe.ResourceStatus
+	subResources   []table.Resource
+}
+
+func (i *InventoryInfo) Identifier() object.ObjMetadata {
+	return i.resourceStatus.Identifier
+}
+
+func (i *InventoryInfo) ResourceStatus() *pe.ResourceStatus {
+	return i.resourceStatus
+}
+
+func (i *InventoryInfo) SubResources() []table.Resource {
+	return i.subResources
+}
+
 func (ca *CollectorAdapter) LatestStatus() *ResourceState {
 	observation := ca.collector.LatestObservation()
+	if ca.inventory != nil {
+		return &ResourceState{
+			resources: inventoryResources(ca.inventory, observation.ResourceStatuses),
+			err:       observation.Error,
+		}
+	}
 	var resources []table.Resource
 	for _, resourceStatus := range observation.ResourceStatuses {
 		resources = append(resources, &ResourceInfo{
@@ -65,3 +100,38 @@ func (ca *CollectorAdapter) LatestStatus() *ResourceState {
 		err:       observation.Error,
 	}
 }
+
+// inventoryResources returns the objects of the passed inventory,
+// followed by its child inventories.
+func inventoryResources(inv *printer.Inventory, resourceStatuses pe.ResourceStatuses) []table.Resource {
+	var resources []table.Resource
+	for _, resourceStatus := range resourceStatuses {
+		if object.ObjMetas(inv.Identifiers).Contains(resourceStatus.Identifier) {
+			resources = append(resources, &ResourceInfo{
+				resourceStatus: resourceStatus,
+			})
+		}
+	}
+	for _, child := range inv.Children {
+		var childStatuses []*pe.ResourceStatus
+		for _, resourceStatus := range resourceStatuses {
+			if object.ObjMetas(child.AllIdentifiers()).Contains(resourceStatus.Identifier) {
+				childStatuses = append(childStatuses, resourceStatus)
+			}
+		}
+		resources = append(resources, &InventoryInfo{
+			resourceStatus: &pe.ResourceStatus{
+				// The inventory is printed as a resource of kind Inventory.
+				Identifier: object.ObjMetadata{
+					Namespace: child.Namespace,
+					Name:      child.Name,
+					GroupKind: schema.GroupKind{Kind: "Inventory"},
+				},
+				Status:  aggregator.AggregateStatus(childStatuses, status.CurrentStatus),
+				Message: fmt.Sprintf("%d resource(s)", len(childStatuses)),
+			},
+			subResources: inventoryResources(child, resourceStatuses),
+		})
+	}
+	return resources
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/cmd/status/printers/printer"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -35,6 +36,18 @@ func NewTablePrinter(ioStreams genericclioptions.IOStreams) *tablePrinter {
 // until the channel is closed .
 func (t *tablePrinter) Print(ch <-chan event.Event, identifiers []object.ObjMetadata,
 	cancelFunc collector.ObserverFunc) error {
+	return t.print(ch, identifiers, nil, cancelFunc)
+}
+
+// PrintInventory is like Print, but the objects of the child inventories
+// are printed under a row for their inventory.
+func (t *tablePrinter) PrintInventory(ch <-chan event.Event, inv *printer.Inventory,
+	cancelFunc collector.ObserverFunc) error {
+	return t.print(ch, inv.AllIdentifiers(), inv, cancelFunc)
+}
+
+func (t *tablePrinter) print(ch <-chan event.Event, identifiers []object.ObjMetadata,
+	inv *printer.Inventory, cancelFunc collector.ObserverFunc) error {
 	coll := collector.NewResourceStatusCollector(identifiers)
 	stop := make(chan struct{})
 
@@ -42,6 +55,7 @@ func (t *tablePrinter) Print(ch <-chan event.Event, identifiers []object.ObjMeta
 	// printing the latest state on a regular cadence.
 	printCompleted := t.runPrintLoop(&CollectorAdapter{
 		collector: coll,
+		inventory: inv,
 	}, stop)
 
 	// Make the collector start listening on the eventChannel.
@@ -0,0 +1,149 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wait
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/cmd/printers"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+const (
+	forCurrent = "current"
+	forDeleted = "deleted"
+)
+
+// GetWaitRunner creates and returns the WaitRunner which stores the cobra command.
+func GetWaitRunner(factory cmdutil.Factory, invFactory inventory.InventoryClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *WaitRunner {
+	r := &WaitRunner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "wait (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Wait for the resources in a configuration to reconcile"),
+		Long: i18n.T(`Wait for the resources in a configuration to become current or to be
+deleted, without applying anything. This is useful when the resources have
+been applied by some other tool. With --inventory, all the resources in the
+inventory are waited for instead of the resources in the package.
+Exits with a non-zero exit code if the resources don't reach the condition
+before the timeout.`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: r.RunE,
+	}
+
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.waitFor, "for", forCurrent,
+		fmt.Sprintf("The condition to wait for, must be one of %q or %q.", forCurrent, forDeleted))
+	cmd.Flags().BoolVar(&r.fromInventory, "inventory", false,
+		"If true, wait for all the resources in the inventory instead of the resources in the package.")
+	cmd.Flags().DurationVar(&r.timeout, "timeout", apply.DefaultWaitTimeout,
+		"Timeout threshold for waiting for all resources to reach the condition.")
+	cmd.Flags().DurationVar(&r.period, "poll-period", 2*time.Second,
+		"Polling period for resource statuses.")
+
+	flagutils.AddStatusEngineFlag(cmd, &r.statusEngine)
+
+	r.Command = cmd
+	return r
+}
+
+// WaitCommand creates the WaitRunner, returning the cobra command associated with it.
+func WaitCommand(f cmdutil.Factory, invFactory inventory.InventoryClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetWaitRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// WaitRunner encapsulates data necessary to run the wait command.
+type WaitRunner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.InventoryClientFactory
+	loader     manifestreader.ManifestLoader
+
+	output        string
+	waitFor       string
+	fromInventory bool
+	timeout       time.Duration
+	period        time.Duration
+	statusEngine  string
+}
+
+func (r *WaitRunner) RunE(cmd *cobra.Command, args []string) error {
+	condition, err := convertCondition(r.waitFor)
+	if err != nil {
+		return err
+	}
+
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	inv, objs, err := r.loader.InventoryInfo(objs)
+	if err != nil {
+		return err
+	}
+	if r.fromInventory {
+		objs = nil
+	}
+
+	statusPoller, err := flagutils.NewStatusPoller(r.factory, r.statusEngine)
+	if err != nil {
+		return err
+	}
+	invClient, err := r.invFactory.NewInventoryClient(r.factory)
+	if err != nil {
+		return err
+	}
+	w, err := apply.NewWaiter(r.factory, invClient, statusPoller)
+	if err != nil {
+		return err
+	}
+	ch := w.Run(context.Background(), inv, objs, apply.WaitOptions{
+		Condition:        condition,
+		Timeout:          r.timeout,
+		PollInterval:     r.period,
+		EmitStatusEvents: true,
+	})
+
+	// The printer will print updates from the channel. It will block
+	// until the channel is closed. A TimeoutError from the waiter is
+	// returned from the printer.
+	printer := printers.GetPrinter(r.output, r.ioStreams)
+	return printer.Print(ch, common.DryRunNone, true)
+}
+
+func convertCondition(waitFor string) (taskrunner.Condition, error) {
+	switch waitFor {
+	case forCurrent:
+		return taskrunner.AllCurrent, nil
+	case forDeleted:
+		return taskrunner.AllNotFound, nil
+	default:
+		return "", fmt.Errorf("wait condition must be one of %q or %q, got %q", forCurrent, forDeleted, waitFor)
+	}
+}
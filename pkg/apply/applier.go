@@ -8,22 +8,31 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/apply/solver"
+	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/statusreaders"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/ordering"
 )
@@ -109,14 +118,40 @@ func (a *Applier) prepareObjects(localInv inventory.InventoryInfo, localObjs []*
 	return localObjs, pruneObjs, nil
 }
 
+// statusReadersFactoryFunc returns the function that creates the
+// StatusReaders for the status rules declared in the annotations of
+// the passed CRDs. The rules are only used by this run, so they don't
+// affect the status computed for other appliers. Nil is returned if
+// none of the CRDs declares a rule.
+func statusReadersFactoryFunc(objs []*unstructured.Unstructured) (func(engine.ClusterReader, meta.RESTMapper) map[schema.GroupKind]engine.StatusReader, error) {
+	var rules []status.Rule
+	for _, obj := range objs {
+		if !object.IsCRD(obj) {
+			continue
+		}
+		rule, found, err := status.RuleFromCRD(obj)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return statusreaders.RuleStatusReadersFactoryFunc(rules...)
+}
+
 // Run performs the Apply step. This happens asynchronously with updates
 // on progress and any errors are reported back on the event channel.
 // Cancelling the operation or setting timeout on how long to Wait
 // for it complete can be done with the passed in context.
-// Note: There isn't currently any way to interrupt the operation
-// before all the given resources have been applied to the cluster. Any
-// cancellation or timeout will only affect how long we Wait for the
-// resources to become current.
+// If the context is cancelled, no more resources will be applied or
+// pruned after the ones currently in flight. The inventory is still
+// updated to reflect the resources that were actually applied, and
+// the last event on the channel will be an error event with the
+// error from the context.
 func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, objects []*unstructured.Unstructured, options Options) <-chan event.Event {
 	klog.V(4).Infof("apply run for %d objects", len(objects))
 	eventChannel := make(chan event.Event)
@@ -139,12 +174,57 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 			return
 		}
 
+		if options.Applier != "" {
+			if setter, ok := a.invClient.(inventory.ApplierSetter); ok {
+				setter.SetApplier(options.Applier)
+			}
+		}
+
+		// The lock is held in the namespace of the inventory, so that
+		// namespace is applied first if it is part of the package.
+		if options.InventoryLocker != nil {
+			if invNamespace := task.InventoryNamespaceInSet(invInfo, objects); invNamespace != nil {
+				if err := a.invClient.ApplyInventoryNamespace(invNamespace, options.DryRunStrategy); err != nil {
+					handleError(eventChannel, err)
+					return
+				}
+			}
+		}
+		// Hold the inventory lock until the run is done, so no other
+		// client changes the inventory in the meantime. The run is
+		// cancelled if the lock is lost.
+		lockCtx, unlock, err := lockInventory(ctx, options.InventoryLocker, invInfo, options.DryRunStrategy)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		defer func() { _ = unlock() }()
+
+		// Hooks are not applied with the other objects. They are run
+		// in their own phases instead.
+		hooks, objects, err := hook.Split(objects)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
 		applyObjs, pruneObjs, err := a.prepareObjects(invInfo, objects, options)
 		if err != nil {
 			handleError(eventChannel, err)
 			return
 		}
 		klog.V(4).Infof("calculated %d apply objs; %d prune objs", len(applyObjs), len(pruneObjs))
+		// The status rules declared on the applied CRDs are used to
+		// wait for their custom resources.
+		statusReadersFunc, err := statusReadersFactoryFunc(applyObjs)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		invUIDs, err := inventoryUIDs(a.invClient, invInfo)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
 
 		// Fetch the queue (channel) of tasks that should be executed.
 		klog.V(4).Infoln("applier building task queue...")
@@ -164,6 +244,11 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 			PrunePropagationPolicy: options.PrunePropagationPolicy,
 			PruneTimeout:           options.PruneTimeout,
 			InventoryPolicy:        options.InventoryPolicy,
+			ApplyConcurrency:       options.ApplyConcurrency,
+			RollbackOnFailure:      rollbackEnabled(options),
+			HookTimeout:            options.HookTimeout,
+			PollInterval:           options.PollInterval,
+			InventoryUIDs:          invUIDs,
 		}
 		// Build list of prune validation filters.
 		pruneFilters := []filter.ValidationFilter{
@@ -176,16 +261,45 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 				LocalNamespaces: localNamespaces(invInfo, object.UnstructuredsToObjMetasOrDie(objects)),
 			},
 		}
+		// Pre-prune hooks only run if there is something to prune.
+		var prePruneHooks []*unstructured.Unstructured
+		if opts.Prune && len(pruneObjs) > 0 {
+			prePruneHooks = hooks[hook.PrePrune]
+		}
 		// Build the task queue by appending tasks in the proper order.
 		taskQueue, err := taskBuilder.
 			AppendInvAddTask(invInfo, applyObjs, options.DryRunStrategy).
+			AppendHookTask(hook.PreApply, hooks[hook.PreApply], opts).
 			AppendApplyWaitTasks(invInfo, applyObjs, opts).
+			AppendHookTask(hook.PostApply, hooks[hook.PostApply], opts).
+			AppendHookTask(hook.PrePrune, prePruneHooks, opts).
 			AppendPruneWaitTasks(pruneObjs, pruneFilters, opts).
 			AppendInvSetTask(invInfo, options.DryRunStrategy).
 			Build()
 		if err != nil {
 			handleError(eventChannel, err)
 		}
+		// If rollback is enabled, build the queue of tasks that should
+		// run if any of the tasks above fails. It reverts the applied
+		// objects, and then restores the previous inventory, dropping
+		// only the objects created by this run that have been deleted.
+		var rollbackQueue chan taskrunner.Task
+		if opts.RollbackOnFailure {
+			rollbackBuilder := &solver.TaskQueueBuilder{
+				PruneOptions: a.pruneOptions,
+				Mapper:       mapper,
+				InvClient:    a.invClient,
+			}
+			rollbackTasks, err := rollbackBuilder.
+				AppendRollbackTask(opts).
+				AppendRollbackInvSetTask(invInfo, options.DryRunStrategy).
+				Build()
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			rollbackQueue = rollbackTasks.ToChannel()
+		}
 		// Send event to inform the caller about the resources that
 		// will be applied/pruned.
 		eventChannel <- event.Event{
@@ -199,11 +313,18 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.InventoryInfo, obje
 		allIds := object.UnstructuredsToObjMetasOrDie(append(applyObjs, pruneObjs...))
 		runner := taskrunner.NewTaskStatusRunner(allIds, a.statusPoller)
 		klog.V(4).Infoln("applier running TaskStatusRunner...")
-		err = runner.Run(ctx, taskQueue.ToChannel(), eventChannel, taskrunner.Options{
+		err = runner.Run(lockCtx, taskQueue.ToChannel(), eventChannel, taskrunner.Options{
 			PollInterval:     options.PollInterval,
 			UseCache:         true,
 			EmitStatusEvents: options.EmitStatusEvents,
+			RollbackQueue:    rollbackQueue,
+
+			CustomStatusReadersFactoryFunc: statusReadersFunc,
 		})
+		// A lost lock is reported instead of the cancellation it caused.
+		if unlockErr := unlock(); unlockErr != nil {
+			err = unlockErr
+		}
 		if err != nil {
 			handleError(eventChannel, err)
 		}
@@ -248,8 +369,38 @@ type Options struct {
 
 	// InventoryPolicy defines the inventory policy of apply.
 	InventoryPolicy inventory.InventoryPolicy
+
+	// ApplyConcurrency defines how many objects within a set of
+	// independent objects can be applied in parallel. If this is
+	// not provided, the objects are applied one at a time.
+	ApplyConcurrency int
+
+	// RollbackOnFailure defines whether the changes made by the applier
+	// should be reverted if any object fails to apply or does not
+	// reconcile before the ReconcileTimeout. Objects are restored to
+	// the state they had before they were applied, and objects that
+	// were created are deleted. Rollback is not done for dry-runs.
+	RollbackOnFailure bool
+
+	// HookTimeout defines how long to wait for each hook to
+	// complete. If this is not provided, DefaultHookTimeout is used.
+	HookTimeout time.Duration
+
+	// InventoryLocker defines how the inventory is locked for the
+	// duration of the apply. If this is not provided, the inventory
+	// is not locked. The inventory is not locked for dry-runs.
+	InventoryLocker inventory.InventoryLocker
+
+	// Applier identifies the client in the revisions of the inventory,
+	// if the InventoryClient records them. If this is not provided,
+	// the InventoryClient's default is used, which is usually the user
+	// and host running the apply.
+	Applier string
 }
 
+// DefaultHookTimeout is the default time to wait for a hook to complete.
+const DefaultHookTimeout = 5 * time.Minute
+
 // setDefaults set the options to the default values if they
 // have not been provided.
 func setDefaults(o *Options) {
@@ -259,6 +410,48 @@ func setDefaults(o *Options) {
 	if o.PrunePropagationPolicy == "" {
 		o.PrunePropagationPolicy = metav1.DeletePropagationBackground
 	}
+	if o.ApplyConcurrency < 1 {
+		o.ApplyConcurrency = 1
+	}
+	if o.HookTimeout == time.Duration(0) {
+		o.HookTimeout = DefaultHookTimeout
+	}
+}
+
+// rollbackEnabled returns true if the applied objects should be
+// rolled back on failure. Nothing is changed during a dry-run, so
+// there is nothing to roll back.
+func rollbackEnabled(o Options) bool {
+	return o.RollbackOnFailure && !o.DryRunStrategy.ClientOrServerDryRun()
+}
+
+// lockInventory acquires the lock of the passed inventory with the
+// passed locker. It returns the context for the run, which is cancelled
+// if the lock is lost, and a function that releases the lock. The
+// function returns an InventoryLockLostError if the lock was lost, and
+// only releases the lock the first time it is called. Nothing is locked
+// if there is no locker or for a dry-run.
+func lockInventory(ctx context.Context, locker inventory.InventoryLocker, inv inventory.InventoryInfo,
+	dryRun common.DryRunStrategy) (context.Context, func() error, error) {
+	if locker == nil || dryRun.ClientOrServerDryRun() {
+		return ctx, func() error { return nil }, nil
+	}
+	lockCtx, unlock, err := locker.Lock(ctx, inv)
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	var unlockErr error
+	return lockCtx, func() error {
+		once.Do(func() {
+			unlockErr = unlock()
+			if _, lost := unlockErr.(inventory.InventoryLockLostError); unlockErr != nil && !lost {
+				klog.Warningf("unable to release the inventory lock: %s", unlockErr)
+				unlockErr = nil
+			}
+		})
+		return unlockErr
+	}, nil
 }
 
 func handleError(eventChannel chan event.Event, err error) {
@@ -270,6 +463,23 @@ func handleError(eventChannel chan event.Event, err error) {
 	}
 }
 
+// inventoryUIDs returns the UIDs of the objects when they were last
+// applied, as stored in the cluster inventory. Objects without a stored
+// UID are not included.
+func inventoryUIDs(invClient inventory.InventoryClient, inv inventory.InventoryInfo) (map[object.ObjMetadata]types.UID, error) {
+	statuses, err := invClient.GetClusterObjStatuses(inv)
+	if err != nil {
+		return nil, err
+	}
+	uids := make(map[object.ObjMetadata]types.UID, len(statuses))
+	for _, s := range statuses {
+		if s.UID != "" {
+			uids[s.Identifier] = s.UID
+		}
+	}
+	return uids, nil
+}
+
 // localNamespaces stores a set of strings of all the namespaces
 // for the passed non cluster-scoped localObjs, plus the namespace
 // of the passed inventory object. This is used to skip deleting
@@ -22,12 +22,14 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/rest/fake"
 	clienttesting "k8s.io/client-go/testing"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	"k8s.io/kubectl/pkg/scheme"
+	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/common"
@@ -64,6 +66,14 @@ metadata:
 type: Opaque
 spec:
   foo: bar
+`,
+		"pod": `
+kind: Pod
+apiVersion: v1
+metadata:
+  name: pod
+  namespace: default
+  uid: pod-uid
 `,
 	}
 )
@@ -78,6 +88,8 @@ type inventoryInfo struct {
 	namespace string
 	id        string
 	list      []object.ObjMetadata
+	// uids are the UIDs stored in the inventory for the objects.
+	uids map[object.ObjMetadata]types.UID
 }
 
 func (i inventoryInfo) toWrapped() inventory.InventoryInfo {
@@ -109,6 +121,9 @@ func TestApplier(t *testing.T) {
 		inventoryPolicy  inventory.InventoryPolicy
 		statusEvents     []pollevent.Event
 		expectedEvents   []testutil.ExpEvent
+		// expectedInventory is the inventory stored after the run, if
+		// it is checked.
+		expectedInventory []object.ObjMetadata
 	}{
 		"initial apply without status or prune": {
 			namespace: "default",
@@ -459,6 +474,57 @@ func TestApplier(t *testing.T) {
 				},
 			},
 		},
+		"resources recreated outside of the inventory should not be pruned": {
+			namespace: "default",
+			resources: []*unstructured.Unstructured{},
+			invInfo: inventoryInfo{
+				name:      "abc-123",
+				namespace: "default",
+				id:        "test",
+				list: []object.ObjMetadata{
+					object.UnstructuredToObjMetaOrDie(
+						testutil.Unstructured(t, resources["deployment"]),
+					),
+				},
+				uids: map[object.ObjMetadata]types.UID{
+					object.UnstructuredToObjMetaOrDie(
+						testutil.Unstructured(t, resources["deployment"]),
+					): "applied-uid",
+				},
+			},
+			clusterObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test")),
+			},
+			reconcileTimeout: 0,
+			prune:            true,
+			inventoryPolicy:  inventory.InventoryPolicyMustMatch,
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.InitType,
+				},
+				{
+					EventType: event.ActionGroupType,
+				},
+				{
+					EventType: event.ActionGroupType,
+				},
+				{
+					EventType: event.ActionGroupType,
+				},
+				{
+					EventType: event.PruneType,
+					PruneEvent: &testutil.ExpPruneEvent{
+						Operation: event.PruneSkipped,
+					},
+				},
+				{
+					EventType: event.ActionGroupType,
+				},
+			},
+			// The recreated deployment is not the applied one, so it
+			// is dropped from the inventory.
+			expectedInventory: []object.ObjMetadata{},
+		},
 		"prune with inventory object annotation matched": {
 			namespace: "default",
 			resources: []*unstructured.Unstructured{},
@@ -534,14 +600,16 @@ func TestApplier(t *testing.T) {
 				objs = append(objs, obj)
 			}
 
+			invHandler := &inventoryObjectHandler{
+				inventoryName:      tc.invInfo.name,
+				inventoryNamespace: tc.invInfo.namespace,
+				inventoryID:        tc.invInfo.id,
+				inventoryList:      tc.invInfo.list,
+				inventoryUIDs:      tc.invInfo.uids,
+			}
 			handlers := append([]handler{
 				&nsHandler{},
-				&inventoryObjectHandler{
-					inventoryName:      tc.invInfo.name,
-					inventoryNamespace: tc.invInfo.namespace,
-					inventoryID:        tc.invInfo.id,
-					inventoryList:      tc.invInfo.list,
-				},
+				invHandler,
 			}, &genericHandler{
 				resources: objs,
 				mapper:    mapper,
@@ -602,6 +670,9 @@ func TestApplier(t *testing.T) {
 
 			err = testutil.VerifyEvents(tc.expectedEvents, events)
 			assert.NoError(t, err)
+			if tc.expectedInventory != nil {
+				assert.ElementsMatch(t, tc.expectedInventory, invHandler.inventoryList)
+			}
 		})
 	}
 }
@@ -852,6 +923,7 @@ type inventoryObjectHandler struct {
 	inventoryNamespace string
 	inventoryID        string
 	inventoryList      []object.ObjMetadata
+	inventoryUIDs      map[object.ObjMetadata]types.UID
 	inventoryObj       *v1.ConfigMap
 }
 
@@ -926,6 +998,9 @@ func (i *inventoryObjectHandler) currentInvObj() v1.ConfigMap {
 	inv := make(map[string]string)
 	for _, objMeta := range i.inventoryList {
 		inv[objMeta.String()] = ""
+		if uid, found := i.inventoryUIDs[objMeta]; found {
+			inv[objMeta.String()] = fmt.Sprintf(`{"uid":%q}`, uid)
+		}
 	}
 	return v1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
@@ -1079,6 +1154,306 @@ func fakeDynamicClient(t *testing.T, mapper meta.RESTMapper, objs ...resourceInf
 		fakeClient.PrependReactor("delete", r, func(clienttesting.Action) (bool, runtime.Object, error) {
 			return true, nil, nil
 		})
+		fakeClient.PrependReactor("update", r, func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return true, action.(clienttesting.UpdateAction).GetObject(), nil
+		})
 	}
 	return fakeClient
 }
+
+type fakeLocker struct {
+	err      error
+	lost     bool
+	onLock   func()
+	locked   int
+	unlocked int
+}
+
+func (f *fakeLocker) Lock(ctx context.Context, inv inventory.InventoryInfo) (context.Context, func() error, error) {
+	if f.onLock != nil {
+		f.onLock()
+	}
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	f.locked++
+	lockCtx, cancel := context.WithCancel(ctx)
+	if f.lost {
+		cancel()
+	}
+	return lockCtx, func() error {
+		cancel()
+		f.unlocked++
+		if f.lost {
+			return inventory.InventoryLockLostError{Namespace: inv.Namespace(), Name: inventory.LockName(inv)}
+		}
+		return nil
+	}, nil
+}
+
+func TestApplier_RollbackInventory(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("default")
+	defer tf.Cleanup()
+	mapper, err := tf.ToRESTMapper()
+	require.NoError(t, err)
+
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	// The secret is applied in the second apply group, and fails since
+	// it belongs to another inventory. The pod is only in the previous
+	// inventory, so it would have been pruned after the apply.
+	secret := testutil.Unstructured(t, resources["secret"], testutil.AddDependsOn(t, deployment))
+	prevInventory := []object.ObjMetadata{
+		testutil.ToIdentifier(t, resources["deployment"]),
+		testutil.ToIdentifier(t, resources["pod"]),
+	}
+	objs := []resourceInfo{
+		{
+			resource: testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test")),
+			exists:   true,
+		},
+		{
+			resource: testutil.Unstructured(t, resources["secret"], testutil.AddOwningInv(t, "unmatched")),
+			exists:   true,
+		},
+		{
+			resource: testutil.Unstructured(t, resources["pod"], testutil.AddOwningInv(t, "test")),
+			exists:   true,
+		},
+	}
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+		list:      prevInventory,
+	}
+	invHandler := &inventoryObjectHandler{
+		inventoryName:      invInfo.name,
+		inventoryNamespace: invInfo.namespace,
+		inventoryID:        invInfo.id,
+		inventoryList:      invInfo.list,
+	}
+	tf.UnstructuredClient = newFakeRESTClient(t, []handler{
+		&nsHandler{},
+		invHandler,
+		&genericHandler{
+			resources: objs,
+			mapper:    mapper,
+		},
+	})
+	tf.FakeDynamicClient = fakeDynamicClient(t, mapper, objs...)
+
+	poller := &fakePoller{
+		events: []pollevent.Event{
+			{
+				EventType: pollevent.ResourceUpdateEvent,
+				Resource: &pollevent.ResourceStatus{
+					Identifier: testutil.ToIdentifier(t, resources["deployment"]),
+					Status:     status.CurrentStatus,
+					Resource:   testutil.Unstructured(t, resources["deployment"]),
+				},
+			},
+		},
+		start: make(chan struct{}),
+	}
+	close(poller.start)
+	invClient, err := inventory.ClusterInventoryClientFactory{}.NewInventoryClient(tf)
+	require.NoError(t, err)
+	infoHelper := &fakeInfoHelper{
+		factory: tf,
+	}
+	invClient.(*inventory.ClusterInventoryClient).InfoHelper = infoHelper
+	applier, err := NewApplier(tf, invClient, poller)
+	require.NoError(t, err)
+	applier.infoHelper = infoHelper
+
+	var rolledBack bool
+	var runErr error
+	for e := range applier.Run(context.Background(), invInfo.toWrapped(), []*unstructured.Unstructured{deployment, secret}, Options{
+		ReconcileTimeout:  time.Minute,
+		InventoryPolicy:   inventory.InventoryPolicyMustMatch,
+		RollbackOnFailure: true,
+	}) {
+		if e.Type == event.ErrorType {
+			runErr = e.ErrorEvent.Err
+		}
+		if e.Type == event.RollbackType {
+			rolledBack = true
+		}
+	}
+	assert.Equal(t, applyerror.NewApplyFailedError([]object.ObjMetadata{
+		testutil.ToIdentifier(t, resources["secret"]),
+	}), runErr)
+	assert.True(t, rolledBack)
+	// The pod was never pruned, so the previous inventory is restored.
+	assert.ElementsMatch(t, prevInventory, invHandler.inventoryList)
+}
+
+func TestApplier_InventoryLocker(t *testing.T) {
+	lockedErr := inventory.InventoryLockedError{
+		Namespace: namespace,
+		Name:      "inventory-lock-test-app-label",
+		Holder:    "other",
+		Since:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testCases := map[string]struct {
+		locker           *fakeLocker
+		dryRunStrategy   common.DryRunStrategy
+		expectedErr      error
+		expectedLocked   int
+		expectedUnlocked int
+	}{
+		"lock is held for the run": {
+			locker:           &fakeLocker{},
+			expectedLocked:   1,
+			expectedUnlocked: 1,
+		},
+		"locked inventory is an error": {
+			locker:      &fakeLocker{err: lockedErr},
+			expectedErr: lockedErr,
+		},
+		"no lock for dry-run": {
+			locker:         &fakeLocker{},
+			dryRunStrategy: common.DryRunClient,
+		},
+		"lost lock is an error": {
+			locker: &fakeLocker{lost: true},
+			expectedErr: inventory.InventoryLockLostError{
+				Namespace: namespace,
+				Name:      "inventory-lock-test-app-label",
+			},
+			expectedLocked:   1,
+			expectedUnlocked: 1,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace(namespace)
+			defer tf.Cleanup()
+
+			poller := &fakePoller{
+				start: make(chan struct{}),
+			}
+			close(poller.start)
+			applier, err := NewApplier(tf, inventory.NewFakeInventoryClient(nil), poller)
+			require.NoError(t, err)
+
+			var events []event.Event
+			for e := range applier.Run(context.Background(), localInv, []*unstructured.Unstructured{}, Options{
+				DryRunStrategy:  tc.dryRunStrategy,
+				InventoryLocker: tc.locker,
+			}) {
+				events = append(events, e)
+			}
+			require.NotEmpty(t, events)
+			last := events[len(events)-1]
+			if tc.expectedErr != nil {
+				require.Equal(t, event.ErrorType, last.Type)
+				assert.Equal(t, tc.expectedErr, last.ErrorEvent.Err)
+			} else {
+				assert.NotEqual(t, event.ErrorType, last.Type)
+			}
+			assert.Equal(t, tc.expectedLocked, tc.locker.locked)
+			assert.Equal(t, tc.expectedUnlocked, tc.locker.unlocked)
+		})
+	}
+}
+
+func TestApplier_InventoryLockerNamespace(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(namespace)
+	defer tf.Cleanup()
+
+	poller := &fakePoller{
+		start: make(chan struct{}),
+	}
+	close(poller.start)
+	invClient := inventory.NewFakeInventoryClient(nil)
+	applier, err := NewApplier(tf, invClient, poller)
+	require.NoError(t, err)
+
+	// The lease of the lock is created in the namespace of the inventory,
+	// so the namespace must exist before the lock is acquired.
+	var namespacesAtLock []string
+	lockedErr := fmt.Errorf("locked")
+	locker := &fakeLocker{
+		err: lockedErr,
+		onLock: func() {
+			namespacesAtLock = append([]string{}, invClient.Namespaces...)
+		},
+	}
+	invNamespace := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata": map[string]interface{}{
+				"name": namespace,
+			},
+		},
+	}
+	var events []event.Event
+	for e := range applier.Run(context.Background(), localInv, []*unstructured.Unstructured{invNamespace}, Options{
+		InventoryLocker: locker,
+	}) {
+		events = append(events, e)
+	}
+	require.NotEmpty(t, events)
+	last := events[len(events)-1]
+	require.Equal(t, event.ErrorType, last.Type)
+	assert.Equal(t, lockedErr, last.ErrorEvent.Err)
+	assert.Equal(t, []string{namespace}, namespacesAtLock)
+}
+
+func TestApplier_Applier(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(namespace)
+	defer tf.Cleanup()
+
+	poller := &fakePoller{
+		start: make(chan struct{}),
+	}
+	close(poller.start)
+	invClient := inventory.NewFakeInventoryClient(nil)
+	applier, err := NewApplier(tf, invClient, poller)
+	require.NoError(t, err)
+
+	for e := range applier.Run(context.Background(), localInv, []*unstructured.Unstructured{}, Options{
+		Applier: "ci-pipeline",
+	}) {
+		require.NotEqual(t, event.ErrorType, e.Type)
+	}
+	require.NotEmpty(t, invClient.Revisions)
+	assert.Equal(t, "ci-pipeline", invClient.Revisions[0].Applier)
+}
+
+func TestStatusReadersFactoryFunc(t *testing.T) {
+	crd := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "certificates.cert-manager.io",
+				"annotations": map[string]interface{}{
+					status.StatusRulesAnnotation: "current:\n- conditions:\n  - type: Ready\n",
+				},
+			},
+			"spec": map[string]interface{}{
+				"group": "cert-manager.io",
+				"names": map[string]interface{}{
+					"kind": "Certificate",
+				},
+			},
+		},
+	}
+	gk := schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}
+
+	factoryFunc, err := statusReadersFactoryFunc([]*unstructured.Unstructured{crd})
+	require.NoError(t, err)
+	require.NotNil(t, factoryFunc)
+	assert.Contains(t, factoryFunc(nil, nil), gk)
+	// The rule is only used by the run, so it is not registered.
+	assert.Nil(t, status.DefaultRegistry.Get(gk))
+
+	crd.SetAnnotations(nil)
+	factoryFunc, err = statusReadersFactoryFunc([]*unstructured.Unstructured{crd})
+	require.NoError(t, err)
+	assert.Nil(t, factoryFunc)
+}
@@ -75,6 +75,17 @@ type DestroyerOptions struct {
 	// PollInterval defines how often we should poll for the status
 	// of resources.
 	PollInterval time.Duration
+
+	// InventoryLocker defines how the inventory is locked for the
+	// duration of the destroy. If this is not provided, the inventory
+	// is not locked. The inventory is not locked for dry-runs.
+	InventoryLocker inventory.InventoryLocker
+
+	// DestroyChildren defines whether the child inventories declared
+	// by the inventory are destroyed too. Children are destroyed before
+	// their parents, and an InventoryEvent is sent before the objects
+	// of each inventory are destroyed.
+	DestroyChildren bool
 }
 
 func setDestroyerDefaults(o *DestroyerOptions) {
@@ -94,72 +105,146 @@ func (d *Destroyer) Run(inv inventory.InventoryInfo, options DestroyerOptions) <
 	setDestroyerDefaults(&options)
 	go func() {
 		defer close(eventChannel)
-		// Retrieve the objects to be deleted from the cluster. Second parameter is empty
-		// because no local objects returns all inventory objects for deletion.
-		emptyLocalObjs := []*unstructured.Unstructured{}
-		deleteObjs, err := d.pruneOptions.GetPruneObjs(inv, emptyLocalObjs, prune.Options{
-			DryRunStrategy: options.DryRunStrategy,
-		})
-		if err != nil {
-			handleError(eventChannel, err)
+		if !options.DestroyChildren {
+			d.run(inv, options, eventChannel)
 			return
 		}
-		mapper, err := d.factory.ToRESTMapper()
+		tree, err := inventory.NewInventoryTree(d.invClient, inv)
 		if err != nil {
 			handleError(eventChannel, err)
 			return
 		}
-		klog.V(4).Infoln("destroyer building task queue...")
-		taskBuilder := &solver.TaskQueueBuilder{
-			PruneOptions: d.pruneOptions,
-			Factory:      d.factory,
-			Mapper:       mapper,
-			InvClient:    d.invClient,
-			Destroy:      true,
-		}
-		opts := solver.Options{
-			Prune:                  true,
-			PruneTimeout:           options.DeleteTimeout,
-			DryRunStrategy:         options.DryRunStrategy,
-			PrunePropagationPolicy: options.DeletePropagationPolicy,
-		}
-		deleteFilters := []filter.ValidationFilter{
-			filter.PreventRemoveFilter{},
-			filter.InventoryPolicyFilter{
-				Inv:       inv,
-				InvPolicy: options.InventoryPolicy,
-			},
-		}
-		// Build the ordered set of tasks to execute.
-		taskQueue, err := taskBuilder.
-			AppendPruneWaitTasks(deleteObjs, deleteFilters, opts).
-			AppendDeleteInvTask(inv, options.DryRunStrategy).
-			Build()
-		if err != nil {
-			handleError(eventChannel, err)
-		}
-		// Send event to inform the caller about the resources that
-		// will be pruned.
-		eventChannel <- event.Event{
-			Type: event.InitType,
-			InitEvent: event.InitEvent{
-				ActionGroups: taskQueue.ToActionGroups(),
-			},
-		}
-		// Create a new TaskStatusRunner to execute the taskQueue.
-		klog.V(4).Infoln("destroyer building TaskStatusRunner...")
-		deleteIds := object.UnstructuredsToObjMetasOrDie(deleteObjs)
-		runner := taskrunner.NewTaskStatusRunner(deleteIds, d.statusPoller)
-		klog.V(4).Infoln("destroyer running TaskStatusRunner...")
-		// TODO(seans): Make the poll interval configurable like the applier.
-		err = runner.Run(context.Background(), taskQueue.ToChannel(), eventChannel, taskrunner.Options{
-			UseCache:         true,
-			PollInterval:     options.PollInterval,
-			EmitStatusEvents: options.EmitStatusEvents,
-		})
-		if err != nil {
-			handleError(eventChannel, err)
+		for _, t := range tree.DestroyOrder() {
+			if tree.HasChildren() {
+				eventChannel <- inventoryEvent(t)
+			}
+			failures, ok := d.run(t.Info, options, eventChannel)
+			if !ok {
+				return
+			}
+			// The parents of an inventory that wasn't destroyed
+			// completely are kept, since they usually provide the
+			// namespaces and CRDs of the objects that are left.
+			if failures > 0 && t.Parent != nil {
+				handleError(eventChannel, fmt.Errorf("%d object(s) of inventory %q were not deleted; "+
+					"the remaining inventories are not destroyed", failures, t.Info.ID()))
+				return
+			}
 		}
 	}()
 	return eventChannel
 }
+
+// run destroys the objects of the passed inventory and the inventory
+// object, sending the events on the passed channel. It returns the
+// number of objects that failed to be deleted or were skipped, and
+// false if an error event was sent.
+func (d *Destroyer) run(inv inventory.InventoryInfo, options DestroyerOptions, eventChannel chan event.Event) (int, bool) {
+	// Hold the inventory lock until the run is done, so no other
+	// client changes the inventory in the meantime. The run is
+	// cancelled if the lock is lost.
+	lockCtx, unlock, err := lockInventory(context.Background(), options.InventoryLocker, inv, options.DryRunStrategy)
+	if err != nil {
+		handleError(eventChannel, err)
+		return 0, false
+	}
+	defer func() { _ = unlock() }()
+	// Retrieve the objects to be deleted from the cluster. Second parameter is empty
+	// because no local objects returns all inventory objects for deletion.
+	emptyLocalObjs := []*unstructured.Unstructured{}
+	deleteObjs, err := d.pruneOptions.GetPruneObjs(inv, emptyLocalObjs, prune.Options{
+		DryRunStrategy: options.DryRunStrategy,
+	})
+	if err != nil {
+		handleError(eventChannel, err)
+		return 0, false
+	}
+	invUIDs, err := inventoryUIDs(d.invClient, inv)
+	if err != nil {
+		handleError(eventChannel, err)
+		return 0, false
+	}
+	mapper, err := d.factory.ToRESTMapper()
+	if err != nil {
+		handleError(eventChannel, err)
+		return 0, false
+	}
+	klog.V(4).Infoln("destroyer building task queue...")
+	taskBuilder := &solver.TaskQueueBuilder{
+		PruneOptions: d.pruneOptions,
+		Factory:      d.factory,
+		Mapper:       mapper,
+		InvClient:    d.invClient,
+		Destroy:      true,
+	}
+	opts := solver.Options{
+		Prune:                  true,
+		PruneTimeout:           options.DeleteTimeout,
+		DryRunStrategy:         options.DryRunStrategy,
+		PrunePropagationPolicy: options.DeletePropagationPolicy,
+		InventoryUIDs:          invUIDs,
+	}
+	deleteFilters := []filter.ValidationFilter{
+		filter.PreventRemoveFilter{},
+		filter.InventoryPolicyFilter{
+			Inv:       inv,
+			InvPolicy: options.InventoryPolicy,
+		},
+	}
+	// Build the ordered set of tasks to execute.
+	taskQueue, err := taskBuilder.
+		AppendPruneWaitTasks(deleteObjs, deleteFilters, opts).
+		AppendDeleteInvTask(inv, options.DryRunStrategy).
+		Build()
+	if err != nil {
+		handleError(eventChannel, err)
+		return 0, false
+	}
+	// Send event to inform the caller about the resources that
+	// will be pruned.
+	eventChannel <- event.Event{
+		Type: event.InitType,
+		InitEvent: event.InitEvent{
+			ActionGroups: taskQueue.ToActionGroups(),
+		},
+	}
+	// Create a new TaskStatusRunner to execute the taskQueue.
+	klog.V(4).Infoln("destroyer building TaskStatusRunner...")
+	deleteIds := object.UnstructuredsToObjMetasOrDie(deleteObjs)
+	runner := taskrunner.NewTaskStatusRunner(deleteIds, d.statusPoller)
+	klog.V(4).Infoln("destroyer running TaskStatusRunner...")
+	// TODO(seans): Make the poll interval configurable like the applier.
+	err = runner.Run(lockCtx, taskQueue.ToChannel(), eventChannel, taskrunner.Options{
+		UseCache:         true,
+		PollInterval:     options.PollInterval,
+		EmitStatusEvents: options.EmitStatusEvents,
+	})
+	// A lost lock is reported instead of the cancellation it caused.
+	if unlockErr := unlock(); unlockErr != nil {
+		err = unlockErr
+	}
+	if err != nil {
+		handleError(eventChannel, err)
+		return 0, false
+	}
+	return len(runner.TaskContext().PruneFailures()), true
+}
+
+// inventoryEvent returns the event sent before the objects of the
+// inventory at the root of the passed tree are destroyed.
+func inventoryEvent(tree *inventory.InventoryTree) event.Event {
+	var parent string
+	if tree.Parent != nil {
+		parent = tree.Parent.Info.ID()
+	}
+	return event.Event{
+		Type: event.InventoryType,
+		InventoryEvent: event.InventoryEvent{
+			Namespace: tree.Info.Namespace(),
+			Name:      tree.Info.Name(),
+			ID:        tree.Info.ID(),
+			Parent:    parent,
+			Depth:     tree.Depth(),
+		},
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/poller"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/yaml"
+)
+
+// Differ computes the changes an apply would make to the cluster. It
+// runs the Applier with a server-side dry-run, and compares the result
+// for each object with the object in the cluster. Objects that would
+// be pruned are included, based on the inventory.
+type Differ struct {
+	applier *apply.Applier
+	client  dynamic.Interface
+	mapper  meta.RESTMapper
+}
+
+// NewDiffer returns a new Differ.
+func NewDiffer(factory util.Factory, invClient inventory.InventoryClient, statusPoller poller.Poller) (*Differ, error) {
+	applier, err := apply.NewApplier(factory, invClient, statusPoller)
+	if err != nil {
+		return nil, err
+	}
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Differ{
+		applier: applier,
+		client:  client,
+		mapper:  mapper,
+	}, nil
+}
+
+// Options defines the configuration of the apply that is diffed.
+type Options struct {
+	// Encapsulates the fields for server-side apply.
+	ServerSideOptions common.ServerSideOptions
+
+	// NoPrune defines whether objects that would be pruned should be
+	// left out of the diff.
+	NoPrune bool
+
+	// InventoryPolicy defines the inventory policy of the apply.
+	InventoryPolicy inventory.InventoryPolicy
+}
+
+// ObjectDiff contains the state of a single object before and after
+// the apply.
+type ObjectDiff struct {
+	Identifier object.ObjMetadata
+	// Live is the object in the cluster. It is nil if the object
+	// would be created.
+	Live *unstructured.Unstructured
+	// Merged is the object as it would be after the apply. It is nil
+	// if the object would be pruned.
+	Merged *unstructured.Unstructured
+	// Error is set if the dry-run apply or prune of the object failed.
+	Error error
+}
+
+// DiffError is returned when one or more objects in the cluster differ
+// from the result of the apply. Like kubectl diff, the command exits
+// with status 1 in that case.
+type DiffError struct {
+	// Identifiers are the objects that differ.
+	Identifiers []object.ObjMetadata
+}
+
+func (d DiffError) Error() string {
+	return fmt.Sprintf("%d resource(s) differ", len(d.Identifiers))
+}
+
+// Run runs a server-side dry-run of the apply, and returns the diff for
+// each of the objects that would be applied or pruned. An error is
+// returned if the dry-run could not be run at all.
+func (d *Differ) Run(ctx context.Context, invInfo inventory.InventoryInfo, objects []*unstructured.Unstructured,
+	options Options) ([]ObjectDiff, error) {
+	ch := d.applier.Run(ctx, invInfo, objects, apply.Options{
+		ServerSideOptions: options.ServerSideOptions,
+		NoPrune:           options.NoPrune,
+		DryRunStrategy:    common.DryRunServer,
+		InventoryPolicy:   options.InventoryPolicy,
+	})
+	return d.collect(ctx, ch)
+}
+
+// collect turns the events from a dry-run of the apply into diffs.
+// Prune events for objects that are not pruned, e.g. because of the
+// inventory policy, are skipped.
+func (d *Differ) collect(ctx context.Context, ch <-chan event.Event) ([]ObjectDiff, error) {
+	var diffs []ObjectDiff
+	var err error
+	for e := range ch {
+		switch e.Type {
+		case event.ErrorType:
+			err = e.ErrorEvent.Err
+		case event.ApplyType:
+			diffs = append(diffs, d.applyDiff(ctx, e.ApplyEvent))
+		case event.PruneType:
+			if e.PruneEvent.Error == nil && e.PruneEvent.Operation != event.Pruned {
+				continue
+			}
+			diffs = append(diffs, ObjectDiff{
+				Identifier: e.PruneEvent.Identifier,
+				Live:       e.PruneEvent.Object,
+				Error:      e.PruneEvent.Error,
+			})
+		}
+	}
+	return diffs, err
+}
+
+// applyDiff returns the diff for an object that would be applied. Since
+// nothing is changed by the dry-run, the object in the cluster is
+// fetched after it has been applied.
+func (d *Differ) applyDiff(ctx context.Context, ae event.ApplyEvent) ObjectDiff {
+	diff := ObjectDiff{
+		Identifier: ae.Identifier,
+		Error:      ae.Error,
+	}
+	if ae.Error != nil {
+		return diff
+	}
+	diff.Merged = ae.Resource
+	live, err := d.getObject(ctx, ae.Identifier)
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.V(4).Infof("unable to get %s from the cluster: %s", ae.Identifier, err)
+		diff.Error = err
+		return diff
+	}
+	diff.Live = live
+	return diff
+}
+
+func (d *Differ) getObject(ctx context.Context, id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	mapping, err := d.mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return d.client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Get(ctx, id.Name, metav1.GetOptions{})
+}
+
+// Unified returns the differences between the live and the merged
+// object as a unified diff of their YAML. The managedFields are left
+// out, since they change with every apply. An empty string is
+// returned if there are no differences.
+func (o ObjectDiff) Unified() (string, error) {
+	live, err := toYAML(o.Live)
+	if err != nil {
+		return "", err
+	}
+	merged, err := toYAML(o.Merged)
+	if err != nil {
+		return "", err
+	}
+	path := resourcePath(o.Identifier)
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(live),
+		B:        splitLines(merged),
+		FromFile: "live/" + path,
+		ToFile:   "merged/" + path,
+		Context:  3,
+	})
+}
+
+// splitLines splits the text into lines that keep their line endings.
+// Unlike difflib.SplitLines, no empty line is added at the end.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func toYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	b, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// resourcePath returns a path that identifies the object in the
+// headers of the diff, e.g. deployment.apps/default/foo
+func resourcePath(id object.ObjMetadata) string {
+	gk := strings.ToLower(id.GroupKind.String())
+	if id.Namespace == "" {
+		return fmt.Sprintf("%s/%s", gk, id.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", gk, id.Namespace, id.Name)
+}
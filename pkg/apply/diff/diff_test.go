@@ -0,0 +1,227 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newConfigMap(name, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"data": map[string]interface{}{
+				"key": value,
+			},
+		},
+	}
+}
+
+func withManagedFields(u *unstructured.Unstructured, manager string) *unstructured.Unstructured {
+	u = u.DeepCopy()
+	u.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: manager}})
+	return u
+}
+
+func TestObjectDiff_Unified(t *testing.T) {
+	id := object.UnstructuredToObjMetaOrDie(newConfigMap("foo", "a"))
+
+	testCases := map[string]struct {
+		diff     ObjectDiff
+		expected string
+	}{
+		"changed": {
+			diff: ObjectDiff{
+				Identifier: id,
+				Live:       newConfigMap("foo", "a"),
+				Merged:     newConfigMap("foo", "b"),
+			},
+			expected: `--- live/configmap/default/foo
++++ merged/configmap/default/foo
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  key: a
++  key: b
+ kind: ConfigMap
+ metadata:
+   name: foo
+`,
+		},
+		"unchanged": {
+			diff: ObjectDiff{
+				Identifier: id,
+				Live:       newConfigMap("foo", "a"),
+				Merged:     newConfigMap("foo", "a"),
+			},
+			expected: "",
+		},
+		"managedFields are ignored": {
+			diff: ObjectDiff{
+				Identifier: id,
+				Live:       withManagedFields(newConfigMap("foo", "a"), "kubectl"),
+				Merged:     withManagedFields(newConfigMap("foo", "a"), "kapply"),
+			},
+			expected: "",
+		},
+		"created": {
+			diff: ObjectDiff{
+				Identifier: id,
+				Merged:     newConfigMap("foo", "a"),
+			},
+			expected: `--- live/configmap/default/foo
++++ merged/configmap/default/foo
+@@ -0,0 +1,7 @@
++apiVersion: v1
++data:
++  key: a
++kind: ConfigMap
++metadata:
++  name: foo
++  namespace: default
+`,
+		},
+		"pruned": {
+			diff: ObjectDiff{
+				Identifier: id,
+				Live:       newConfigMap("foo", "a"),
+			},
+			expected: `--- live/configmap/default/foo
++++ merged/configmap/default/foo
+@@ -1,7 +0,0 @@
+-apiVersion: v1
+-data:
+-  key: a
+-kind: ConfigMap
+-metadata:
+-  name: foo
+-  namespace: default
+`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			actual, err := tc.diff.Unified()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestDiffer_Collect(t *testing.T) {
+	changed := newConfigMap("changed", "b")
+	created := newConfigMap("created", "a")
+	pruned := newConfigMap("pruned", "a")
+	skipped := newConfigMap("skipped", "a")
+	failed := newConfigMap("failed", "a")
+
+	d := &Differ{
+		client: fake.NewSimpleDynamicClient(scheme.Scheme, newConfigMap("changed", "a")),
+		mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	applyErr := fmt.Errorf("apply failed")
+	events := []event.Event{
+		{Type: event.InitType},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Identifier: object.UnstructuredToObjMetaOrDie(changed),
+				Operation:  event.Configured,
+				Resource:   changed,
+			},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Identifier: object.UnstructuredToObjMetaOrDie(created),
+				Operation:  event.Created,
+				Resource:   created,
+			},
+		},
+		{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Identifier: object.UnstructuredToObjMetaOrDie(failed),
+				Error:      applyErr,
+			},
+		},
+		{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				Identifier: object.UnstructuredToObjMetaOrDie(pruned),
+				Operation:  event.Pruned,
+				Object:     pruned,
+			},
+		},
+		{
+			Type: event.PruneType,
+			PruneEvent: event.PruneEvent{
+				Identifier: object.UnstructuredToObjMetaOrDie(skipped),
+				Operation:  event.PruneSkipped,
+				Object:     skipped,
+			},
+		},
+	}
+	ch := make(chan event.Event, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+
+	diffs, err := d.collect(context.Background(), ch)
+	require.NoError(t, err)
+	require.Len(t, diffs, 4)
+
+	assert.Equal(t, object.UnstructuredToObjMetaOrDie(changed), diffs[0].Identifier)
+	assert.NoError(t, diffs[0].Error)
+	assert.Equal(t, newConfigMap("changed", "a"), diffs[0].Live)
+	assert.Equal(t, changed, diffs[0].Merged)
+
+	assert.Equal(t, object.UnstructuredToObjMetaOrDie(created), diffs[1].Identifier)
+	assert.NoError(t, diffs[1].Error)
+	assert.Nil(t, diffs[1].Live)
+	assert.Equal(t, created, diffs[1].Merged)
+
+	assert.Equal(t, object.UnstructuredToObjMetaOrDie(failed), diffs[2].Identifier)
+	assert.Equal(t, applyErr, diffs[2].Error)
+
+	assert.Equal(t, object.UnstructuredToObjMetaOrDie(pruned), diffs[3].Identifier)
+	assert.NoError(t, diffs[3].Error)
+	assert.Equal(t, pruned, diffs[3].Live)
+	assert.Nil(t, diffs[3].Merged)
+}
+
+func TestDiffer_Collect_Error(t *testing.T) {
+	ch := make(chan event.Event, 1)
+	ch <- event.Event{
+		Type: event.ErrorType,
+		ErrorEvent: event.ErrorEvent{
+			Err: fmt.Errorf("inventory not found"),
+		},
+	}
+	close(ch)
+
+	_, err := (&Differ{}).collect(context.Background(), ch)
+	assert.EqualError(t, err, "inventory not found")
+}
@@ -0,0 +1,397 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// ignoredAnnotations are added by the tools that read or apply the
+// manifests, and are never compared.
+var ignoredAnnotations = map[string]bool{
+	v1.LastAppliedConfigAnnotation: true,
+	kioutil.PathAnnotation:         true,
+	kioutil.IndexAnnotation:        true,
+}
+
+// Compare returns the fields of the live object that don't match the
+// local manifest. Only the fields set in the local manifest are
+// compared, so fields that have been defaulted or are set by
+// controllers are not reported. The status and all metadata except
+// labels and annotations are never compared.
+//
+// If the live object has been applied with client-side apply, only
+// the fields in the last-applied annotation are compared, so fields
+// that have been added to the local manifest since the last apply are
+// not reported as drift. For each field that has drifted, the field
+// manager in the managedFields of the live object that last changed it
+// is reported, ignoring the provided fieldManager.
+func Compare(local, live *unstructured.Unstructured, fieldManager string) ([]event.FieldDrift, error) {
+	applied, err := appliedObject(local, live)
+	if err != nil {
+		return nil, err
+	}
+	c := &comparer{
+		managers: newManagedFields(live, fieldManager),
+	}
+	for _, key := range sortedKeys(local.Object) {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			c.compareMetadata(local, live, applied)
+		default:
+			c.compare(path{fieldElement(key)}, applied[key], local.Object[key], live.Object[key])
+		}
+	}
+	return c.drift, nil
+}
+
+// appliedObject returns the configuration that was last applied to the
+// live object. This is the last-applied annotation for objects applied
+// with client-side apply, and the local manifest otherwise.
+func appliedObject(local, live *unstructured.Unstructured) (map[string]interface{}, error) {
+	lastApplied, found := live.GetAnnotations()[v1.LastAppliedConfigAnnotation]
+	if !found {
+		return local.Object, nil
+	}
+	applied := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", v1.LastAppliedConfigAnnotation, err)
+	}
+	return applied, nil
+}
+
+type comparer struct {
+	managers managedFields
+	drift    []event.FieldDrift
+}
+
+func (c *comparer) report(p path) {
+	c.drift = append(c.drift, event.FieldDrift{
+		Field:   p.String(),
+		Manager: c.managers.managerOf(p),
+	})
+}
+
+// compareMetadata compares the labels and annotations of the objects.
+func (c *comparer) compareMetadata(local, live *unstructured.Unstructured, applied map[string]interface{}) {
+	appliedMetadata, _ := applied["metadata"].(map[string]interface{})
+	for _, field := range []string{"annotations", "labels"} {
+		localValues, _, _ := unstructured.NestedStringMap(local.Object, "metadata", field)
+		liveValues, _, _ := unstructured.NestedStringMap(live.Object, "metadata", field)
+		appliedValues, _ := appliedMetadata[field].(map[string]interface{})
+		for _, key := range sortedStringKeys(localValues) {
+			if field == "annotations" && ignoredAnnotations[key] {
+				continue
+			}
+			if _, found := appliedValues[key]; !found {
+				continue
+			}
+			liveValue, found := liveValues[key]
+			if !found || liveValue != localValues[key] {
+				c.report(path{fieldElement("metadata"), fieldElement(field), fieldElement(key)})
+			}
+		}
+	}
+}
+
+// compare compares the local value with the live value at the given
+// path, and reports the paths of the values that don't match. Values
+// that are not part of the applied configuration are skipped.
+func (c *comparer) compare(p path, applied, local, live interface{}) {
+	if applied == nil {
+		return
+	}
+	switch localValue := local.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			c.report(p)
+			return
+		}
+		appliedValue, _ := applied.(map[string]interface{})
+		for _, key := range sortedKeys(localValue) {
+			childPath := p.child(fieldElement(key))
+			liveChild, found := liveValue[key]
+			if !found {
+				if _, applied := appliedValue[key]; applied {
+					c.report(childPath)
+				}
+				continue
+			}
+			c.compare(childPath, appliedValue[key], localValue[key], liveChild)
+		}
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok {
+			c.report(p)
+			return
+		}
+		if !isNamedList(localValue) {
+			if !isSubset(localValue, liveValue) {
+				c.report(p)
+			}
+			return
+		}
+		appliedValue, _ := applied.([]interface{})
+		for _, item := range localValue {
+			name := itemName(item)
+			childPath := p.child(nameElement(name))
+			liveItem, found := findNamedItem(liveValue, name)
+			if !found {
+				if _, applied := findNamedItem(appliedValue, name); applied {
+					c.report(childPath)
+				}
+				continue
+			}
+			appliedItem, _ := findNamedItem(appliedValue, name)
+			c.compare(childPath, appliedItem, item, liveItem)
+		}
+	default:
+		if !scalarEqual(local, live) {
+			c.report(p)
+		}
+	}
+}
+
+// isNamedList returns true if all items in the list are maps with a
+// name field. The items in these lists are matched by their name
+// rather than by their position.
+func isNamedList(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if itemName(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func itemName(item interface{}) string {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := m["name"].(string)
+	return name
+}
+
+func findNamedItem(list []interface{}, name string) (interface{}, bool) {
+	for _, item := range list {
+		if itemName(item) == name {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+// isSubset returns true if all fields in the local value have the same
+// value in the live value. Lists must have the same length.
+func isSubset(local, live interface{}) bool {
+	switch localValue := local.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range localValue {
+			if !isSubset(value, liveValue[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(localValue) != len(liveValue) {
+			return false
+		}
+		for i := range localValue {
+			if !isSubset(localValue[i], liveValue[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return scalarEqual(local, live)
+	}
+}
+
+// scalarEqual compares two scalar values. Numbers are compared by
+// value, since the same number can be decoded as either an integer or
+// a float depending on where it was read from.
+func scalarEqual(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	af, aIsNumber := toFloat(a)
+	bf, bIsNumber := toFloat(b)
+	return aIsNumber && bIsNumber && af == bf
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pathElement is a single step in the path to a field. It is either
+// a field in a map, or an item in a list identified by its name.
+type pathElement struct {
+	field string
+	name  string
+}
+
+func fieldElement(field string) pathElement {
+	return pathElement{field: field}
+}
+
+func nameElement(name string) pathElement {
+	return pathElement{name: name}
+}
+
+// path is the path to a field in an object.
+type path []pathElement
+
+func (p path) child(e pathElement) path {
+	child := make(path, len(p), len(p)+1)
+	copy(child, p)
+	return append(child, e)
+}
+
+// String returns the path in the form .spec.containers[name=app].image
+func (p path) String() string {
+	var sb strings.Builder
+	for _, e := range p {
+		if e.field != "" {
+			sb.WriteString(".")
+			sb.WriteString(e.field)
+			continue
+		}
+		sb.WriteString("[name=")
+		sb.WriteString(e.name)
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+// managedFields contains the fields managed by each of the field
+// managers of an object, except for the field manager used to apply
+// the object. The entries are sorted with the most recent first.
+type managedFields []managedFieldsEntry
+
+type managedFieldsEntry struct {
+	manager string
+	fields  map[string]interface{}
+}
+
+func newManagedFields(obj *unstructured.Unstructured, fieldManager string) managedFields {
+	entries := obj.GetManagedFields()
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Time == nil || entries[j].Time == nil {
+			return entries[j].Time == nil && entries[i].Time != nil
+		}
+		return entries[j].Time.Before(entries[i].Time)
+	})
+	var mf managedFields
+	for _, entry := range entries {
+		if entry.Manager == fieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			klog.V(4).Infof("invalid managed fields for manager %s: %s", entry.Manager, err)
+			continue
+		}
+		mf = append(mf, managedFieldsEntry{
+			manager: entry.Manager,
+			fields:  fields,
+		})
+	}
+	return mf
+}
+
+// managerOf returns the most recent field manager that manages the
+// field at the given path, or an empty string if there is none.
+func (mf managedFields) managerOf(p path) string {
+	for _, entry := range mf {
+		if entry.manages(p) {
+			return entry.manager
+		}
+	}
+	return ""
+}
+
+// manages returns true if the fields of the entry include the given
+// path. The fields are in the FieldsV1 format, where f:<name> is a
+// field and k:<json> is an item in a list identified by its keys.
+func (e managedFieldsEntry) manages(p path) bool {
+	node := e.fields
+	for _, elem := range p {
+		key, found := fieldsKey(node, elem)
+		if !found {
+			return false
+		}
+		node, _ = node[key].(map[string]interface{})
+	}
+	return true
+}
+
+func fieldsKey(node map[string]interface{}, elem pathElement) (string, bool) {
+	if elem.field != "" {
+		key := "f:" + elem.field
+		_, found := node[key]
+		return key, found
+	}
+	for key := range node {
+		if !strings.HasPrefix(key, "k:") {
+			continue
+		}
+		itemKeys := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &itemKeys); err != nil {
+			continue
+		}
+		if name, ok := itemKeys["name"].(string); ok && name == elem.name {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+)
+
+func newDeployment(replicas int64, image string, labels map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": image,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if labels != nil {
+		u.Object["metadata"].(map[string]interface{})["labels"] = labels
+	}
+	return u
+}
+
+// withDefaults adds fields to a live object that are set by the
+// API server and controllers.
+func withDefaults(u *unstructured.Unstructured) *unstructured.Unstructured {
+	u = u.DeepCopy()
+	u.SetUID("uid")
+	u.SetResourceVersion("42")
+	_ = unstructured.SetNestedField(u.Object, "Always", "spec", "template", "spec", "restartPolicy")
+	_ = unstructured.SetNestedField(u.Object, int64(1), "status", "replicas")
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	containers[0].(map[string]interface{})["imagePullPolicy"] = "IfNotPresent"
+	_ = unstructured.SetNestedSlice(u.Object, containers, "spec", "template", "spec", "containers")
+	return u
+}
+
+func withManagedFields(u *unstructured.Unstructured, entries ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+	u = u.DeepCopy()
+	u.SetManagedFields(entries)
+	return u
+}
+
+func newManagedFieldsEntry(manager string, t time.Time, fields string) metav1.ManagedFieldsEntry {
+	mt := metav1.NewTime(t)
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "apps/v1",
+		Time:       &mt,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func withLastApplied(u *unstructured.Unstructured, lastApplied string) *unstructured.Unstructured {
+	u = u.DeepCopy()
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[v1.LastAppliedConfigAnnotation] = lastApplied
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func TestCompare(t *testing.T) {
+	t1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	testCases := map[string]struct {
+		local    *unstructured.Unstructured
+		live     *unstructured.Unstructured
+		expected []event.FieldDrift
+	}{
+		"defaulted fields are not drift": {
+			local:    newDeployment(3, "nginx:1.19", nil),
+			live:     withDefaults(newDeployment(3, "nginx:1.19", nil)),
+			expected: nil,
+		},
+		"changed field with manager": {
+			local: newDeployment(3, "nginx:1.19", nil),
+			live: withManagedFields(withDefaults(newDeployment(5, "nginx:1.19", nil)),
+				newManagedFieldsEntry("kapply", t1, `{"f:spec":{"f:template":{}}}`),
+				newManagedFieldsEntry("kubectl-scale", t2, `{"f:spec":{"f:replicas":{}}}`),
+			),
+			expected: []event.FieldDrift{
+				{Field: ".spec.replicas", Manager: "kubectl-scale"},
+			},
+		},
+		"changed list item is matched by name": {
+			local: newDeployment(3, "nginx:1.19", nil),
+			live: withManagedFields(withDefaults(newDeployment(3, "nginx:1.20", nil)),
+				newManagedFieldsEntry("kubectl-edit", t1,
+					`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{"f:image":{}}}}}}}`),
+			),
+			expected: []event.FieldDrift{
+				{Field: ".spec.template.spec.containers[name=app].image", Manager: "kubectl-edit"},
+			},
+		},
+		"most recent manager is reported": {
+			local: newDeployment(3, "nginx:1.19", nil),
+			live: withManagedFields(newDeployment(5, "nginx:1.19", nil),
+				newManagedFieldsEntry("older", t1, `{"f:spec":{"f:replicas":{}}}`),
+				newManagedFieldsEntry("newer", t2, `{"f:spec":{"f:replicas":{}}}`),
+			),
+			expected: []event.FieldDrift{
+				{Field: ".spec.replicas", Manager: "newer"},
+			},
+		},
+		"changes by the applier itself are not attributed": {
+			local: newDeployment(3, "nginx:1.19", nil),
+			live: withManagedFields(newDeployment(5, "nginx:1.19", nil),
+				newManagedFieldsEntry("kapply", t1, `{"f:spec":{"f:replicas":{}}}`),
+			),
+			expected: []event.FieldDrift{
+				{Field: ".spec.replicas"},
+			},
+		},
+		"changed and removed labels": {
+			local: newDeployment(3, "nginx:1.19", map[string]interface{}{
+				"app":  "foo",
+				"tier": "web",
+			}),
+			live: newDeployment(3, "nginx:1.19", map[string]interface{}{
+				"app": "bar",
+			}),
+			expected: []event.FieldDrift{
+				{Field: ".metadata.labels.app"},
+				{Field: ".metadata.labels.tier"},
+			},
+		},
+		"ignored annotations": {
+			local: withLastApplied(newDeployment(3, "nginx:1.19", nil), `{"metadata":{}}`),
+			live:  newDeployment(3, "nginx:1.19", nil),
+		},
+		"fields not in the last-applied configuration are not drift": {
+			local: newDeployment(3, "nginx:1.20", nil),
+			live: withLastApplied(newDeployment(5, "nginx:1.19", nil),
+				`{"apiVersion":"apps/v1","kind":"Deployment","spec":{"replicas":3}}`),
+			expected: []event.FieldDrift{
+				{Field: ".spec.replicas"},
+			},
+		},
+		"numbers are compared by value": {
+			local: newDeployment(3, "nginx:1.19", nil),
+			live: func() *unstructured.Unstructured {
+				u := newDeployment(3, "nginx:1.19", nil)
+				u.Object["spec"].(map[string]interface{})["replicas"] = float64(3)
+				return u
+			}(),
+			expected: nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			drift, err := Compare(tc.local, tc.live, "kapply")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, drift)
+		})
+	}
+}
+
+func TestCompare_InvalidLastApplied(t *testing.T) {
+	_, err := Compare(newDeployment(3, "nginx:1.19", nil),
+		withLastApplied(newDeployment(3, "nginx:1.19", nil), "{"), "kapply")
+	assert.Error(t, err)
+}
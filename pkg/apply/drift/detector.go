@@ -0,0 +1,188 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Detector compares the objects in an inventory with the local
+// manifests they were applied from, to find the objects that have been
+// changed in the cluster since they were applied.
+type Detector struct {
+	invClient inventory.InventoryClient
+	client    dynamic.Interface
+	mapper    meta.RESTMapper
+}
+
+// NewDetector returns a new Detector that reads the inventory using
+// the provided InventoryClient.
+func NewDetector(factory util.Factory, invClient inventory.InventoryClient) (*Detector, error) {
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Detector{
+		invClient: invClient,
+		client:    client,
+		mapper:    mapper,
+	}, nil
+}
+
+// Options defines the configuration of a drift detection.
+type Options struct {
+	// FieldManager is the field manager that was used to apply the
+	// objects. Changes made by this field manager are not attributed
+	// to it in the drift report, since they were made by the applier.
+	FieldManager string
+}
+
+// Run compares each object in the inventory with its local manifest,
+// and sends a DriftEvent with the result for each of them on the
+// returned channel. Objects in the inventory without a local manifest
+// are not checked, since they would be pruned by the next apply. The
+// same goes for local manifests that are not in the inventory yet. The
+// channel is closed when all objects have been checked.
+func (d *Detector) Run(ctx context.Context, invInfo inventory.InventoryInfo, objects []*unstructured.Unstructured,
+	options Options) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+	go func() {
+		defer close(eventChannel)
+		clusterObjs, err := d.invClient.GetClusterObjs(invInfo, common.DryRunNone)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		localObjs := make(map[object.ObjMetadata]*unstructured.Unstructured)
+		for _, obj := range objects {
+			id, err := object.UnstructuredToObjMeta(obj)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			localObjs[id] = obj
+		}
+		var ids []object.ObjMetadata
+		for _, id := range clusterObjs {
+			if _, found := localObjs[id]; found {
+				ids = append(ids, id)
+			}
+		}
+		klog.V(4).Infof("drift detection for %d objects", len(ids))
+
+		const groupName = "drift-0"
+		eventChannel <- event.Event{
+			Type: event.InitType,
+			InitEvent: event.InitEvent{
+				ActionGroups: []event.ActionGroup{{
+					Name:        groupName,
+					Action:      event.DriftAction,
+					Identifiers: ids,
+				}},
+			},
+		}
+		eventChannel <- actionGroupEvent(groupName, event.Started)
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				handleError(eventChannel, ctx.Err())
+				return
+			}
+			eventChannel <- d.detect(ctx, id, localObjs[id], options)
+		}
+		eventChannel <- actionGroupEvent(groupName, event.Finished)
+	}()
+	return eventChannel
+}
+
+// detect fetches the live object and compares it with the local
+// manifest.
+func (d *Detector) detect(ctx context.Context, id object.ObjMetadata, local *unstructured.Unstructured,
+	options Options) event.Event {
+	e := event.Event{
+		Type: event.DriftType,
+		DriftEvent: event.DriftEvent{
+			Identifier: id,
+		},
+	}
+	live, err := d.getObject(ctx, local)
+	if apierrors.IsNotFound(err) {
+		e.DriftEvent.Operation = event.DriftMissing
+		return e
+	}
+	if err != nil {
+		e.DriftEvent.Error = err
+		return e
+	}
+	e.DriftEvent.Object = live
+	fields, err := Compare(local, live, options.FieldManager)
+	if err != nil {
+		e.DriftEvent.Error = err
+		return e
+	}
+	if len(fields) == 0 {
+		e.DriftEvent.Operation = event.InSync
+		return e
+	}
+	e.DriftEvent.Operation = event.Drifted
+	e.DriftEvent.Fields = fields
+	return e
+}
+
+func (d *Detector) getObject(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	return d.client.Resource(mapping.Resource).Namespace(obj.GetNamespace()).
+		Get(ctx, obj.GetName(), metav1.GetOptions{})
+}
+
+func actionGroupEvent(groupName string, t event.ActionGroupEventType) event.Event {
+	return event.Event{
+		Type: event.ActionGroupType,
+		ActionGroupEvent: event.ActionGroupEvent{
+			GroupName: groupName,
+			Action:    event.DriftAction,
+			Type:      t,
+		},
+	}
+}
+
+func handleError(eventChannel chan event.Event, err error) {
+	eventChannel <- event.Event{
+		Type: event.ErrorType,
+		ErrorEvent: event.ErrorEvent{
+			Err: err,
+		},
+	}
+}
+
+// DriftError is returned when one or more objects in the cluster
+// have drifted from their local manifests.
+type DriftError struct {
+	// Identifiers are the objects that have drifted or are missing.
+	Identifiers []object.ObjMetadata
+}
+
+func (d DriftError) Error() string {
+	return fmt.Sprintf("%d resource(s) drifted", len(d.Identifiers))
+}
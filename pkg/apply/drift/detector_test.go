@@ -0,0 +1,119 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newConfigMap(name, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"data": map[string]interface{}{
+				"key": value,
+			},
+		},
+	}
+}
+
+func TestDetector_Run(t *testing.T) {
+	inSync := newConfigMap("in-sync", "a")
+	drifted := newConfigMap("drifted", "a")
+	missing := newConfigMap("missing", "a")
+	notApplied := newConfigMap("not-applied", "a")
+	invOnly := newConfigMap("inventory-only", "a")
+
+	local := []*unstructured.Unstructured{inSync, drifted, missing, notApplied}
+	inv := object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{
+		inSync, drifted, missing, invOnly,
+	})
+	live := []runtime.Object{
+		inSync.DeepCopy(),
+		newConfigMap("drifted", "b"),
+		invOnly.DeepCopy(),
+	}
+
+	d := &Detector{
+		invClient: inventory.NewFakeInventoryClient(inv),
+		client:    fake.NewSimpleDynamicClient(scheme.Scheme, live...),
+		mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	var events []event.Event
+	for e := range d.Run(context.Background(), nil, local, Options{FieldManager: "kapply"}) {
+		events = append(events, e)
+	}
+
+	require.Len(t, events, 6)
+	assert.Equal(t, event.InitType, events[0].Type)
+	require.Len(t, events[0].InitEvent.ActionGroups, 1)
+	ag := events[0].InitEvent.ActionGroups[0]
+	assert.Equal(t, event.DriftAction, ag.Action)
+	assert.Equal(t, object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{
+		inSync, drifted, missing,
+	}), ag.Identifiers)
+	assert.Equal(t, event.ActionGroupType, events[1].Type)
+	assert.Equal(t, event.Started, events[1].ActionGroupEvent.Type)
+
+	expected := []event.DriftEvent{
+		{
+			Identifier: object.UnstructuredToObjMetaOrDie(inSync),
+			Operation:  event.InSync,
+		},
+		{
+			Identifier: object.UnstructuredToObjMetaOrDie(drifted),
+			Operation:  event.Drifted,
+			Fields: []event.FieldDrift{
+				{Field: ".data.key"},
+			},
+		},
+		{
+			Identifier: object.UnstructuredToObjMetaOrDie(missing),
+			Operation:  event.DriftMissing,
+		},
+	}
+	for i, exp := range expected {
+		e := events[i+2]
+		require.Equal(t, event.DriftType, e.Type)
+		assert.NoError(t, e.DriftEvent.Error)
+		assert.Equal(t, exp.Identifier, e.DriftEvent.Identifier)
+		assert.Equal(t, exp.Operation, e.DriftEvent.Operation)
+		assert.Equal(t, exp.Fields, e.DriftEvent.Fields)
+	}
+	assert.Equal(t, event.Finished, events[5].ActionGroupEvent.Type)
+}
+
+func TestDetector_Run_InventoryError(t *testing.T) {
+	d := &Detector{
+		invClient: &inventory.FakeInventoryClient{Err: fmt.Errorf("inventory not found")},
+	}
+
+	var events []event.Event
+	for e := range d.Run(context.Background(), nil, nil, Options{}) {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, event.ErrorType, events[0].Type)
+	assert.EqualError(t, events[0].ErrorEvent.Err, "inventory not found")
+}
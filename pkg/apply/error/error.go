@@ -2,6 +2,12 @@
 // SPDX-License-Identifier: Apache-2.0
 package error
 
+import (
+	"fmt"
+
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
 type UnknownTypeError struct {
 	err error
 }
@@ -37,3 +43,38 @@ func (e *InitializeApplyOptionError) Error() string {
 func NewInitializeApplyOptionError(err error) *InitializeApplyOptionError {
 	return &InitializeApplyOptionError{err: err}
 }
+
+// ApplyFailedError is returned from an apply task that fails if
+// any of its objects could not be applied.
+type ApplyFailedError struct {
+	Identifiers []object.ObjMetadata
+}
+
+func (e *ApplyFailedError) Error() string {
+	return fmt.Sprintf("failed to apply %d resource(s)", len(e.Identifiers))
+}
+
+func NewApplyFailedError(ids []object.ObjMetadata) *ApplyFailedError {
+	return &ApplyFailedError{Identifiers: ids}
+}
+
+// HookFailedError is returned from a hook task if one of its hooks
+// could not be created or has failed.
+type HookFailedError struct {
+	Identifier object.ObjMetadata
+	Phase      string
+	err        error
+}
+
+func (e *HookFailedError) Error() string {
+	return fmt.Sprintf("%s hook %s/%s failed: %v", e.Phase,
+		e.Identifier.Namespace, e.Identifier.Name, e.err)
+}
+
+func (e *HookFailedError) Unwrap() error {
+	return e.err
+}
+
+func NewHookFailedError(id object.ObjMetadata, phase string, err error) *HookFailedError {
+	return &HookFailedError{Identifier: id, Phase: phase, err: err}
+}
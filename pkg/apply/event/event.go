@@ -21,6 +21,10 @@ const (
 	StatusType
 	PruneType
 	DeleteType
+	RollbackType
+	HookType
+	DriftType
+	InventoryType
 )
 
 // Event is the type of the objects that will be returned through
@@ -58,6 +62,23 @@ type Event struct {
 	// DeleteEvent contains information about object that have been
 	// deleted.
 	DeleteEvent DeleteEvent
+
+	// RollbackEvent contains information about objects that have
+	// been rolled back after a failed apply.
+	RollbackEvent RollbackEvent
+
+	// HookEvent contains information about the hooks that have
+	// been run.
+	HookEvent HookEvent
+
+	// DriftEvent contains information about the differences between
+	// the objects in the cluster and the local manifests.
+	DriftEvent DriftEvent
+
+	// InventoryEvent contains information about the inventory whose
+	// objects are processed next, when a tree of nested inventories
+	// is processed.
+	InventoryEvent InventoryEvent
 }
 
 type InitEvent struct {
@@ -73,6 +94,9 @@ const (
 	DeleteAction
 	WaitAction
 	InventoryAction
+	RollbackAction
+	HookAction
+	DriftAction
 )
 
 type ActionGroup struct {
@@ -115,6 +139,19 @@ type ApplyEvent struct {
 	Operation  ApplyEventOperation
 	Resource   *unstructured.Unstructured
 	Error      error
+	// Conflicts contains the fields that could not be applied because
+	// they are managed by other field managers. It is only set if a
+	// server-side apply failed because of conflicts.
+	Conflicts []FieldConflict
+}
+
+// FieldConflict is a field that is managed by a different field
+// manager than the one used for a server-side apply.
+type FieldConflict struct {
+	// Field is the path of the field, e.g. .spec.replicas.
+	Field string
+	// Manager is the field manager that currently manages the field.
+	Manager string
 }
 
 type StatusEvent struct {
@@ -159,3 +196,106 @@ type DeleteEvent struct {
 	Reason string
 	Error  error
 }
+
+//go:generate stringer -type=RollbackEventOperation
+type RollbackEventOperation int
+
+const (
+	RollbackUnspecified RollbackEventOperation = iota
+	// RollbackRestored means the object has been restored to the
+	// state it had before it was applied.
+	RollbackRestored
+	// RollbackDeleted means the object did not exist before it was
+	// applied, so it has been deleted.
+	RollbackDeleted
+)
+
+type RollbackEvent struct {
+	Identifier object.ObjMetadata
+	Operation  RollbackEventOperation
+	Object     *unstructured.Unstructured
+	Error      error
+}
+
+//go:generate stringer -type=HookEventOperation
+type HookEventOperation int
+
+const (
+	HookUnspecified HookEventOperation = iota
+	// HookCreated means the hook object has been created, and
+	// the hook is running.
+	HookCreated
+	// HookSucceeded means the hook has completed successfully.
+	HookSucceeded
+	// HookFailed means the hook could not be created or has failed.
+	HookFailed
+	// HookDeleted means the hook object has been deleted according
+	// to its delete policy.
+	HookDeleted
+	// HookSkipped means the hook has not been run, because this
+	// is a dry-run.
+	HookSkipped
+)
+
+type HookEvent struct {
+	Identifier object.ObjMetadata
+	// Phase is the phase of the apply in which the hook is run.
+	Phase     string
+	Operation HookEventOperation
+	Object    *unstructured.Unstructured
+	Error     error
+}
+
+//go:generate stringer -type=DriftEventOperation
+type DriftEventOperation int
+
+const (
+	DriftUnspecified DriftEventOperation = iota
+	// InSync means the fields of the object in the cluster match
+	// the local manifest.
+	InSync
+	// Drifted means one or more fields of the object in the cluster
+	// have been changed since it was applied.
+	Drifted
+	// DriftMissing means the object is in the inventory, but has
+	// been deleted from the cluster.
+	DriftMissing
+)
+
+type DriftEvent struct {
+	Identifier object.ObjMetadata
+	Operation  DriftEventOperation
+	// Object is the object in the cluster. It is not set if the
+	// object is missing.
+	Object *unstructured.Unstructured
+	// Fields contains the fields that have drifted. It is only set
+	// if the Operation is Drifted.
+	Fields []FieldDrift
+	Error  error
+}
+
+// FieldDrift is a field of an object in the cluster that doesn't
+// match the local manifest.
+type FieldDrift struct {
+	// Field is the path of the field, e.g. .spec.replicas.
+	Field string
+	// Manager is the field manager that last changed the field. It
+	// is empty if it can't be determined.
+	Manager string
+}
+
+// InventoryEvent is sent before the objects of an inventory in a tree
+// of nested inventories are processed. The events that follow it, up
+// to the next InventoryEvent, are about the objects of this inventory.
+type InventoryEvent struct {
+	// Namespace, Name and ID identify the inventory object.
+	Namespace string
+	Name      string
+	ID        string
+	// Parent is the ID of the parent inventory. It is empty for the
+	// root of the tree.
+	Parent string
+	// Depth is the depth of the inventory in the tree. The depth of
+	// the root is zero.
+	Depth int
+}
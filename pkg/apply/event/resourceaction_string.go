@@ -16,11 +16,14 @@ func _() {
 	_ = x[DeleteAction-2]
 	_ = x[WaitAction-3]
 	_ = x[InventoryAction-4]
+	_ = x[RollbackAction-5]
+	_ = x[HookAction-6]
+	_ = x[DriftAction-7]
 }
 
-const _ResourceAction_name = "ApplyActionPruneActionDeleteActionWaitActionInventoryAction"
+const _ResourceAction_name = "ApplyActionPruneActionDeleteActionWaitActionInventoryActionRollbackActionHookActionDriftAction"
 
-var _ResourceAction_index = [...]uint8{0, 11, 22, 34, 44, 59}
+var _ResourceAction_index = [...]uint8{0, 11, 22, 34, 44, 59, 73, 83, 94}
 
 func (i ResourceAction) String() string {
 	if i < 0 || i >= ResourceAction(len(_ResourceAction_index)-1) {
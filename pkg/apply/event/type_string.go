@@ -18,11 +18,15 @@ func _() {
 	_ = x[StatusType-4]
 	_ = x[PruneType-5]
 	_ = x[DeleteType-6]
+	_ = x[RollbackType-7]
+	_ = x[HookType-8]
+	_ = x[DriftType-9]
+	_ = x[InventoryType-10]
 }
 
-const _Type_name = "InitTypeErrorTypeActionGroupTypeApplyTypeStatusTypePruneTypeDeleteType"
+const _Type_name = "InitTypeErrorTypeActionGroupTypeApplyTypeStatusTypePruneTypeDeleteTypeRollbackTypeHookTypeDriftTypeInventoryType"
 
-var _Type_index = [...]uint8{0, 8, 17, 32, 41, 51, 60, 70}
+var _Type_index = [...]uint8{0, 8, 17, 32, 41, 51, 60, 70, 82, 90, 99, 112}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
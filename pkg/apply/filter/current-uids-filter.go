@@ -7,30 +7,72 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 // CurrentUIDFilter implements ValidationFilter interface to determine
 // if an object should not be pruned (deleted) because it has recently
-// been applied.
+// been applied, or because it is not the object that was applied. The
+// latter happens when the applied object was deleted and an object with
+// the same name was recreated outside of the inventory.
 type CurrentUIDFilter struct {
 	CurrentUIDs sets.String
+	// InventoryUIDs are the UIDs of the objects when they were last
+	// applied, as stored in the inventory.
+	InventoryUIDs map[object.ObjMetadata]types.UID
 }
 
+var _ InventoryRemovalFilter = CurrentUIDFilter{}
+
 // Name returns a filter identifier for logging.
 func (cuf CurrentUIDFilter) Name() string {
 	return "CurrentUIDFilter"
 }
 
 // Filter returns true if the passed object should NOT be pruned (deleted)
-// because the it is a namespace that objects still reside in; otherwise
-// returns false. This filter should not be added to the list of filters
-// for "destroying", since every object is being deletet. Never returns an error.
+// because it has just been applied, or because its UID differs from
+// the UID stored in the inventory for the object; otherwise returns false.
+// Objects without a stored UID are not compared. When "destroying",
+// nothing has just been applied, so only the stored UIDs are compared.
 func (cuf CurrentUIDFilter) Filter(obj *unstructured.Unstructured) (bool, string, error) {
 	uid := string(obj.GetUID())
 	if cuf.CurrentUIDs.Has(uid) {
 		reason := fmt.Sprintf("object removal prevented; UID just applied: %s", uid)
 		return true, reason, nil
 	}
+	appliedUID, err := cuf.appliedUID(obj)
+	if err != nil {
+		return false, "", err
+	}
+	if appliedUID != "" && obj.GetUID() != appliedUID {
+		reason := fmt.Sprintf("object removal prevented; object recreated with UID %s (applied UID %s)", uid, appliedUID)
+		return true, reason, nil
+	}
 	return false, "", nil
 }
+
+// RemoveFromInventory returns true if the UID of the passed object
+// differs from the UID stored in the inventory, since the object in
+// the cluster is then not the one that was applied.
+func (cuf CurrentUIDFilter) RemoveFromInventory(obj *unstructured.Unstructured) bool {
+	appliedUID, err := cuf.appliedUID(obj)
+	if err != nil {
+		return false
+	}
+	return appliedUID != "" && obj.GetUID() != appliedUID && !cuf.CurrentUIDs.Has(string(obj.GetUID()))
+}
+
+// appliedUID returns the UID stored in the inventory for the passed
+// object, or an empty UID if none is stored.
+func (cuf CurrentUIDFilter) appliedUID(obj *unstructured.Unstructured) (types.UID, error) {
+	if len(cuf.InventoryUIDs) == 0 {
+		return "", nil
+	}
+	id, err := object.UnstructuredToObjMeta(obj)
+	if err != nil {
+		return "", err
+	}
+	return cuf.InventoryUIDs[id], nil
+}
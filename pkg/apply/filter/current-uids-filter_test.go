@@ -8,6 +8,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 func TestCurrentUIDFilter(t *testing.T) {
@@ -66,3 +67,70 @@ func TestCurrentUIDFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestCurrentUIDFilter_InventoryUIDs(t *testing.T) {
+	id := object.UnstructuredToObjMetaOrDie(defaultObj)
+	tests := map[string]struct {
+		currentUIDs   sets.String
+		inventoryUIDs map[object.ObjMetadata]types.UID
+		objUID        string
+		filtered      bool
+		removed       bool
+	}{
+		"Object not in inventory UIDs, object is not filtered": {
+			inventoryUIDs: map[object.ObjMetadata]types.UID{},
+			objUID:        "foo",
+			filtered:      false,
+		},
+		"No UID stored for object, object is not filtered": {
+			inventoryUIDs: map[object.ObjMetadata]types.UID{id: ""},
+			objUID:        "foo",
+			filtered:      false,
+		},
+		"Object UID same as inventory UID, object is not filtered": {
+			inventoryUIDs: map[object.ObjMetadata]types.UID{id: "foo"},
+			objUID:        "foo",
+			filtered:      false,
+		},
+		"Object UID differs from inventory UID, object is filtered and removed from inventory": {
+			inventoryUIDs: map[object.ObjMetadata]types.UID{id: "foo"},
+			objUID:        "bar",
+			filtered:      true,
+			removed:       true,
+		},
+		"Object UID just applied, object is filtered and kept in inventory": {
+			currentUIDs:   sets.NewString("bar"),
+			inventoryUIDs: map[object.ObjMetadata]types.UID{id: "foo"},
+			objUID:        "bar",
+			filtered:      true,
+			removed:       false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter := CurrentUIDFilter{
+				CurrentUIDs:   tc.currentUIDs,
+				InventoryUIDs: tc.inventoryUIDs,
+			}
+			obj := defaultObj.DeepCopy()
+			obj.SetUID(types.UID(tc.objUID))
+			actual, reason, err := filter.Filter(obj)
+			if err != nil {
+				t.Fatalf("CurrentUIDFilter unexpected error (%s)", err)
+			}
+			if tc.filtered != actual {
+				t.Errorf("CurrentUIDFilter expected filter (%t), got (%t)", tc.filtered, actual)
+			}
+			if tc.filtered && len(reason) == 0 {
+				t.Errorf("CurrentUIDFilter filtered; expected but missing Reason")
+			}
+			if !tc.filtered && len(reason) > 0 {
+				t.Errorf("CurrentUIDFilter not filtered; received unexpected Reason: %s", reason)
+			}
+			if removed := filter.RemoveFromInventory(obj); tc.removed != removed {
+				t.Errorf("CurrentUIDFilter expected removal from inventory (%t), got (%t)", tc.removed, removed)
+			}
+		})
+	}
+}
@@ -19,3 +19,14 @@ type ValidationFilter interface {
 	// during filtering it is returned.
 	Filter(obj *unstructured.Unstructured) (bool, string, error)
 }
+
+// InventoryRemovalFilter is implemented by the ValidationFilters that
+// filter objects which no longer belong to the inventory. Such objects
+// are removed from the inventory instead of being kept in it as prune
+// failures.
+type InventoryRemovalFilter interface {
+	ValidationFilter
+	// RemoveFromInventory returns true if the passed filtered object
+	// no longer belongs to the inventory.
+	RemoveFromInventory(obj *unstructured.Unstructured) bool
+}
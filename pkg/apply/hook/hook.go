@@ -0,0 +1,224 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hook contains the functionality for apply-time hooks.
+// Hooks are objects, usually Jobs, that are annotated with the
+// phase of the apply in which they should run. They are not applied
+// with the rest of the objects and are not added to the inventory.
+// Instead, each hook is created when its phase is reached and is
+// waited for until it has completed.
+package hook
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// Annotation marks an object as a hook. The value is a comma
+	// separated list of the phases in which the hook should run.
+	Annotation = "config.kubernetes.io/hook"
+	// DeletePolicyAnnotation defines when a hook is deleted. The
+	// value is a comma separated list of delete policies. If it is
+	// not set, the BeforeHookCreation policy is used.
+	DeletePolicyAnnotation = "config.kubernetes.io/hook-delete-policy"
+)
+
+// Phase is the phase of the apply in which a hook is run.
+type Phase string
+
+const (
+	// PreApply hooks run before any objects are applied.
+	PreApply Phase = "pre-apply"
+	// PostApply hooks run after all objects have been applied
+	// and waited for.
+	PostApply Phase = "post-apply"
+	// PrePrune hooks run before any objects are pruned. They only
+	// run if there are objects to prune.
+	PrePrune Phase = "pre-prune"
+)
+
+// Phases contains all the supported phases, in the order in
+// which they are run.
+var Phases = []Phase{PreApply, PostApply, PrePrune}
+
+// valid returns true if the phase is one of the supported Phases.
+func (p Phase) valid() bool {
+	for _, phase := range Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletePolicy defines when a hook object is deleted.
+type DeletePolicy string
+
+const (
+	// BeforeHookCreation deletes a previous instance of the hook
+	// before the hook is created.
+	BeforeHookCreation DeletePolicy = "before-hook-creation"
+	// HookSucceeded deletes the hook after it has completed successfully.
+	HookSucceeded DeletePolicy = "hook-succeeded"
+	// HookFailed deletes the hook after it has failed.
+	HookFailed DeletePolicy = "hook-failed"
+)
+
+// Hooks contains the hook objects for each phase.
+type Hooks map[Phase][]*unstructured.Unstructured
+
+// IsHook returns true if the passed object is annotated as a hook.
+func IsHook(u *unstructured.Unstructured) bool {
+	_, found := object.HasAnnotation(u, Annotation)
+	return found
+}
+
+// Split separates the hooks from the other objects. The hooks are
+// grouped by the phases in which they run, and a hook with multiple
+// phases is included in each of them. Returns an error if a hook
+// annotation can't be parsed.
+func Split(objs []*unstructured.Unstructured) (Hooks, []*unstructured.Unstructured, error) {
+	hooks := make(Hooks)
+	var others []*unstructured.Unstructured
+	for _, obj := range objs {
+		if !IsHook(obj) {
+			others = append(others, obj)
+			continue
+		}
+		phases, err := ObjPhases(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := ObjDeletePolicies(obj); err != nil {
+			return nil, nil, err
+		}
+		for _, phase := range phases {
+			hooks[phase] = append(hooks[phase], obj)
+		}
+	}
+	return hooks, others, nil
+}
+
+// ObjPhases returns the phases from the hook annotation
+// of the passed object.
+func ObjPhases(u *unstructured.Unstructured) ([]Phase, error) {
+	value, _ := object.HasAnnotation(u, Annotation)
+	var phases []Phase
+	for _, s := range splitValues(value) {
+		phase := Phase(s)
+		if !phase.valid() {
+			return nil, fmt.Errorf("unknown hook phase %q for %s/%s",
+				s, u.GetNamespace(), u.GetName())
+		}
+		phases = append(phases, phase)
+	}
+	if len(phases) == 0 {
+		return nil, fmt.Errorf("hook annotation for %s/%s has no phases",
+			u.GetNamespace(), u.GetName())
+	}
+	return phases, nil
+}
+
+// ObjDeletePolicies returns the delete policies from the hook delete
+// policy annotation of the passed object. If the object doesn't have
+// the annotation, the default BeforeHookCreation policy is returned.
+func ObjDeletePolicies(u *unstructured.Unstructured) ([]DeletePolicy, error) {
+	value, found := object.HasAnnotation(u, DeletePolicyAnnotation)
+	if !found {
+		return []DeletePolicy{BeforeHookCreation}, nil
+	}
+	var policies []DeletePolicy
+	for _, s := range splitValues(value) {
+		policy := DeletePolicy(s)
+		switch policy {
+		case BeforeHookCreation, HookSucceeded, HookFailed:
+			policies = append(policies, policy)
+		default:
+			return nil, fmt.Errorf("unknown hook delete policy %q for %s/%s",
+				s, u.GetNamespace(), u.GetName())
+		}
+	}
+	return policies, nil
+}
+
+// HasDeletePolicy returns true if the passed policy is
+// one of the delete policies of the object.
+func HasDeletePolicy(u *unstructured.Unstructured, policy DeletePolicy) bool {
+	policies, err := ObjDeletePolicies(u)
+	if err != nil {
+		return false
+	}
+	for _, p := range policies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// Completed checks whether the hook has run to completion. It returns
+// true and a nil error if the hook has succeeded, and true and an error
+// describing the failure if the hook has failed. Jobs are completed
+// when they have either the Complete or Failed condition, and Pods
+// when they have reached the Succeeded or Failed phase. Other objects
+// are completed once they are reconciled.
+func Completed(u *unstructured.Unstructured) (bool, error) {
+	switch u.GroupVersionKind().GroupKind().String() {
+	case "Job.batch":
+		objc, err := status.GetObjectWithConditions(u.Object)
+		if err != nil {
+			return false, err
+		}
+		for _, c := range objc.Status.Conditions {
+			if c.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case "Complete":
+				return true, nil
+			case "Failed":
+				return true, fmt.Errorf("job failed: %s", c.Message)
+			}
+		}
+		return false, nil
+	case "Pod":
+		phase := status.GetStringField(u.Object, ".status.phase", "")
+		switch corev1.PodPhase(phase) {
+		case corev1.PodSucceeded:
+			return true, nil
+		case corev1.PodFailed:
+			return true, fmt.Errorf("pod failed: %s",
+				status.GetStringField(u.Object, ".status.message", ""))
+		}
+		return false, nil
+	default:
+		res, err := status.Compute(u)
+		if err != nil {
+			return false, err
+		}
+		switch res.Status {
+		case status.CurrentStatus:
+			return true, nil
+		case status.FailedStatus:
+			return true, fmt.Errorf("hook failed: %s", res.Message)
+		}
+		return false, nil
+	}
+}
+
+func splitValues(value string) []string {
+	var values []string
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
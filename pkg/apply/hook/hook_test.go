@@ -0,0 +1,168 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObj(kind, name string, annotations map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+		},
+	}
+	if kind == "Pod" || kind == "ConfigMap" {
+		u.SetAPIVersion("v1")
+	}
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func TestSplit(t *testing.T) {
+	migrate := newObj("Job", "migrate", map[string]string{Annotation: "pre-apply"})
+	smoke := newObj("Job", "smoke", map[string]string{Annotation: "post-apply, pre-prune"})
+	cm := newObj("ConfigMap", "cm", nil)
+
+	hooks, others, err := Split([]*unstructured.Unstructured{migrate, cm, smoke})
+	assert.NoError(t, err)
+	assert.Equal(t, []*unstructured.Unstructured{cm}, others)
+	assert.Equal(t, Hooks{
+		PreApply:  {migrate},
+		PostApply: {smoke},
+		PrePrune:  {smoke},
+	}, hooks)
+}
+
+func TestSplit_Errors(t *testing.T) {
+	testCases := map[string]map[string]string{
+		"unknown phase": {
+			Annotation: "post-delete",
+		},
+		"no phases": {
+			Annotation: " , ",
+		},
+		"unknown delete policy": {
+			Annotation:             "pre-apply",
+			DeletePolicyAnnotation: "never",
+		},
+	}
+
+	for tn, annotations := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, _, err := Split([]*unstructured.Unstructured{
+				newObj("Job", "hook", annotations),
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHasDeletePolicy(t *testing.T) {
+	defaultPolicy := newObj("Job", "hook", map[string]string{Annotation: "pre-apply"})
+	assert.True(t, HasDeletePolicy(defaultPolicy, BeforeHookCreation))
+	assert.False(t, HasDeletePolicy(defaultPolicy, HookSucceeded))
+
+	explicit := newObj("Job", "hook", map[string]string{
+		Annotation:             "pre-apply",
+		DeletePolicyAnnotation: "hook-succeeded,hook-failed",
+	})
+	assert.False(t, HasDeletePolicy(explicit, BeforeHookCreation))
+	assert.True(t, HasDeletePolicy(explicit, HookSucceeded))
+	assert.True(t, HasDeletePolicy(explicit, HookFailed))
+}
+
+func TestCompleted(t *testing.T) {
+	testCases := map[string]struct {
+		kind          string
+		status        map[string]interface{}
+		expectedDone  bool
+		expectedError bool
+	}{
+		"job running": {
+			kind: "Job",
+			status: map[string]interface{}{
+				"active":    int64(1),
+				"startTime": "2021-01-01T00:00:00Z",
+			},
+			expectedDone: false,
+		},
+		"job complete": {
+			kind: "Job",
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   "Complete",
+						"status": "True",
+					},
+				},
+			},
+			expectedDone: true,
+		},
+		"job failed": {
+			kind: "Job",
+			status: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":    "Failed",
+						"status":  "True",
+						"message": "BackoffLimitExceeded",
+					},
+				},
+			},
+			expectedDone:  true,
+			expectedError: true,
+		},
+		"pod running": {
+			kind: "Pod",
+			status: map[string]interface{}{
+				"phase": "Running",
+			},
+			expectedDone: false,
+		},
+		"pod succeeded": {
+			kind: "Pod",
+			status: map[string]interface{}{
+				"phase": "Succeeded",
+			},
+			expectedDone: true,
+		},
+		"pod failed": {
+			kind: "Pod",
+			status: map[string]interface{}{
+				"phase": "Failed",
+			},
+			expectedDone:  true,
+			expectedError: true,
+		},
+		"other object is current": {
+			kind:         "ConfigMap",
+			expectedDone: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			u := newObj(tc.kind, "hook", nil)
+			if tc.status != nil {
+				u.Object["status"] = tc.status
+			}
+			done, err := Completed(u)
+			assert.Equal(t, tc.expectedDone, done)
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
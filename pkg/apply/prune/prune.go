@@ -78,7 +78,8 @@ type Options struct {
 // of a prune filter is PreventDeleteFilter, which checks if an
 // annotation exists on the object to ensure the objects is not
 // deleted (e.g. a PersistentVolume that we do no want to
-// automatically prune/delete).
+// automatically prune/delete). If the context in the taskContext is
+// cancelled, the objects that have not yet been pruned are skipped.
 //
 // Parameters:
 //   pruneObjs - objects to prune (delete)
@@ -92,7 +93,17 @@ func (po *PruneOptions) Prune(pruneObjs []*unstructured.Unstructured,
 	eventFactory := CreateEventFactory(o.Destroy)
 	// Iterate through objects to prune (delete). If an object is not pruned
 	// and we need to keep it in the inventory, we must capture the prune failure.
-	for _, pruneObj := range pruneObjs {
+	for i, pruneObj := range pruneObjs {
+		// Stop pruning if the context has been cancelled. The remaining
+		// objects are captured as prune failures so they are kept in
+		// the inventory.
+		if taskContext.Cancelled() {
+			klog.V(4).Infof("prune cancelled (%d objects not pruned)", len(pruneObjs)-i)
+			for _, obj := range pruneObjs[i:] {
+				taskContext.CapturePruneFailure(object.UnstructuredToObjMetaOrDie(obj))
+			}
+			break
+		}
 		pruneID := object.UnstructuredToObjMetaOrDie(pruneObj)
 		klog.V(5).Infof("attempting prune: %s", pruneID)
 		// Check filters to see if we're prevented from pruning/deleting object.
@@ -113,6 +124,12 @@ func (po *PruneOptions) Prune(pruneObjs []*unstructured.Unstructured,
 			if filtered {
 				klog.V(4).Infof("prune filtered by %s: %s", filter.Name(), pruneID)
 				taskContext.EventChannel() <- eventFactory.CreateSkippedEvent(pruneObj, reason)
+				// An object that no longer belongs to the inventory is
+				// dropped from it, instead of being kept as a prune failure.
+				if removeFromInventory(filter, pruneObj) {
+					klog.V(4).Infof("prune removed from inventory: %s", pruneID)
+					break
+				}
 				taskContext.CapturePruneFailure(pruneID)
 				break
 			}
@@ -149,6 +166,13 @@ func (po *PruneOptions) Prune(pruneObjs []*unstructured.Unstructured,
 	return nil
 }
 
+// removeFromInventory returns true if the passed filter filtered the
+// passed object because it no longer belongs to the inventory.
+func removeFromInventory(f filter.ValidationFilter, obj *unstructured.Unstructured) bool {
+	rf, ok := f.(filter.InventoryRemovalFilter)
+	return ok && rf.RemoveFromInventory(obj)
+}
+
 // GetPruneObjs calculates the set of prune objects, and retrieves them
 // from the cluster. Set of prune objects equals the set of inventory
 // objects minus the set of currently applied objects. Returns an error
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
@@ -395,7 +396,7 @@ func TestPrune(t *testing.T) {
 			// The event channel can not block; make sure its bigger than all
 			// the events that can be put on it.
 			eventChannel := make(chan event.Event, len(tc.pruneObjs)+1)
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 			err = func() error {
 				defer close(eventChannel)
 				// Run the prune and validate.
@@ -485,7 +486,7 @@ func TestPruneWithErrors(t *testing.T) {
 			// The event channel can not block; make sure its bigger than all
 			// the events that can be put on it.
 			eventChannel := make(chan event.Event, len(tc.pruneObjs))
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 			err = func() error {
 				defer close(eventChannel)
 				var opts Options
@@ -510,6 +511,89 @@ func TestPruneWithErrors(t *testing.T) {
 	}
 }
 
+func TestPruneCancelled(t *testing.T) {
+	pruneObjs := []*unstructured.Unstructured{pod, pdb}
+	pruneIds := object.UnstructuredsToObjMetasOrDie(pruneObjs)
+	po := PruneOptions{
+		InvClient: inventory.NewFakeInventoryClient(pruneIds),
+		Client:    fake.NewSimpleDynamicClient(scheme.Scheme, pod, pdb),
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+	eventChannel := make(chan event.Event, len(pruneObjs))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	taskContext := taskrunner.NewTaskContext(ctx, eventChannel)
+	err := po.Prune(pruneObjs, []filter.ValidationFilter{}, taskContext, defaultOptions)
+	close(eventChannel)
+	require.NoError(t, err)
+
+	// No objects are deleted, so no events are sent, and all the
+	// objects are kept in the inventory as prune failures.
+	assert.Empty(t, eventChannel)
+	actual := taskContext.PruneFailures()
+	if !object.SetEquals(pruneIds, actual) {
+		t.Errorf("expected (%s) prune failures, got (%s)", pruneIds, actual)
+	}
+}
+
+func TestPruneRecreated(t *testing.T) {
+	pruneObjs := []*unstructured.Unstructured{pod, pdb}
+	pruneIds := object.UnstructuredsToObjMetasOrDie(pruneObjs)
+	po := PruneOptions{
+		InvClient: inventory.NewFakeInventoryClient(pruneIds),
+		Client:    fake.NewSimpleDynamicClient(scheme.Scheme, pod, pdb),
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+	// The pod has been recreated with another UID than the applied one,
+	// and the pdb is protected from deletion.
+	pruneFilters := []filter.ValidationFilter{
+		filter.PreventRemoveFilter{},
+		filter.CurrentUIDFilter{
+			InventoryUIDs: map[object.ObjMetadata]types.UID{
+				object.UnstructuredToObjMetaOrDie(pod): "applied-pod-uid",
+			},
+		},
+	}
+	protectedPDB := pdb.DeepCopy()
+	protectedPDB.SetAnnotations(map[string]string{
+		common.OnRemoveAnnotation: common.OnRemoveKeep,
+	})
+	eventChannel := make(chan event.Event, len(pruneObjs))
+	taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
+	err := po.Prune([]*unstructured.Unstructured{pod, protectedPDB}, pruneFilters, taskContext, defaultOptions)
+	close(eventChannel)
+	require.NoError(t, err)
+
+	var actualEvents []event.Event
+	for e := range eventChannel {
+		actualEvents = append(actualEvents, e)
+	}
+	err = testutil.VerifyEvents([]testutil.ExpEvent{
+		{
+			EventType: event.PruneType,
+			PruneEvent: &testutil.ExpPruneEvent{
+				Operation: event.PruneSkipped,
+			},
+		},
+		{
+			EventType: event.PruneType,
+			PruneEvent: &testutil.ExpPruneEvent{
+				Operation: event.PruneSkipped,
+			},
+		},
+	}, actualEvents)
+	assert.NoError(t, err)
+	// The recreated pod is dropped from the inventory, while the
+	// protected pdb is kept in it as a prune failure.
+	expected := []object.ObjMetadata{object.UnstructuredToObjMetaOrDie(pdb)}
+	actual := taskContext.PruneFailures()
+	if !object.SetEquals(expected, actual) {
+		t.Errorf("expected (%s) prune failures, got (%s)", expected, actual)
+	}
+}
+
 func TestGetPruneObjs(t *testing.T) {
 	tests := map[string]struct {
 		localObjs     []*unstructured.Unstructured
@@ -621,7 +705,7 @@ func TestPrune_PropagationPolicy(t *testing.T) {
 			}
 
 			eventChannel := make(chan event.Event, 1)
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 			err := po.Prune([]*unstructured.Unstructured{pdb}, []filter.ValidationFilter{}, taskContext, Options{
 				PropagationPolicy: tc.propagationPolicy,
 			})
@@ -21,10 +21,12 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
@@ -53,6 +55,8 @@ type TaskQueueBuilder struct {
 	applyCounter     int
 	waitCounter      int
 	pruneCounter     int
+	rollbackCounter  int
+	hookCounter      int
 	tasks            []taskrunner.Task
 	err              error
 }
@@ -90,6 +94,14 @@ type Options struct {
 	PrunePropagationPolicy metav1.DeletionPropagation
 	PruneTimeout           time.Duration
 	InventoryPolicy        inventory.InventoryPolicy
+	ApplyConcurrency       int
+	RollbackOnFailure      bool
+	HookTimeout            time.Duration
+	PollInterval           time.Duration
+	// InventoryUIDs are the UIDs of the objects when they were last
+	// applied, as stored in the inventory. Pruning skips the objects
+	// that have been recreated with another UID.
+	InventoryUIDs map[object.ObjMetadata]types.UID
 }
 
 // Build returns the queue of tasks that have been created.
@@ -123,6 +135,18 @@ func (t *TaskQueueBuilder) AppendInvAddTask(inv inventory.InventoryInfo, applyOb
 // AppendInvAddTask appends an inventory set task to the task queue.
 // Returns a pointer to the Builder to chain function calls.
 func (t *TaskQueueBuilder) AppendInvSetTask(inv inventory.InventoryInfo, dryRun common.DryRunStrategy) *TaskQueueBuilder {
+	return t.appendInvSetTask(inv, dryRun, false)
+}
+
+// AppendRollbackInvSetTask appends an inventory set task to the task
+// queue, that restores the previous inventory after a rollback.
+// Returns a pointer to the Builder to chain function calls.
+func (t *TaskQueueBuilder) AppendRollbackInvSetTask(inv inventory.InventoryInfo, dryRun common.DryRunStrategy) *TaskQueueBuilder {
+	return t.appendInvSetTask(inv, dryRun, true)
+}
+
+func (t *TaskQueueBuilder) appendInvSetTask(inv inventory.InventoryInfo, dryRun common.DryRunStrategy,
+	rollback bool) *TaskQueueBuilder {
 	klog.V(2).Infoln("adding inventory set task")
 	prevInvIds, _ := t.InvClient.GetClusterObjs(inv, dryRun)
 	prevInventory := make(map[object.ObjMetadata]bool, len(prevInvIds))
@@ -135,6 +159,7 @@ func (t *TaskQueueBuilder) AppendInvSetTask(inv inventory.InventoryInfo, dryRun
 		InvInfo:       inv,
 		PrevInventory: prevInventory,
 		DryRun:        dryRun,
+		Rollback:      rollback,
 	})
 	t.invSetCounter += 1
 	return t
@@ -169,6 +194,8 @@ func (t *TaskQueueBuilder) AppendApplyTask(inv inventory.InventoryInfo,
 		Mapper:            t.Mapper,
 		InventoryPolicy:   o.InventoryPolicy,
 		InvInfo:           inv,
+		Concurrency:       o.ApplyConcurrency,
+		RollbackOnFailure: o.RollbackOnFailure,
 	})
 	t.applyCounter += 1
 	return t
@@ -178,14 +205,38 @@ func (t *TaskQueueBuilder) AppendApplyTask(inv inventory.InventoryInfo,
 // Returns a pointer to the Builder to chain function calls.
 func (t *TaskQueueBuilder) AppendWaitTask(waitIds []object.ObjMetadata, condition taskrunner.Condition,
 	waitTimeout time.Duration) *TaskQueueBuilder {
+	return t.appendWaitTask(waitIds, condition, waitTimeout, nil)
+}
+
+// AppendObjsWaitTask appends a task to wait on the passed objects to the
+// task queue. With the AllCurrent condition, objects with the wait-for
+// annotation must meet the condition given by the annotation instead.
+// Returns a pointer to the Builder to chain function calls.
+func (t *TaskQueueBuilder) AppendObjsWaitTask(waitObjs []*unstructured.Unstructured, condition taskrunner.Condition,
+	waitTimeout time.Duration) *TaskQueueBuilder {
+	var conditions map[object.ObjMetadata]taskrunner.ResourceCondition
+	if condition == taskrunner.AllCurrent {
+		var err error
+		conditions, err = resourceConditions(waitObjs)
+		if err != nil {
+			t.err = err
+		}
+	}
+	waitIds := object.UnstructuredsToObjMetasOrDie(waitObjs)
+	return t.appendWaitTask(waitIds, condition, waitTimeout, conditions)
+}
+
+func (t *TaskQueueBuilder) appendWaitTask(waitIds []object.ObjMetadata, condition taskrunner.Condition,
+	waitTimeout time.Duration, resourceConditions map[object.ObjMetadata]taskrunner.ResourceCondition) *TaskQueueBuilder {
 	klog.V(2).Infoln("adding wait task")
-	t.tasks = append(t.tasks, taskrunner.NewWaitTask(
+	waitTask := taskrunner.NewWaitTask(
 		fmt.Sprintf("wait-%d", t.waitCounter),
 		waitIds,
 		condition,
 		waitTimeout,
-		t.Mapper),
-	)
+		t.Mapper)
+	waitTask.ResourceConditions = resourceConditions
+	t.tasks = append(t.tasks, waitTask)
 	t.waitCounter += 1
 	return t
 }
@@ -204,12 +255,52 @@ func (t *TaskQueueBuilder) AppendPruneTask(pruneObjs []*unstructured.Unstructure
 			PropagationPolicy: o.PrunePropagationPolicy,
 			DryRunStrategy:    o.DryRunStrategy,
 			Destroy:           t.Destroy,
+			InventoryUIDs:     o.InventoryUIDs,
 		},
 	)
 	t.pruneCounter += 1
 	return t
 }
 
+// AppendRollbackTask appends a task to the task queue to roll back the
+// objects that have been applied. Returns a pointer to the Builder to
+// chain function calls.
+func (t *TaskQueueBuilder) AppendRollbackTask(o Options) *TaskQueueBuilder {
+	klog.V(2).Infoln("adding rollback task")
+	t.tasks = append(t.tasks, &task.RollbackTask{
+		TaskName:          fmt.Sprintf("rollback-%d", t.rollbackCounter),
+		Client:            t.PruneOptions.Client,
+		Mapper:            t.Mapper,
+		PropagationPolicy: o.PrunePropagationPolicy,
+	})
+	t.rollbackCounter += 1
+	return t
+}
+
+// AppendHookTask appends a task to run the hooks for the given phase to
+// the task queue. No task is added if there are no hooks. Returns a
+// pointer to the Builder to chain function calls.
+func (t *TaskQueueBuilder) AppendHookTask(phase hook.Phase, hooks []*unstructured.Unstructured,
+	o Options) *TaskQueueBuilder {
+	if len(hooks) == 0 {
+		return t
+	}
+	klog.V(2).Infof("adding %s hook task (%d objects)", phase, len(hooks))
+	t.tasks = append(t.tasks, &task.HookTask{
+		TaskName:          fmt.Sprintf("hook-%d", t.hookCounter),
+		Phase:             phase,
+		Objects:           hooks,
+		Client:            t.PruneOptions.Client,
+		Mapper:            t.Mapper,
+		DryRunStrategy:    o.DryRunStrategy,
+		PropagationPolicy: o.PrunePropagationPolicy,
+		Timeout:           o.HookTimeout,
+		PollInterval:      o.PollInterval,
+	})
+	t.hookCounter += 1
+	return t
+}
+
 // AppendApplyWaitTasks adds apply and wait tasks to the task queue,
 // depending on build variables (like dry-run) and resource types
 // (like CRD's). Returns a pointer to the Builder to chain function calls.
@@ -227,12 +318,37 @@ func (t *TaskQueueBuilder) AppendApplyWaitTasks(inv inventory.InventoryInfo,
 		t.AppendApplyTask(inv, applySet, o)
 		if addWaitTask {
 			applyIds := object.UnstructuredsToObjMetasOrDie(applySet)
-			t.AppendWaitTask(applyIds, taskrunner.AllCurrent, waitTimeout)
+			resourceConditions, err := resourceConditions(applySet)
+			if err != nil {
+				t.err = err
+			}
+			t.appendWaitTask(applyIds, taskrunner.AllCurrent, waitTimeout, resourceConditions)
 		}
 	}
 	return t
 }
 
+// resourceConditions returns the custom wait conditions given by the
+// wait-for annotation for the passed objects, keyed by the object
+// identifier. Objects without the annotation are not included.
+func resourceConditions(objs []*unstructured.Unstructured) (map[object.ObjMetadata]taskrunner.ResourceCondition, error) {
+	var conditions map[object.ObjMetadata]taskrunner.ResourceCondition
+	for _, obj := range objs {
+		cond, err := taskrunner.ResourceConditionFromObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		if cond == nil {
+			continue
+		}
+		if conditions == nil {
+			conditions = make(map[object.ObjMetadata]taskrunner.ResourceCondition)
+		}
+		conditions[object.UnstructuredToObjMetaOrDie(obj)] = cond
+	}
+	return conditions, nil
+}
+
 // AppendPruneWaitTasks adds prune and wait tasks to the task queue
 // based on build variables (like dry-run). Returns a pointer to the
 // Builder to chain function calls.
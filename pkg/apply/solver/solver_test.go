@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
@@ -360,6 +361,49 @@ func TestTaskQueueBuilder_AppendApplyWaitTasks(t *testing.T) {
 			expectedTasks: []taskrunner.Task{},
 			isError:       true,
 		},
+		"wait-for annotation sets resource conditions": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddWaitFor(t, "condition=Available")),
+				testutil.Unstructured(t, resources["secret"],
+					testutil.AddWaitFor(t, "none")),
+				testutil.Unstructured(t, resources["pod"]),
+			},
+			options: Options{ReconcileTimeout: time.Minute},
+			expectedTasks: []taskrunner.Task{
+				&task.ApplyTask{
+					TaskName: "apply-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["deployment"]),
+						testutil.Unstructured(t, resources["secret"]),
+						testutil.Unstructured(t, resources["pod"]),
+					},
+				},
+				withResourceConditions(taskrunner.NewWaitTask(
+					"wait-0",
+					[]object.ObjMetadata{
+						testutil.ToIdentifier(t, resources["deployment"]),
+						testutil.ToIdentifier(t, resources["secret"]),
+						testutil.ToIdentifier(t, resources["pod"]),
+					},
+					taskrunner.AllCurrent, 1*time.Minute,
+					testutil.NewFakeRESTMapper()),
+					map[object.ObjMetadata]taskrunner.ResourceCondition{
+						testutil.ToIdentifier(t, resources["deployment"]): mustParseResourceCondition(t, "condition=Available"),
+						testutil.ToIdentifier(t, resources["secret"]):     taskrunner.NoWait,
+					}),
+			},
+			isError: false,
+		},
+		"invalid wait-for annotation returns error": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddWaitFor(t, "eventually")),
+			},
+			options:       Options{ReconcileTimeout: time.Minute},
+			expectedTasks: []taskrunner.Task{},
+			isError:       true,
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -398,12 +442,33 @@ func TestTaskQueueBuilder_AppendApplyWaitTasks(t *testing.T) {
 							expTsk.Ids, actWaitTask.Ids)
 					}
 					assert.Equal(t, taskrunner.AllCurrent, actWaitTask.Condition)
+					assert.Equal(t, len(expTsk.ResourceConditions), len(actWaitTask.ResourceConditions))
+					for id, expCond := range expTsk.ResourceConditions {
+						actCond, found := actWaitTask.ResourceConditions[id]
+						if assert.True(t, found, "missing resource condition for %s", id) {
+							assert.Equal(t, expCond.String(), actCond.String())
+						}
+					}
 				}
 			}
 		})
 	}
 }
 
+func withResourceConditions(w *taskrunner.WaitTask,
+	conditions map[object.ObjMetadata]taskrunner.ResourceCondition) *taskrunner.WaitTask {
+	w.ResourceConditions = conditions
+	return w
+}
+
+func mustParseResourceCondition(t *testing.T, value string) taskrunner.ResourceCondition {
+	cond, err := taskrunner.ParseResourceCondition(value)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return cond
+}
+
 func TestTaskQueueBuilder_AppendPruneWaitTasks(t *testing.T) {
 	testCases := map[string]struct {
 		pruneObjs     []*unstructured.Unstructured
@@ -672,6 +737,83 @@ func TestTaskQueueBuilder_AppendPruneWaitTasks(t *testing.T) {
 
 // verifyObjSets ensures the slice of expected objects is the same as
 // the actual slice of objects. Order is NOT important.
+func TestTaskQueueBuilder_AppendHookTask(t *testing.T) {
+	job := testutil.Unstructured(t, `
+kind: Job
+apiVersion: batch/v1
+metadata:
+  name: migrate
+  namespace: test-namespace
+`)
+	tqb := TaskQueueBuilder{
+		PruneOptions: pruneOptions,
+		Mapper:       testutil.NewFakeRESTMapper(),
+	}
+	tq, err := tqb.
+		AppendHookTask(hook.PreApply, []*unstructured.Unstructured{job}, Options{
+			HookTimeout: time.Minute,
+		}).
+		AppendHookTask(hook.PostApply, nil, Options{}).
+		Build()
+	assert.NoError(t, err)
+	if !assert.Equal(t, 1, len(tq.tasks)) {
+		t.FailNow()
+	}
+	hookTask, ok := tq.tasks[0].(*task.HookTask)
+	if !assert.True(t, ok, "expected HookTask, got %T", tq.tasks[0]) {
+		t.FailNow()
+	}
+	assert.Equal(t, "hook-0", hookTask.Name())
+	assert.Equal(t, hook.PreApply, hookTask.Phase)
+	assert.Equal(t, time.Minute, hookTask.Timeout)
+	assert.Equal(t, []*unstructured.Unstructured{job}, hookTask.Objects)
+}
+
+func TestTaskQueueBuilder_AppendObjsWaitTask(t *testing.T) {
+	pod := testutil.Unstructured(t, resources["pod"])
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	deployment.SetAnnotations(map[string]string{
+		object.WaitForAnnotation: "condition=Available",
+	})
+	objs := []*unstructured.Unstructured{pod, deployment}
+
+	testCases := map[string]struct {
+		condition          taskrunner.Condition
+		expectedConditions []string
+	}{
+		"wait-for annotation is used with AllCurrent": {
+			condition:          taskrunner.AllCurrent,
+			expectedConditions: []string{"condition=Available"},
+		},
+		"wait-for annotation is ignored with AllNotFound": {
+			condition: taskrunner.AllNotFound,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tqb := TaskQueueBuilder{
+				Mapper: testutil.NewFakeRESTMapper(),
+			}
+			tq, err := tqb.AppendObjsWaitTask(objs, tc.condition, time.Minute).Build()
+			assert.NoError(t, err)
+			if !assert.Equal(t, 1, len(tq.tasks)) {
+				t.FailNow()
+			}
+			waitTask := toWaitTask(t, tq.tasks[0])
+			assert.Equal(t, "wait-0", waitTask.Name())
+			assert.Equal(t, tc.condition, waitTask.Condition)
+			assert.Equal(t, time.Minute, waitTask.Timeout)
+			assert.Equal(t, object.UnstructuredsToObjMetasOrDie(objs), waitTask.Ids)
+			var conditions []string
+			for _, cond := range waitTask.ResourceConditions {
+				conditions = append(conditions, cond.String())
+			}
+			assert.Equal(t, tc.expectedConditions, conditions)
+		})
+	}
+}
+
 func verifyObjSets(t *testing.T, expected []*unstructured.Unstructured, actual []*unstructured.Unstructured) {
 	if len(expected) != len(actual) {
 		t.Fatalf("expected set size (%d), got (%d)", len(expected), len(actual))
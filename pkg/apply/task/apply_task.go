@@ -6,7 +6,7 @@ package task
 import (
 	"context"
 	"io/ioutil"
-	"strings"
+	"sync"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -55,6 +55,14 @@ type ApplyTask struct {
 	ServerSideOptions common.ServerSideOptions
 	InventoryPolicy   inventory.InventoryPolicy
 	InvInfo           inventory.InventoryInfo
+	// Concurrency is the maximum number of objects that will be
+	// applied in parallel. Values less than one means the objects
+	// are applied one at a time.
+	Concurrency int
+	// RollbackOnFailure defines whether the state of each object in
+	// the cluster should be captured before it is applied, and whether
+	// the task should fail if any of the objects could not be applied.
+	RollbackOnFailure bool
 }
 
 // applyOptionsFactoryFunc is a factory function for creating a new
@@ -83,92 +91,168 @@ func (a *ApplyTask) Identifiers() []object.ObjMetadata {
 // It will also fetch the Generation from each of the applied resources
 // after the Run function has completed. This information is then added
 // to the taskContext. The generation is increased every time
-// the desired state of a resource is changed.
+// the desired state of a resource is changed. If the context in the
+// taskContext is cancelled, the objects that have not yet been applied
+// are skipped. If Concurrency is larger than one, the objects are
+// applied in parallel by a pool of that many workers.
 func (a *ApplyTask) Start(taskContext *taskrunner.TaskContext) {
 	go func() {
 		objects := a.Objects
 		klog.V(2).Infof("apply task starting (%d objects)", len(objects))
-		// Create a new instance of the applyOptions interface and use it
-		// to apply the objects.
-		ao, dynamic, err := applyOptionsFactoryFunc(taskContext.EventChannel(),
-			a.ServerSideOptions, a.DryRunStrategy, a.Factory)
-		if err != nil {
-			if klog.V(4).Enabled() {
-				klog.Errorf("error creating ApplyOptions (%s)--returning", err)
-			}
-			sendBatchApplyEvents(taskContext, objects, err)
-			a.sendTaskResult(taskContext)
-			return
-		}
-		for _, obj := range objects {
-			// Set the client and mapping fields on the provided
-			// info so they can be applied to the cluster.
-			info, err := a.InfoHelper.BuildInfo(obj)
-			id := object.UnstructuredToObjMetaOrDie(obj)
+		// Create a new instance of the applyOptions interface for each
+		// worker, since an applyOptions can only apply one set of objects
+		// at a time.
+		workers := a.workers()
+		aos := make([]applyOptions, 0, workers)
+		var dynamic dynamic.Interface
+		for w := 0; w < workers; w++ {
+			ao, d, err := applyOptionsFactoryFunc(taskContext.EventChannel(),
+				a.ServerSideOptions, a.DryRunStrategy, a.Factory)
 			if err != nil {
 				if klog.V(4).Enabled() {
-					klog.Errorf("unable to convert obj to info for %s/%s (%s)--continue",
-						obj.GetNamespace(), obj.GetName(), err)
+					klog.Errorf("error creating ApplyOptions (%s)--returning", err)
 				}
-				taskContext.EventChannel() <- createApplyFailedEvent(id,
-					applyerror.NewUnknownTypeError(err))
-				taskContext.CaptureResourceFailure(id)
-				continue
+				sendBatchApplyEvents(taskContext, objects, err)
+				a.sendTaskResult(taskContext)
+				return
 			}
-			clusterObj, err := getClusterObj(dynamic, info)
-			if err != nil {
-				if !apierrors.IsNotFound(err) {
-					if klog.V(4).Enabled() {
-						klog.Errorf("error (%s) retrieving %s/%s from cluster--continue",
-							err, info.Namespace, info.Name)
+			aos = append(aos, ao)
+			dynamic = d
+		}
+
+		objCh := make(chan *unstructured.Unstructured)
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var failures []object.ObjMetadata
+		for _, ao := range aos {
+			wg.Add(1)
+			go func(ao applyOptions) {
+				defer wg.Done()
+				for obj := range objCh {
+					if !a.applyObject(taskContext, ao, dynamic, obj) {
+						mu.Lock()
+						failures = append(failures, object.UnstructuredToObjMetaOrDie(obj))
+						mu.Unlock()
 					}
-					taskContext.EventChannel() <- createApplyFailedEvent(id, err)
-					taskContext.CaptureResourceFailure(id)
-					continue
 				}
-			}
-			canApply, err := inventory.CanApply(a.InvInfo, clusterObj, a.InventoryPolicy)
-			if !canApply {
-				klog.V(5).Infof("can not apply %s/%s--continue",
-					clusterObj.GetNamespace(), clusterObj.GetName())
-				if err != nil {
-					taskContext.EventChannel() <- createApplyFailedEvent(id, err)
-				} else {
-					taskContext.EventChannel() <- createApplyEvent(id,
-						event.Unchanged, clusterObj)
+			}(ao)
+		}
+		for i, obj := range objects {
+			// Stop applying objects if the context has been cancelled.
+			// The remaining objects are captured as failures, so the
+			// ones that were previously in the inventory are kept there.
+			if taskContext.Cancelled() {
+				klog.V(4).Infof("apply task cancelled (%d objects not applied)", len(objects)-i)
+				for _, obj := range objects[i:] {
+					taskContext.CaptureResourceFailure(object.UnstructuredToObjMetaOrDie(obj))
 				}
-				taskContext.CaptureResourceFailure(id)
-				continue
-			}
-			ao.SetObjects([]*resource.Info{info})
-			klog.V(5).Infof("applying %s/%s...", info.Namespace, info.Name)
-			err = ao.Run()
-			if err != nil && a.ServerSideOptions.ServerSideApply && isAPIService(obj) && isStreamError(err) {
-				// Server-side Apply doesn't work with APIService before k8s 1.21
-				// https://github.com/kubernetes/kubernetes/issues/89264
-				// Thus APIService is handled specially using client-side apply.
-				err = clientSideApply(info, taskContext.EventChannel(), a.DryRunStrategy, a.Factory)
+				break
 			}
-			if err != nil {
-				if klog.V(4).Enabled() {
-					klog.Errorf("error applying (%s/%s) %s", info.Namespace, info.Name, err)
-				}
-				taskContext.EventChannel() <- createApplyFailedEvent(id,
-					applyerror.NewApplyRunError(err))
-				taskContext.CaptureResourceFailure(id)
-			} else if info.Object != nil {
-				acc, err := meta.Accessor(info.Object)
-				if err == nil {
-					uid := acc.GetUID()
-					gen := acc.GetGeneration()
-					taskContext.ResourceApplied(id, uid, gen)
-				}
+			objCh <- obj
+		}
+		close(objCh)
+		wg.Wait()
+		if a.RollbackOnFailure && len(failures) > 0 {
+			taskContext.TaskChannel() <- taskrunner.TaskResult{
+				Err: applyerror.NewApplyFailedError(failures),
 			}
+			return
 		}
 		a.sendTaskResult(taskContext)
 	}()
 }
 
+// workers returns the number of workers that should be used to
+// apply the objects. It is never less than one, and never more
+// than the number of objects.
+func (a *ApplyTask) workers() int {
+	workers := a.Concurrency
+	if workers > len(a.Objects) {
+		workers = len(a.Objects)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// applyObject applies a single object to the cluster using the
+// provided applyOptions, and records the result in the taskContext.
+// Returns false if the object could not be applied.
+func (a *ApplyTask) applyObject(taskContext *taskrunner.TaskContext, ao applyOptions,
+	dynamic dynamic.Interface, obj *unstructured.Unstructured) bool {
+	// Set the client and mapping fields on the provided
+	// info so they can be applied to the cluster.
+	info, err := a.InfoHelper.BuildInfo(obj)
+	id := object.UnstructuredToObjMetaOrDie(obj)
+	if err != nil {
+		if klog.V(4).Enabled() {
+			klog.Errorf("unable to convert obj to info for %s/%s (%s)--continue",
+				obj.GetNamespace(), obj.GetName(), err)
+		}
+		taskContext.EventChannel() <- createApplyFailedEvent(id,
+			applyerror.NewUnknownTypeError(err))
+		taskContext.CaptureResourceFailure(id)
+		return false
+	}
+	clusterObj, err := getClusterObj(dynamic, info)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			if klog.V(4).Enabled() {
+				klog.Errorf("error (%s) retrieving %s/%s from cluster--continue",
+					err, info.Namespace, info.Name)
+			}
+			taskContext.EventChannel() <- createApplyFailedEvent(id, err)
+			taskContext.CaptureResourceFailure(id)
+			return false
+		}
+	}
+	canApply, err := inventory.CanApply(a.InvInfo, clusterObj, a.InventoryPolicy)
+	if !canApply {
+		klog.V(5).Infof("can not apply %s/%s--continue",
+			clusterObj.GetNamespace(), clusterObj.GetName())
+		if err != nil {
+			taskContext.EventChannel() <- createApplyFailedEvent(id, err)
+		} else {
+			taskContext.EventChannel() <- createApplyEvent(id,
+				event.Unchanged, clusterObj)
+		}
+		taskContext.CaptureResourceFailure(id)
+		// An object that is skipped because of the inventory policy
+		// is not considered a failure.
+		return err == nil
+	}
+	if a.RollbackOnFailure {
+		taskContext.CapturePreviousObject(id, clusterObj)
+	}
+	klog.V(5).Infof("applying %s/%s...", info.Namespace, info.Name)
+	if a.ServerSideOptions.ServerSideApply {
+		err = a.serverSideApply(taskContext, dynamic, info, clusterObj)
+	} else {
+		ao.SetObjects([]*resource.Info{info})
+		err = ao.Run()
+	}
+	if err != nil {
+		if klog.V(4).Enabled() {
+			klog.Errorf("error applying (%s/%s) %s", info.Namespace, info.Name, err)
+		}
+		e := createApplyFailedEvent(id, applyerror.NewApplyRunError(err))
+		e.ApplyEvent.Conflicts = FieldConflictsFromError(err)
+		taskContext.EventChannel() <- e
+		taskContext.CaptureResourceFailure(id)
+		return false
+	}
+	if info.Object != nil {
+		acc, err := meta.Accessor(info.Object)
+		if err == nil {
+			uid := acc.GetUID()
+			gen := acc.GetGeneration()
+			taskContext.ResourceApplied(id, uid, gen)
+		}
+	}
+	return true
+}
+
 func newApplyOptions(eventChannel chan event.Event, serverSideOptions common.ServerSideOptions,
 	strategy common.DryRunStrategy, factory util.Factory) (applyOptions, dynamic.Interface, error) {
 	discovery, err := factory.ToDiscoveryClient()
@@ -256,23 +340,3 @@ func sendBatchApplyEvents(taskContext *taskrunner.TaskContext, objects []*unstru
 		taskContext.CaptureResourceFailure(id)
 	}
 }
-
-func isAPIService(obj *unstructured.Unstructured) bool {
-	gk := obj.GroupVersionKind().GroupKind()
-	return gk.Group == "apiregistration.k8s.io" && gk.Kind == "APIService"
-}
-
-// isStreamError checks if the error is a StreamError. Since kubectl wraps the actual StreamError,
-// we can't check the error type.
-func isStreamError(err error) bool {
-	return strings.Contains(err.Error(), "stream error: stream ID ")
-}
-
-func clientSideApply(info *resource.Info, eventChannel chan event.Event, strategy common.DryRunStrategy, factory util.Factory) error {
-	ao, _, err := applyOptionsFactoryFunc(eventChannel, common.ServerSideOptions{ServerSideApply: false}, strategy, factory)
-	if err != nil {
-		return err
-	}
-	ao.SetObjects([]*resource.Info{info})
-	return ao.Run()
-}
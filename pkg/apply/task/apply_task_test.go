@@ -4,6 +4,7 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -81,7 +82,7 @@ func TestApplyTask_BasicAppliedObjects(t *testing.T) {
 		t.Run(tn, func(t *testing.T) {
 			eventChannel := make(chan event.Event)
 			defer close(eventChannel)
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 
 			objs := toUnstructureds(tc.applied)
 
@@ -127,6 +128,130 @@ func TestApplyTask_BasicAppliedObjects(t *testing.T) {
 	}
 }
 
+// Tests that no objects are applied once the context has been
+// cancelled, and that the skipped objects are captured as failures
+// so they are not removed from the inventory.
+func TestApplyTask_Cancelled(t *testing.T) {
+	eventChannel := make(chan event.Event)
+	defer close(eventChannel)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	taskContext := taskrunner.NewTaskContext(ctx, eventChannel)
+
+	objs := toUnstructureds([]resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "foo",
+			namespace:  "default",
+			uid:        types.UID("my-uid"),
+			generation: int64(42),
+		},
+	})
+
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(chan event.Event, common.ServerSideOptions, common.DryRunStrategy, util.Factory) (applyOptions, dynamic.Interface, error) {
+		return &fakeApplyOptions{}, nil, nil
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	applyTask := &ApplyTask{
+		Objects:    objs,
+		InfoHelper: &fakeInfoHelper{},
+		InvInfo:    &fakeInventoryInfo{},
+	}
+	applyTask.Start(taskContext)
+	<-taskContext.TaskChannel()
+
+	assert.Empty(t, taskContext.AppliedResources())
+	expected := object.UnstructuredsToObjMetasOrDie(objs)
+	actual := taskContext.ResourceFailures()
+	if !object.SetEquals(expected, actual) {
+		t.Errorf("expected (%s) failed resources, got (%s)", expected, actual)
+	}
+}
+
+// Tests that all objects are applied and recorded in the TaskContext
+// when the objects are applied by several workers.
+func TestApplyTask_Concurrency(t *testing.T) {
+	var rss []resourceInfo
+	for i := 0; i < 10; i++ {
+		rss = append(rss, resourceInfo{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       fmt.Sprintf("foo-%d", i),
+			namespace:  "default",
+			uid:        types.UID(fmt.Sprintf("uid-%d", i)),
+			generation: int64(i),
+		})
+	}
+	objs := toUnstructureds(rss)
+
+	testCases := map[string]struct {
+		concurrency     int
+		expectedWorkers int
+	}{
+		"zero means one worker": {
+			concurrency:     0,
+			expectedWorkers: 1,
+		},
+		"fewer workers than objects": {
+			concurrency:     3,
+			expectedWorkers: 3,
+		},
+		"never more workers than objects": {
+			concurrency:     20,
+			expectedWorkers: 10,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			defer close(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
+
+			var mu sync.Mutex
+			var created int
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(chan event.Event, common.ServerSideOptions, common.DryRunStrategy, util.Factory) (applyOptions, dynamic.Interface, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				created++
+				return &fakeApplyOptions{}, nil, nil
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			applyTask := &ApplyTask{
+				Objects:     objs,
+				InfoHelper:  &fakeInfoHelper{},
+				InvInfo:     &fakeInventoryInfo{},
+				Concurrency: tc.concurrency,
+			}
+
+			getClusterObj = func(d dynamic.Interface, info *resource.Info) (*unstructured.Unstructured, error) {
+				for _, obj := range objs {
+					if info.Name == obj.GetName() && info.Namespace == obj.GetNamespace() {
+						return obj, nil
+					}
+				}
+				return nil, nil
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+
+			assert.Equal(t, tc.expectedWorkers, created)
+			expected := object.UnstructuredsToObjMetasOrDie(objs)
+			actual := taskContext.AppliedResources()
+			if !object.SetEquals(expected, actual) {
+				t.Errorf("expected (%s) applied resources, got (%s)", expected, actual)
+			}
+		})
+	}
+}
+
 func TestApplyTask_FetchGeneration(t *testing.T) {
 	testCases := map[string]struct {
 		rss []resourceInfo
@@ -170,7 +295,7 @@ func TestApplyTask_FetchGeneration(t *testing.T) {
 		t.Run(tn, func(t *testing.T) {
 			eventChannel := make(chan event.Event)
 			defer close(eventChannel)
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 
 			objs := toUnstructureds(tc.rss)
 
@@ -291,7 +416,7 @@ func TestApplyTask_DryRun(t *testing.T) {
 			drs := common.Strategies[i]
 			t.Run(tn, func(t *testing.T) {
 				eventChannel := make(chan event.Event)
-				taskContext := taskrunner.NewTaskContext(eventChannel)
+				taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 
 				restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
 					Group:   "apps",
@@ -429,7 +554,7 @@ func TestApplyTaskWithError(t *testing.T) {
 		drs := common.DryRunNone
 		t.Run(tn, func(t *testing.T) {
 			eventChannel := make(chan event.Event)
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 
 			restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
 				Group:   "apps",
@@ -614,7 +739,7 @@ func TestApplyTaskWithDifferentInventoryAnnotation(t *testing.T) {
 		drs := common.DryRunNone
 		t.Run(tn, func(t *testing.T) {
 			eventChannel := make(chan event.Event)
-			taskContext := taskrunner.NewTaskContext(eventChannel)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
 
 			restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
 				Group:   "apps",
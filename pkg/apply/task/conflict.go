@@ -0,0 +1,67 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+)
+
+// conflictMessagePrefix is the prefix of the message in the status
+// causes for field manager conflicts.
+const conflictMessagePrefix = "conflict with "
+
+// FieldConflictsFromError returns the field conflicts from the status
+// causes of an error returned by a server-side apply. Returns nil
+// if the error is not a conflict error.
+func FieldConflictsFromError(err error) []event.FieldConflict {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || !apierrors.IsConflict(statusErr) {
+		return nil
+	}
+	details := statusErr.ErrStatus.Details
+	if details == nil {
+		return nil
+	}
+	var conflicts []event.FieldConflict
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflicts = append(conflicts, event.FieldConflict{
+			Field:   cause.Field,
+			Manager: conflictManager(cause.Message),
+		})
+	}
+	return conflicts
+}
+
+// conflictManager extracts the name of the field manager from the
+// message of a conflict status cause. The message has the form
+//   conflict with "<manager>"[ using <apiVersion>[ at <time>]]
+// If the message doesn't have this form, the message is returned as is.
+func conflictManager(message string) string {
+	quoted := strings.TrimPrefix(message, conflictMessagePrefix)
+	if !strings.HasPrefix(quoted, `"`) {
+		return message
+	}
+	for i := 1; i < len(quoted); i++ {
+		switch quoted[i] {
+		case '\\':
+			i++
+		case '"':
+			manager, err := strconv.Unquote(quoted[:i+1])
+			if err != nil {
+				return message
+			}
+			return manager
+		}
+	}
+	return message
+}
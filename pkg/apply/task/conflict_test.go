@@ -0,0 +1,96 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+)
+
+func newConflictError(causes ...metav1.StatusCause) error {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   409,
+		Reason: metav1.StatusReasonConflict,
+		Details: &metav1.StatusDetails{
+			Causes: causes,
+		},
+		Message: fmt.Sprintf("Apply failed with %d conflicts", len(causes)),
+	}}
+}
+
+func TestFieldConflictsFromError(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected []event.FieldConflict
+	}{
+		"not a status error": {
+			err:      fmt.Errorf("apply failed"),
+			expected: nil,
+		},
+		"not a conflict": {
+			err:      apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "foo"),
+			expected: nil,
+		},
+		"field manager conflicts": {
+			err: newConflictError(
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "hpa-controller" using autoscaling/v1`,
+					Field:   ".spec.replicas",
+				},
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kubectl"`,
+					Field:   ".metadata.labels.app",
+				},
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "ignored",
+					Field:   ".spec",
+				},
+			),
+			expected: []event.FieldConflict{
+				{Field: ".spec.replicas", Manager: "hpa-controller"},
+				{Field: ".metadata.labels.app", Manager: "kubectl"},
+			},
+		},
+		"wrapped conflict error": {
+			err: fmt.Errorf("wrapped: %w", newConflictError(
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "a \"quoted\" manager" using v1 at 2021-01-01T00:00:00Z`,
+					Field:   ".data.key",
+				},
+			)),
+			expected: []event.FieldConflict{
+				{Field: ".data.key", Manager: `a "quoted" manager`},
+			},
+		},
+		"unexpected message format": {
+			err: newConflictError(
+				metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: "conflicts with someone",
+					Field:   ".data.key",
+				},
+			),
+			expected: []event.FieldConflict{
+				{Field: ".data.key", Manager: "conflicts with someone"},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FieldConflictsFromError(tc.err))
+		})
+	}
+}
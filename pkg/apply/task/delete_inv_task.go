@@ -36,10 +36,19 @@ func (i *DeleteInvTask) Identifiers() []object.ObjMetadata {
 	return []object.ObjMetadata{}
 }
 
-// Start deletes the inventory object from the cluster.
+// Start deletes the inventory object from the cluster. If the
+// context has been cancelled, the objects that were not deleted
+// are stored in the inventory instead.
 func (i *DeleteInvTask) Start(taskContext *taskrunner.TaskContext) {
 	go func() {
 		klog.V(2).Infoln("starting delete inventory task")
+		if taskContext.Cancelled() {
+			pruneFailures := taskContext.PruneFailures()
+			klog.V(4).Infof("delete inventory task cancelled; keeping %d objects", len(pruneFailures))
+			err := i.InvClient.Replace(i.InvInfo, pruneFailures, i.DryRun)
+			taskContext.TaskChannel() <- taskrunner.TaskResult{Err: err}
+			return
+		}
 		err := i.InvClient.DeleteInventoryObj(i.InvInfo, i.DryRun)
 		// Not found is not error, since this means it was already deleted.
 		if apierrors.IsNotFound(err) {
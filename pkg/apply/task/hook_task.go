@@ -0,0 +1,233 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// defaultHookPollInterval is used if no PollInterval is set
+// on the HookTask.
+const defaultHookPollInterval = 2 * time.Second
+
+// HookTask runs the hooks for a single phase of the apply. The hooks
+// are run one at a time, in the order of the Objects. Each hook is
+// created and then waited for until it has completed, and the task
+// fails as soon as one of the hooks fails. Hooks are deleted
+// according to their delete policy.
+type HookTask struct {
+	TaskName string
+
+	Phase             hook.Phase
+	Objects           []*unstructured.Unstructured
+	Client            dynamic.Interface
+	Mapper            meta.RESTMapper
+	DryRunStrategy    common.DryRunStrategy
+	PropagationPolicy metav1.DeletionPropagation
+	// Timeout is how long to wait for each hook to complete. A zero
+	// value means there is no timeout.
+	Timeout time.Duration
+	// PollInterval is how often the hook is checked for completion.
+	PollInterval time.Duration
+}
+
+func (h *HookTask) Name() string {
+	return h.TaskName
+}
+
+func (h *HookTask) Action() event.ResourceAction {
+	return event.HookAction
+}
+
+func (h *HookTask) Identifiers() []object.ObjMetadata {
+	return object.UnstructuredsToObjMetasOrDie(h.Objects)
+}
+
+// Start runs the hooks. Hooks are not run during a dry-run, and no
+// more hooks are started if the context has been cancelled.
+func (h *HookTask) Start(taskContext *taskrunner.TaskContext) {
+	go func() {
+		klog.V(2).Infof("hook task starting (phase: %s, %d objects)", h.Phase, len(h.Objects))
+		var err error
+		for _, obj := range h.Objects {
+			id := object.UnstructuredToObjMetaOrDie(obj)
+			if h.DryRunStrategy.ClientOrServerDryRun() {
+				taskContext.EventChannel() <- h.createEvent(id, event.HookSkipped, obj, nil)
+				continue
+			}
+			if taskContext.Cancelled() {
+				klog.V(4).Infof("hook task cancelled; skipping %s", id)
+				continue
+			}
+			if err = h.runHook(taskContext, id, obj); err != nil {
+				break
+			}
+		}
+		taskContext.TaskChannel() <- taskrunner.TaskResult{
+			Err: err,
+		}
+	}()
+}
+
+// runHook creates a single hook and waits for it to complete. An
+// error is returned if the hook could not be run or has failed.
+func (h *HookTask) runHook(taskContext *taskrunner.TaskContext, id object.ObjMetadata,
+	obj *unstructured.Unstructured) error {
+	client, err := h.namespacedClient(id)
+	if err != nil {
+		return h.hookFailed(taskContext, id, obj, err)
+	}
+	if hook.HasDeletePolicy(obj, hook.BeforeHookCreation) {
+		if err := h.deleteAndWait(taskContext.Context(), client, id); err != nil {
+			return h.hookFailed(taskContext, id, obj, err)
+		}
+	}
+	klog.V(4).Infof("creating %s hook %s", h.Phase, id)
+	created, err := client.Create(context.TODO(), obj, metav1.CreateOptions{})
+	if err != nil {
+		return h.hookFailed(taskContext, id, obj, err)
+	}
+	taskContext.EventChannel() <- h.createEvent(id, event.HookCreated, created, nil)
+
+	hookErr := h.waitForCompletion(taskContext.Context(), client, id)
+	if hookErr != nil {
+		taskContext.EventChannel() <- h.createEvent(id, event.HookFailed, created, hookErr)
+		if hook.HasDeletePolicy(obj, hook.HookFailed) {
+			h.deleteHook(taskContext, client, id, created)
+		}
+		return applyerror.NewHookFailedError(id, string(h.Phase), hookErr)
+	}
+	taskContext.EventChannel() <- h.createEvent(id, event.HookSucceeded, created, nil)
+	if hook.HasDeletePolicy(obj, hook.HookSucceeded) {
+		h.deleteHook(taskContext, client, id, created)
+	}
+	return nil
+}
+
+// hookFailed sends the event for a hook that could not be run, and
+// returns the error for the task.
+func (h *HookTask) hookFailed(taskContext *taskrunner.TaskContext, id object.ObjMetadata,
+	obj *unstructured.Unstructured, err error) error {
+	taskContext.EventChannel() <- h.createEvent(id, event.HookFailed, obj, err)
+	return applyerror.NewHookFailedError(id, string(h.Phase), err)
+}
+
+// waitForCompletion polls the hook until it has completed. It returns
+// nil if the hook succeeded, and an error if it failed, was deleted
+// or did not complete before the timeout.
+func (h *HookTask) waitForCompletion(ctx context.Context, client dynamic.ResourceInterface,
+	id object.ObjMetadata) error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	var hookErr error
+	err := wait.PollImmediateUntil(h.pollInterval(), func() (bool, error) {
+		u, err := client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("hook was deleted before it completed")
+			}
+			klog.V(4).Infof("error getting hook %s: %s", id, err)
+			return false, nil
+		}
+		done, err := hook.Completed(u)
+		hookErr = err
+		return done, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("hook did not complete: %w", ctx.Err())
+	}
+	if err != nil {
+		return err
+	}
+	return hookErr
+}
+
+// deleteAndWait deletes a previous instance of the hook, and waits
+// until it is gone from the cluster.
+func (h *HookTask) deleteAndWait(ctx context.Context, client dynamic.ResourceInterface,
+	id object.ObjMetadata) error {
+	err := client.Delete(context.TODO(), id.Name, metav1.DeleteOptions{
+		PropagationPolicy: &h.PropagationPolicy,
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("waiting for previous %s hook %s to be deleted", h.Phase, id)
+	return wait.PollImmediateUntil(h.pollInterval(), func() (bool, error) {
+		_, err := client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, nil
+	}, ctx.Done())
+}
+
+// deleteHook deletes the hook after it has completed. A failure to
+// delete the hook is reported, but does not fail the task.
+func (h *HookTask) deleteHook(taskContext *taskrunner.TaskContext, client dynamic.ResourceInterface,
+	id object.ObjMetadata, obj *unstructured.Unstructured) {
+	klog.V(4).Infof("deleting %s hook %s", h.Phase, id)
+	err := client.Delete(context.TODO(), id.Name, metav1.DeleteOptions{
+		PropagationPolicy: &h.PropagationPolicy,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		taskContext.EventChannel() <- h.createEvent(id, event.HookDeleted, obj, err)
+		return
+	}
+	taskContext.EventChannel() <- h.createEvent(id, event.HookDeleted, obj, nil)
+}
+
+func (h *HookTask) pollInterval() time.Duration {
+	if h.PollInterval <= 0 {
+		return defaultHookPollInterval
+	}
+	return h.PollInterval
+}
+
+func (h *HookTask) namespacedClient(id object.ObjMetadata) (dynamic.ResourceInterface, error) {
+	mapping, err := h.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return h.Client.Resource(mapping.Resource).Namespace(id.Namespace), nil
+}
+
+func (h *HookTask) createEvent(id object.ObjMetadata, operation event.HookEventOperation,
+	obj *unstructured.Unstructured, err error) event.Event {
+	return event.Event{
+		Type: event.HookType,
+		HookEvent: event.HookEvent{
+			Identifier: id,
+			Phase:      string(h.Phase),
+			Operation:  operation,
+			Object:     obj,
+			Error:      err,
+		},
+	}
+}
+
+// ClearTimeout is not supported by the HookTask.
+func (h *HookTask) ClearTimeout() {}
@@ -0,0 +1,174 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+var jobResource = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+
+func newHookJob(name, conditionType, deletePolicy string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   conditionType,
+						"status": "True",
+					},
+				},
+			},
+		},
+	}
+	annotations := map[string]string{
+		hook.Annotation: string(hook.PreApply),
+	}
+	if deletePolicy != "" {
+		annotations[hook.DeletePolicyAnnotation] = deletePolicy
+	}
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func TestHookTask(t *testing.T) {
+	testCases := map[string]struct {
+		hooks          []*unstructured.Unstructured
+		existing       []*unstructured.Unstructured
+		dryRunStrategy common.DryRunStrategy
+		expectedOps    []event.HookEventOperation
+		expectedError  bool
+		expectedJobs   []string
+	}{
+		"successful hook is kept": {
+			hooks: []*unstructured.Unstructured{
+				newHookJob("migrate", "Complete", ""),
+			},
+			expectedOps:  []event.HookEventOperation{event.HookCreated, event.HookSucceeded},
+			expectedJobs: []string{"migrate"},
+		},
+		"previous instance is replaced": {
+			hooks: []*unstructured.Unstructured{
+				newHookJob("migrate", "Complete", ""),
+			},
+			existing: []*unstructured.Unstructured{
+				newHookJob("migrate", "Failed", ""),
+			},
+			expectedOps:  []event.HookEventOperation{event.HookCreated, event.HookSucceeded},
+			expectedJobs: []string{"migrate"},
+		},
+		"successful hook is deleted": {
+			hooks: []*unstructured.Unstructured{
+				newHookJob("migrate", "Complete", "hook-succeeded"),
+			},
+			expectedOps: []event.HookEventOperation{event.HookCreated, event.HookSucceeded,
+				event.HookDeleted},
+			expectedJobs: []string{},
+		},
+		"failed hook stops the task": {
+			hooks: []*unstructured.Unstructured{
+				newHookJob("migrate", "Failed", "hook-failed"),
+				newHookJob("never-run", "Complete", ""),
+			},
+			expectedOps: []event.HookEventOperation{event.HookCreated, event.HookFailed,
+				event.HookDeleted},
+			expectedError: true,
+			expectedJobs:  []string{},
+		},
+		"hooks are skipped for dry-run": {
+			hooks: []*unstructured.Unstructured{
+				newHookJob("migrate", "Complete", ""),
+			},
+			dryRunStrategy: common.DryRunClient,
+			expectedOps:    []event.HookEventOperation{event.HookSkipped},
+			expectedJobs:   []string{},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var existing []runtime.Object
+			for _, obj := range tc.existing {
+				existing = append(existing, obj)
+			}
+			client := fake.NewSimpleDynamicClient(scheme.Scheme, existing...)
+			eventChannel := make(chan event.Event, 10)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
+
+			task := HookTask{
+				TaskName: "hook-0",
+				Phase:    hook.PreApply,
+				Objects:  tc.hooks,
+				Client:   client,
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+				DryRunStrategy:    tc.dryRunStrategy,
+				PropagationPolicy: metav1.DeletePropagationBackground,
+				Timeout:           time.Minute,
+				PollInterval:      10 * time.Millisecond,
+			}
+			task.Start(taskContext)
+			result := <-taskContext.TaskChannel()
+			close(eventChannel)
+
+			if tc.expectedError {
+				_, ok := result.Err.(*applyerror.HookFailedError)
+				assert.True(t, ok, "expected HookFailedError, got %v", result.Err)
+			} else {
+				assert.NoError(t, result.Err)
+			}
+
+			var ops []event.HookEventOperation
+			for e := range eventChannel {
+				require.Equal(t, event.HookType, e.Type)
+				assert.Equal(t, string(hook.PreApply), e.HookEvent.Phase)
+				ops = append(ops, e.HookEvent.Operation)
+			}
+			assert.Equal(t, tc.expectedOps, ops)
+
+			for _, obj := range tc.hooks {
+				_, err := client.Resource(jobResource).Namespace("default").
+					Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+				if contains(tc.expectedJobs, obj.GetName()) {
+					assert.NoError(t, err)
+				} else {
+					assert.True(t, apierrors.IsNotFound(err), "expected %s to not exist", obj.GetName())
+				}
+			}
+		})
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
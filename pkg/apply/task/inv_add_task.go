@@ -41,12 +41,19 @@ func (i *InvAddTask) Identifiers() []object.ObjMetadata {
 func (i *InvAddTask) Start(taskContext *taskrunner.TaskContext) {
 	go func() {
 		klog.V(2).Infoln("starting inventory add task")
+		// Nothing will be applied if the context has been cancelled,
+		// so there is no need to add the objects to the inventory.
+		if taskContext.Cancelled() {
+			klog.V(4).Infoln("inventory add task cancelled")
+			taskContext.TaskChannel() <- taskrunner.TaskResult{}
+			return
+		}
 		if err := inventory.ValidateNoInventory(i.Objects); err != nil {
 			taskContext.TaskChannel() <- taskrunner.TaskResult{Err: err}
 			return
 		}
 		// Ensures the namespace exists before applying the inventory object into it.
-		if invNamespace := inventoryNamespaceInSet(i.InvInfo, i.Objects); invNamespace != nil {
+		if invNamespace := InventoryNamespaceInSet(i.InvInfo, i.Objects); invNamespace != nil {
 			klog.V(4).Infof("applying inventory namespace %s", invNamespace.GetName())
 			if err := i.InvClient.ApplyInventoryNamespace(invNamespace, i.DryRun); err != nil {
 				taskContext.TaskChannel() <- taskrunner.TaskResult{Err: err}
@@ -63,10 +70,10 @@ func (i *InvAddTask) Start(taskContext *taskrunner.TaskContext) {
 // ClearTimeout is not supported by the InvAddTask.
 func (i *InvAddTask) ClearTimeout() {}
 
-// inventoryNamespaceInSet returns the the namespace the passed inventory
+// InventoryNamespaceInSet returns the namespace the passed inventory
 // object will be applied to, or nil if this namespace object does not exist
 // in the passed slice "infos" or the inventory object is cluster-scoped.
-func inventoryNamespaceInSet(inv inventory.InventoryInfo, objs []*unstructured.Unstructured) *unstructured.Unstructured {
+func InventoryNamespaceInSet(inv inventory.InventoryInfo, objs []*unstructured.Unstructured) *unstructured.Unstructured {
 	if inv == nil {
 		return nil
 	}
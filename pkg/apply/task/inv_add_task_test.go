@@ -4,6 +4,7 @@
 package task
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -109,7 +110,7 @@ func TestInvAddTask(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			client := inventory.NewFakeInventoryClient(tc.initialObjs)
 			eventChannel := make(chan event.Event)
-			context := taskrunner.NewTaskContext(eventChannel)
+			context := taskrunner.NewTaskContext(context.Background(), eventChannel)
 			task := InvAddTask{
 				TaskName:  taskName,
 				InvClient: client,
@@ -175,7 +176,7 @@ func TestInventoryNamespaceInSet(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			actualNamespace := inventoryNamespaceInSet(tc.inv, tc.objects)
+			actualNamespace := InventoryNamespaceInSet(tc.inv, tc.objects)
 			if tc.namespace != actualNamespace {
 				t.Fatalf("expected namespace (%v), got (%v)", tc.namespace, actualNamespace)
 			}
@@ -4,6 +4,8 @@
 package task
 
 import (
+	"time"
+
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
@@ -20,6 +22,10 @@ type InvSetTask struct {
 	InvInfo       inventory.InventoryInfo
 	PrevInventory map[object.ObjMetadata]bool
 	DryRun        common.DryRunStrategy
+	// Rollback defines whether the task runs after the applied objects
+	// have been rolled back. The objects of the previous inventory are
+	// then kept in the inventory.
+	Rollback bool
 }
 
 func (i *InvSetTask) Name() string {
@@ -40,7 +46,15 @@ func (i *InvSetTask) Identifiers() []object.ObjMetadata {
 func (i *InvSetTask) Start(taskContext *taskrunner.TaskContext) {
 	go func() {
 		klog.V(2).Infoln("starting inventory replace task")
-		appliedObjs := taskContext.AppliedResources()
+		// Objects that have been rolled back are only kept in the
+		// inventory if they were there before they were applied.
+		appliedObjs := []object.ObjMetadata{}
+		for _, applied := range taskContext.AppliedResources() {
+			if _, exists := i.PrevInventory[applied]; !exists && taskContext.ResourceRolledBack(applied) {
+				continue
+			}
+			appliedObjs = append(appliedObjs, applied)
+		}
 		klog.V(4).Infof("set inventory %d applied objects", len(appliedObjs))
 		// If an object failed to apply, but it was previously stored in
 		// the inventory, then keep it in the inventory so we don't lose
@@ -57,11 +71,75 @@ func (i *InvSetTask) Start(taskContext *taskrunner.TaskContext) {
 		klog.V(4).Infof("set inventory %d prune failures", len(pruneFailures))
 		allApplyObjs := object.Union(appliedObjs, applyFailures)
 		invObjs := object.Union(allApplyObjs, pruneFailures)
+		// After a rollback, the objects scheduled for pruning and the
+		// objects of the apply tasks that never started are still in
+		// the cluster, so the previous inventory is restored. Only the
+		// objects created by this run and deleted by the rollback are
+		// dropped.
+		var keptObjs []object.ObjMetadata
+		if i.Rollback {
+			keptObjs = object.SetDiff(i.prevInventoryObjs(), invObjs)
+			klog.V(4).Infof("keep in inventory %d previous objects", len(keptObjs))
+			invObjs = object.Union(invObjs, keptObjs)
+		}
 		klog.V(4).Infof("set inventory %d total objects", len(invObjs))
-		err := i.InvClient.Replace(i.InvInfo, invObjs, i.DryRun)
+		statuses := i.objectStatuses(taskContext, appliedObjs, applyFailures, pruneFailures)
+		for _, id := range keptObjs {
+			// An empty status keeps the stored status of the object.
+			statuses = append(statuses, inventory.ObjectStatus{Identifier: id})
+		}
+		err := i.InvClient.ReplaceWithStatus(i.InvInfo, invObjs, statuses, i.DryRun)
 		taskContext.TaskChannel() <- taskrunner.TaskResult{Err: err}
 	}()
 }
 
+// prevInventoryObjs returns the objects of the previous inventory.
+func (i *InvSetTask) prevInventoryObjs() []object.ObjMetadata {
+	objs := make([]object.ObjMetadata, 0, len(i.PrevInventory))
+	for id := range i.PrevInventory {
+		objs = append(objs, id)
+	}
+	return objs
+}
+
+// objectStatuses returns the status to store in the inventory for the
+// passed objects, from the result of the apply and prune recorded in the
+// task context. A prune failure takes precedence over the apply result,
+// since pruning happens after applying.
+func (i *InvSetTask) objectStatuses(taskContext *taskrunner.TaskContext, appliedObjs,
+	applyFailures, pruneFailures []object.ObjMetadata) []inventory.ObjectStatus {
+	now := time.Now()
+	statuses := make(map[object.ObjMetadata]inventory.ObjectStatus)
+	for _, id := range appliedObjs {
+		s := inventory.ObjectStatus{
+			Identifier:  id,
+			ApplyResult: inventory.ApplySucceeded,
+		}
+		if taskContext.ResourceRolledBack(id) {
+			s.ApplyResult = inventory.ApplyRolledBack
+		} else {
+			s.UID, _ = taskContext.ResourceUID(id)
+			s.Generation, _ = taskContext.ResourceGeneration(id)
+			s.LastApplied = now
+		}
+		statuses[id] = s
+	}
+	for _, id := range applyFailures {
+		statuses[id] = inventory.ObjectStatus{Identifier: id, ApplyResult: inventory.ApplyFailed}
+	}
+	for _, id := range pruneFailures {
+		statuses[id] = inventory.ObjectStatus{Identifier: id, ApplyResult: inventory.PruneFailed}
+	}
+	result := make([]inventory.ObjectStatus, 0, len(statuses))
+	for id, s := range statuses {
+		if st, found := taskContext.ResourceStatus(id); found {
+			s.Status = st
+		}
+		s.LastUpdated = now
+		result = append(result, s)
+	}
+	return result
+}
+
 // ClearTimeout is not supported by the InvSetTask.
 func (i *InvSetTask) ClearTimeout() {}
@@ -4,12 +4,15 @@
 package task
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
@@ -105,7 +108,7 @@ func TestInvSetTask(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			client := inventory.NewFakeInventoryClient([]object.ObjMetadata{})
 			eventChannel := make(chan event.Event)
-			context := taskrunner.NewTaskContext(eventChannel)
+			context := taskrunner.NewTaskContext(context.Background(), eventChannel)
 			prevInventory := make(map[object.ObjMetadata]bool, len(tc.prevInventory))
 			for _, prevInvID := range tc.prevInventory {
 				prevInventory[prevInvID] = true
@@ -140,3 +143,54 @@ func TestInvSetTask(t *testing.T) {
 		})
 	}
 }
+
+func TestInvSetTask_Statuses(t *testing.T) {
+	id1 := object.UnstructuredToObjMetaOrDie(obj1)
+	id2 := object.UnstructuredToObjMetaOrDie(obj2)
+	id3 := object.UnstructuredToObjMetaOrDie(obj3)
+
+	client := inventory.NewFakeInventoryClient([]object.ObjMetadata{})
+	context := taskrunner.NewTaskContext(context.Background(), make(chan event.Event))
+	task := InvSetTask{
+		TaskName:      taskName,
+		InvClient:     client,
+		PrevInventory: map[object.ObjMetadata]bool{id2: true},
+	}
+	context.ResourceApplied(id1, "uid-1", int64(2))
+	context.CaptureResourceStatus(id1, status.CurrentStatus)
+	context.CaptureResourceFailure(id2)
+	context.CapturePruneFailure(id3)
+
+	task.Start(context)
+	result := <-context.TaskChannel()
+	if result.Err != nil {
+		t.Fatalf("unexpected error running InvSetTask: %s", result.Err)
+	}
+	statuses, _ := client.GetClusterObjStatuses(nil)
+	expected := map[object.ObjMetadata]inventory.ObjectStatus{
+		id1: {
+			Identifier:  id1,
+			ApplyResult: inventory.ApplySucceeded,
+			UID:         "uid-1",
+			Generation:  2,
+			Status:      status.CurrentStatus,
+		},
+		id2: {Identifier: id2, ApplyResult: inventory.ApplyFailed},
+		id3: {Identifier: id3, ApplyResult: inventory.PruneFailed},
+	}
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected %d statuses, got %d", len(expected), len(statuses))
+	}
+	for _, s := range statuses {
+		if s.LastUpdated.IsZero() {
+			t.Errorf("expected last updated time for %s", s.Identifier)
+		}
+		if s.ApplyResult == inventory.ApplySucceeded && s.LastApplied.IsZero() {
+			t.Errorf("expected last applied time for %s", s.Identifier)
+		}
+		s.LastApplied, s.LastUpdated = time.Time{}, time.Time{}
+		if s != expected[s.Identifier] {
+			t.Errorf("expected status %+v, got %+v", expected[s.Identifier], s)
+		}
+	}
+}
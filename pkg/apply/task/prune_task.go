@@ -6,6 +6,7 @@ package task
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
@@ -29,6 +30,9 @@ type PruneTask struct {
 	// True if we are destroying, which deletes the inventory object
 	// as well (possibly) the inventory namespace.
 	Destroy bool
+	// InventoryUIDs are the UIDs of the objects when they were last
+	// applied, as stored in the inventory.
+	InventoryUIDs map[object.ObjMetadata]types.UID
 }
 
 func (p *PruneTask) Name() string {
@@ -55,9 +59,11 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 	go func() {
 		klog.V(2).Infof("prune task starting (%d objects)", len(p.Objects))
 		// Create filter to prevent deletion of currently applied
-		// objects. Must be done here to wait for applied UIDs.
+		// objects, and of objects recreated outside of the inventory.
+		// Must be done here to wait for applied UIDs.
 		uidFilter := filter.CurrentUIDFilter{
-			CurrentUIDs: taskContext.AppliedResourceUIDs(),
+			CurrentUIDs:   taskContext.AppliedResourceUIDs(),
+			InventoryUIDs: p.InventoryUIDs,
 		}
 		p.Filters = append(p.Filters, uidFilter)
 		err := p.PruneOptions.Prune(p.Objects,
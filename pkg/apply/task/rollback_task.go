@@ -0,0 +1,146 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/ordering"
+)
+
+// RollbackTask reverts the changes made by the apply tasks that
+// have run before it. Objects that existed before they were applied
+// are restored to the state captured in the TaskContext, and objects
+// that were created are deleted. This task is only run if some
+// other task has failed.
+type RollbackTask struct {
+	TaskName string
+
+	Client            dynamic.Interface
+	Mapper            meta.RESTMapper
+	PropagationPolicy metav1.DeletionPropagation
+}
+
+func (r *RollbackTask) Name() string {
+	return r.TaskName
+}
+
+func (r *RollbackTask) Action() event.ResourceAction {
+	return event.RollbackAction
+}
+
+func (r *RollbackTask) Identifiers() []object.ObjMetadata {
+	return []object.ObjMetadata{}
+}
+
+// Start rolls back all the objects that have been applied, in the
+// reverse order of how they would be applied. A rollback failure for
+// one object does not stop the rollback of the other objects. The
+// rollback is not interrupted if the context has been cancelled,
+// since that would leave the cluster in a partially applied state.
+func (r *RollbackTask) Start(taskContext *taskrunner.TaskContext) {
+	go func() {
+		ids := taskContext.AppliedResources()
+		klog.V(2).Infof("rollback task starting (%d objects)", len(ids))
+		sort.Sort(sort.Reverse(ordering.SortableMetas(ids)))
+		for _, id := range ids {
+			prevObj, found := taskContext.PreviousObject(id)
+			if !found {
+				klog.V(4).Infof("no previous state captured for %s--continue", id)
+				continue
+			}
+			var e event.Event
+			if prevObj == nil {
+				e = r.deleteObject(id)
+			} else {
+				e = r.restoreObject(id, prevObj)
+			}
+			if e.RollbackEvent.Error == nil {
+				taskContext.CaptureResourceRollback(id)
+			}
+			taskContext.EventChannel() <- e
+		}
+		taskContext.TaskChannel() <- taskrunner.TaskResult{}
+	}()
+}
+
+// deleteObject deletes an object that did not exist before it
+// was applied.
+func (r *RollbackTask) deleteObject(id object.ObjMetadata) event.Event {
+	klog.V(4).Infof("rollback deleting %s", id)
+	namespacedClient, err := r.namespacedClient(id)
+	if err != nil {
+		return createRollbackFailedEvent(id, err)
+	}
+	err = namespacedClient.Delete(context.TODO(), id.Name, metav1.DeleteOptions{
+		PropagationPolicy: &r.PropagationPolicy,
+	})
+	if err != nil {
+		return createRollbackFailedEvent(id, err)
+	}
+	return createRollbackEvent(id, event.RollbackDeleted, nil)
+}
+
+// restoreObject updates the object in the cluster to match
+// the state it had before it was applied.
+func (r *RollbackTask) restoreObject(id object.ObjMetadata, prevObj *unstructured.Unstructured) event.Event {
+	klog.V(4).Infof("rollback restoring %s", id)
+	namespacedClient, err := r.namespacedClient(id)
+	if err != nil {
+		return createRollbackFailedEvent(id, err)
+	}
+	clusterObj, err := namespacedClient.Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if err != nil {
+		return createRollbackFailedEvent(id, err)
+	}
+	obj := prevObj.DeepCopy()
+	// The update must be based on the latest version of the object.
+	obj.SetResourceVersion(clusterObj.GetResourceVersion())
+	restored, err := namespacedClient.Update(context.TODO(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		return createRollbackFailedEvent(id, err)
+	}
+	return createRollbackEvent(id, event.RollbackRestored, restored)
+}
+
+func (r *RollbackTask) namespacedClient(id object.ObjMetadata) (dynamic.ResourceInterface, error) {
+	mapping, err := r.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return r.Client.Resource(mapping.Resource).Namespace(id.Namespace), nil
+}
+
+// ClearTimeout is not supported by the RollbackTask.
+func (r *RollbackTask) ClearTimeout() {}
+
+func createRollbackEvent(id object.ObjMetadata, operation event.RollbackEventOperation, obj *unstructured.Unstructured) event.Event {
+	return event.Event{
+		Type: event.RollbackType,
+		RollbackEvent: event.RollbackEvent{
+			Identifier: id,
+			Operation:  operation,
+			Object:     obj,
+		},
+	}
+}
+
+func createRollbackFailedEvent(id object.ObjMetadata, err error) event.Event {
+	return event.Event{
+		Type: event.RollbackType,
+		RollbackEvent: event.RollbackEvent{
+			Identifier: id,
+			Error:      err,
+		},
+	}
+}
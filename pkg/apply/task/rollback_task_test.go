@@ -0,0 +1,97 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newConfigMap(name, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"data": map[string]interface{}{
+				"key": value,
+			},
+		},
+	}
+}
+
+func TestRollbackTask(t *testing.T) {
+	changed := newConfigMap("changed", "new")
+	previous := newConfigMap("changed", "old")
+	created := newConfigMap("created", "new")
+	unchanged := newConfigMap("unchanged", "new")
+
+	changedID := object.UnstructuredToObjMetaOrDie(changed)
+	createdID := object.UnstructuredToObjMetaOrDie(created)
+	unchangedID := object.UnstructuredToObjMetaOrDie(unchanged)
+
+	client := fake.NewSimpleDynamicClient(scheme.Scheme, changed, created, unchanged)
+	eventChannel := make(chan event.Event, 3)
+	taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
+	taskContext.CapturePreviousObject(changedID, previous)
+	taskContext.ResourceApplied(changedID, "", 1)
+	taskContext.CapturePreviousObject(createdID, nil)
+	taskContext.ResourceApplied(createdID, "", 1)
+	// Objects without a captured previous state are left alone.
+	taskContext.ResourceApplied(unchangedID, "", 1)
+
+	task := RollbackTask{
+		TaskName: "rollback-0",
+		Client:   client,
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+		PropagationPolicy: metav1.DeletePropagationBackground,
+	}
+	task.Start(taskContext)
+	result := <-taskContext.TaskChannel()
+	require.NoError(t, result.Err)
+	close(eventChannel)
+
+	operations := make(map[object.ObjMetadata]event.RollbackEventOperation)
+	for e := range eventChannel {
+		require.Equal(t, event.RollbackType, e.Type)
+		require.NoError(t, e.RollbackEvent.Error)
+		operations[e.RollbackEvent.Identifier] = e.RollbackEvent.Operation
+	}
+	assert.Equal(t, map[object.ObjMetadata]event.RollbackEventOperation{
+		changedID: event.RollbackRestored,
+		createdID: event.RollbackDeleted,
+	}, operations)
+	assert.True(t, taskContext.ResourceRolledBack(changedID))
+	assert.True(t, taskContext.ResourceRolledBack(createdID))
+	assert.False(t, taskContext.ResourceRolledBack(unchangedID))
+
+	cmResource := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	restored, err := client.Resource(cmResource).Namespace("default").
+		Get(context.TODO(), "changed", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, err := unstructured.NestedString(restored.Object, "data", "key")
+	require.NoError(t, err)
+	assert.Equal(t, "old", value)
+
+	_, err = client.Resource(cmResource).Namespace("default").
+		Get(context.TODO(), "created", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
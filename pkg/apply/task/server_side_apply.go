@@ -0,0 +1,143 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/net/http2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// serverSideApply applies the object in the info to the cluster with a
+// server-side apply patch, and sends an apply event with the result. The
+// clusterObj is the state of the object before it was applied, and is
+// used to decide whether the object was created, configured or left
+// unchanged. On success, the object in the info is replaced with the
+// object returned by the API server.
+func (a *ApplyTask) serverSideApply(taskContext *taskrunner.TaskContext, client dynamic.Interface,
+	info *resource.Info, clusterObj *unstructured.Unstructured) error {
+	obj := object.InfoToUnstructured(info)
+	id := object.UnstructuredToObjMetaOrDie(obj)
+	result, err := a.applyPatch(client, obj)
+	if err != nil {
+		if isAPIService(obj) && isStreamError(err) {
+			// Server-side Apply doesn't work with APIService before k8s 1.21
+			// https://github.com/kubernetes/kubernetes/issues/89264
+			// Thus APIService is handled specially using client-side apply.
+			klog.V(4).Infof("server-side apply of %s failed with a stream error; using client-side apply", id)
+			return clientSideApply(info, taskContext.EventChannel(), a.DryRunStrategy, a.Factory)
+		}
+		return err
+	}
+	operation := applyOperation(clusterObj, result, a.DryRunStrategy)
+	info.Object = result
+	taskContext.EventChannel() <- createApplyEvent(id, operation, result)
+	return nil
+}
+
+// applyPatch sends the server-side apply patch for the object, and
+// returns the object returned by the API server. For a client dry-run
+// no request is made, and the object itself is returned.
+func (a *ApplyTask) applyPatch(client dynamic.Interface, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if a.DryRunStrategy.ClientDryRun() {
+		return obj.DeepCopy(), nil
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := a.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	fieldManager := a.ServerSideOptions.FieldManager
+	if fieldManager == "" {
+		fieldManager = common.DefaultFieldManager
+	}
+	force := a.ServerSideOptions.ForceConflicts
+	options := metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        &force,
+	}
+	if a.DryRunStrategy.ServerDryRun() {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	return client.Resource(mapping.Resource).Namespace(obj.GetNamespace()).
+		Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, options)
+}
+
+// applyOperation returns the operation that was performed by applying
+// an object, based on the object in the cluster before the apply and the
+// object returned by the apply. An object that didn't exist was created.
+// Otherwise, a change in the generation or resourceVersion means the
+// object was configured. Since a server dry-run doesn't change the
+// resourceVersion, the objects are compared without the fields managed by
+// the API server instead. For a client dry-run it can't be known whether
+// an existing object would change.
+func applyOperation(clusterObj, result *unstructured.Unstructured,
+	strategy common.DryRunStrategy) event.ApplyEventOperation {
+	switch {
+	case clusterObj == nil:
+		return event.Created
+	case strategy.ClientDryRun():
+		return event.ServersideApplied
+	case clusterObj.GetGeneration() != result.GetGeneration():
+		return event.Configured
+	case strategy.ServerDryRun():
+		if equality.Semantic.DeepEqual(withoutServerFields(clusterObj), withoutServerFields(result)) {
+			return event.Unchanged
+		}
+		return event.Configured
+	case clusterObj.GetResourceVersion() != result.GetResourceVersion():
+		return event.Configured
+	default:
+		return event.Unchanged
+	}
+}
+
+// withoutServerFields returns a copy of the object without the metadata
+// fields that are updated by the API server on every write.
+func withoutServerFields(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	u := obj.DeepCopy()
+	u.SetManagedFields(nil)
+	u.SetResourceVersion("")
+	return u
+}
+
+func isAPIService(obj *unstructured.Unstructured) bool {
+	gk := obj.GroupVersionKind().GroupKind()
+	return gk.Group == "apiregistration.k8s.io" && gk.Kind == "APIService"
+}
+
+// isStreamError checks if the error is, or wraps, an HTTP/2 StreamError.
+func isStreamError(err error) bool {
+	var streamErr http2.StreamError
+	return errors.As(err, &streamErr)
+}
+
+// clientSideApply applies the object in the info using kubectl's
+// client-side apply. This is only used as a fallback for objects that
+// can't be applied with server-side apply.
+func clientSideApply(info *resource.Info, eventChannel chan event.Event, strategy common.DryRunStrategy, factory util.Factory) error {
+	ao, _, err := applyOptionsFactoryFunc(eventChannel, common.ServerSideOptions{ServerSideApply: false}, strategy, factory)
+	if err != nil {
+		return err
+	}
+	ao.SetObjects([]*resource.Info{info})
+	return ao.Run()
+}
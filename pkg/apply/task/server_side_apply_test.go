@@ -0,0 +1,297 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	apps "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func withVersion(obj *unstructured.Unstructured, resourceVersion string, generation int64) *unstructured.Unstructured {
+	u := obj.DeepCopy()
+	u.SetResourceVersion(resourceVersion)
+	u.SetGeneration(generation)
+	return u
+}
+
+var apiService = toUnstructured(map[string]interface{}{
+	"apiVersion": "apiregistration.k8s.io/v1",
+	"kind":       "APIService",
+	"metadata": map[string]interface{}{
+		"name": "v1beta1.metrics.k8s.io",
+	},
+})
+
+func TestApplyOperation(t *testing.T) {
+	labeled := withVersion(deployment, "2", 1)
+	labeled.SetLabels(map[string]string{"app": "foo"})
+
+	testCases := map[string]struct {
+		clusterObj *unstructured.Unstructured
+		result     *unstructured.Unstructured
+		strategy   common.DryRunStrategy
+		expected   event.ApplyEventOperation
+	}{
+		"object did not exist": {
+			clusterObj: nil,
+			result:     withVersion(deployment, "1", 1),
+			expected:   event.Created,
+		},
+		"generation changed": {
+			clusterObj: withVersion(deployment, "1", 1),
+			result:     withVersion(deployment, "2", 2),
+			expected:   event.Configured,
+		},
+		"resourceVersion changed": {
+			clusterObj: withVersion(deployment, "1", 1),
+			result:     labeled,
+			expected:   event.Configured,
+		},
+		"nothing changed": {
+			clusterObj: withVersion(deployment, "1", 1),
+			result:     withVersion(deployment, "1", 1),
+			expected:   event.Unchanged,
+		},
+		"server dry-run with no changes": {
+			clusterObj: withVersion(deployment, "1", 1),
+			result:     withVersion(deployment, "1", 1),
+			strategy:   common.DryRunServer,
+			expected:   event.Unchanged,
+		},
+		"server dry-run with changes": {
+			clusterObj: withVersion(deployment, "1", 1),
+			result:     labeled,
+			strategy:   common.DryRunServer,
+			expected:   event.Configured,
+		},
+		"server dry-run of new object": {
+			clusterObj: nil,
+			result:     withVersion(deployment, "", 1),
+			strategy:   common.DryRunServer,
+			expected:   event.Created,
+		},
+		"client dry-run of existing object": {
+			clusterObj: withVersion(deployment, "1", 1),
+			result:     deployment,
+			strategy:   common.DryRunClient,
+			expected:   event.ServersideApplied,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, applyOperation(tc.clusterObj, tc.result, tc.strategy))
+		})
+	}
+}
+
+func TestApplyTask_ServerSideApply(t *testing.T) {
+	conflictErr := newConflictError(metav1.StatusCause{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Message: `conflict with "hpa-controller" using autoscaling/v1`,
+		Field:   ".spec.replicas",
+	})
+
+	testCases := map[string]struct {
+		obj               *unstructured.Unstructured
+		clusterObj        *unstructured.Unstructured
+		patchResult       *unstructured.Unstructured
+		patchErr          error
+		strategy          common.DryRunStrategy
+		forceConflicts    bool
+		expectedPatch     bool
+		expectedOperation event.ApplyEventOperation
+		expectedConflicts []event.FieldConflict
+		expectedError     bool
+		expectedFallback  bool
+	}{
+		"new object is created": {
+			obj:               deployment,
+			patchResult:       withVersion(deployment, "1", 1),
+			expectedPatch:     true,
+			expectedOperation: event.Created,
+		},
+		"existing object is configured": {
+			obj:               deployment,
+			clusterObj:        withVersion(deployment, "1", 1),
+			patchResult:       withVersion(deployment, "2", 2),
+			forceConflicts:    true,
+			expectedPatch:     true,
+			expectedOperation: event.Configured,
+		},
+		"existing object is unchanged": {
+			obj:               deployment,
+			clusterObj:        withVersion(deployment, "1", 1),
+			patchResult:       withVersion(deployment, "1", 1),
+			expectedPatch:     true,
+			expectedOperation: event.Unchanged,
+		},
+		"server dry-run": {
+			obj:               deployment,
+			clusterObj:        withVersion(deployment, "1", 1),
+			patchResult:       withVersion(deployment, "1", 1),
+			strategy:          common.DryRunServer,
+			expectedPatch:     true,
+			expectedOperation: event.Unchanged,
+		},
+		"client dry-run doesn't send a patch": {
+			obj:               deployment,
+			strategy:          common.DryRunClient,
+			expectedPatch:     false,
+			expectedOperation: event.Created,
+		},
+		"field conflicts are reported": {
+			obj:           deployment,
+			clusterObj:    withVersion(deployment, "1", 1),
+			patchErr:      conflictErr,
+			expectedPatch: true,
+			expectedConflicts: []event.FieldConflict{
+				{Field: ".spec.replicas", Manager: "hpa-controller"},
+			},
+			expectedError: true,
+		},
+		"APIService falls back to client-side apply on a stream error": {
+			obj: apiService,
+			patchErr: &url.Error{
+				Op:  "Patch",
+				URL: "https://localhost/apis/apiregistration.k8s.io/v1/apiservices/v1beta1.metrics.k8s.io",
+				Err: http2.StreamError{StreamID: 1, Code: http2.ErrCodeInternal},
+			},
+			expectedPatch:    true,
+			expectedFallback: true,
+		},
+		"other objects don't fall back on a stream error": {
+			obj:           deployment,
+			patchErr:      http2.StreamError{StreamID: 1, Code: http2.ErrCodeInternal},
+			expectedPatch: true,
+			expectedError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(scheme.Scheme)
+			var patched bool
+			client.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				patched = true
+				patchAction := action.(clienttesting.PatchAction)
+				assert.Equal(t, types.ApplyPatchType, patchAction.GetPatchType())
+				if tc.patchErr != nil {
+					return true, nil, tc.patchErr
+				}
+				return true, tc.patchResult, nil
+			})
+
+			ao := &fakeApplyOptions{}
+			var ssaOptions []common.ServerSideOptions
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(_ chan event.Event, o common.ServerSideOptions, _ common.DryRunStrategy, _ util.Factory) (applyOptions, dynamic.Interface, error) {
+				ssaOptions = append(ssaOptions, o)
+				return ao, client, nil
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+			oldGetClusterObj := getClusterObj
+			getClusterObj = func(d dynamic.Interface, info *resource.Info) (*unstructured.Unstructured, error) {
+				if tc.clusterObj == nil {
+					return nil, apierrors.NewNotFound(schema.GroupResource{}, info.Name)
+				}
+				return tc.clusterObj, nil
+			}
+			defer func() { getClusterObj = oldGetClusterObj }()
+
+			eventChannel := make(chan event.Event)
+			taskContext := taskrunner.NewTaskContext(context.Background(), eventChannel)
+			applyTask := &ApplyTask{
+				Objects:    []*unstructured.Unstructured{tc.obj},
+				InfoHelper: &fakeInfoHelper{},
+				Mapper: testutil.NewFakeRESTMapper(
+					apps.SchemeGroupVersion.WithKind("Deployment"),
+					apiService.GroupVersionKind(),
+				),
+				DryRunStrategy:  tc.strategy,
+				InvInfo:         &fakeInventoryInfo{},
+				InventoryPolicy: inventory.AdoptIfNoInventory,
+				ServerSideOptions: common.ServerSideOptions{
+					ServerSideApply: true,
+					ForceConflicts:  tc.forceConflicts,
+					FieldManager:    "kapply",
+				},
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			assert.Equal(t, tc.expectedPatch, patched)
+			id := object.UnstructuredToObjMetaOrDie(tc.obj)
+
+			if tc.expectedFallback {
+				assert.Len(t, ao.objects, 1, "expected the object to be applied with client-side apply")
+				assert.False(t, ssaOptions[len(ssaOptions)-1].ServerSideApply)
+				assert.Empty(t, events)
+				return
+			}
+			assert.Empty(t, ao.objects, "expected the kubectl ApplyOptions to not be used")
+
+			require.Len(t, events, 1)
+			e := events[0]
+			assert.Equal(t, event.ApplyType, e.Type)
+			assert.Equal(t, id, e.ApplyEvent.Identifier)
+			if tc.expectedError {
+				assert.Error(t, e.ApplyEvent.Error)
+				assert.Equal(t, tc.expectedConflicts, e.ApplyEvent.Conflicts)
+				assert.True(t, taskContext.ResourceFailed(id))
+				return
+			}
+			assert.NoError(t, e.ApplyEvent.Error)
+			assert.Equal(t, tc.expectedOperation, e.ApplyEvent.Operation)
+			_, found := taskContext.ResourceUID(id)
+			assert.True(t, found, "expected the object to be recorded as applied")
+		})
+	}
+}
+
+func TestIsStreamError(t *testing.T) {
+	streamErr := http2.StreamError{StreamID: 3, Code: http2.ErrCodeInternal}
+	assert.True(t, isStreamError(streamErr))
+	assert.True(t, isStreamError(fmt.Errorf("patch failed: %w", streamErr)))
+	assert.True(t, isStreamError(&url.Error{Op: "Patch", URL: "https://localhost", Err: streamErr}))
+	assert.False(t, isStreamError(fmt.Errorf("stream error: stream ID 3; INTERNAL_ERROR")))
+}
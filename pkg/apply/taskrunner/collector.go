@@ -4,6 +4,7 @@
 package taskrunner
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -38,6 +39,9 @@ type resourceStatus struct {
 	CurrentStatus status.Status
 	Message       string
 	Generation    int64
+	// Resource is the latest polled state of the resource, or
+	// nil if it is not available.
+	Resource *unstructured.Unstructured
 }
 
 // resourceStatus updates the collector with the latest
@@ -47,6 +51,7 @@ func (a *resourceStatusCollector) resourceStatus(r *event.ResourceStatus) {
 		ri.CurrentStatus = r.Status
 		ri.Message = r.Message
 		ri.Generation = getGeneration(r)
+		ri.Resource = r.Resource
 		a.resourceMap[r.Identifier] = ri
 	}
 }
@@ -75,14 +80,24 @@ func (a *resourceStatusCollector) conditionMet(rwd []resourceWaitData, c Conditi
 }
 
 // allMatchStatus checks whether all resources given by the
-// Ids parameter has the provided status.
+// Ids parameter has the provided status. Resources with their own
+// ResourceCondition must meet that condition instead.
 func (a *resourceStatusCollector) allMatchStatus(rwd []resourceWaitData, s status.Status) bool {
 	for _, wd := range rwd {
 		ri, found := a.resourceMap[wd.identifier]
 		if !found {
 			return false
 		}
-		if ri.Generation < wd.generation || ri.CurrentStatus != s {
+		if ri.Generation < wd.generation {
+			return false
+		}
+		if wd.condition != nil {
+			if !wd.condition.Meets(ri.Resource) {
+				return false
+			}
+			continue
+		}
+		if ri.CurrentStatus != s {
 			return false
 		}
 	}
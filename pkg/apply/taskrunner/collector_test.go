@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -31,6 +32,21 @@ func TestCollector_ConditionMet(t *testing.T) {
 		},
 	}
 
+	availableDep := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   "Available",
+						"status": "True",
+					},
+				},
+			},
+		},
+	}
+
 	testCases := map[string]struct {
 		collectorState map[object.ObjMetadata]resourceStatus
 		waitTaskData   []resourceWaitData
@@ -123,6 +139,44 @@ func TestCollector_ConditionMet(t *testing.T) {
 			condition:      AllCurrent,
 			expectedResult: false,
 		},
+		"resource condition met without current status": {
+			collectorState: map[object.ObjMetadata]resourceStatus{
+				identifiers["dep"]: {
+					Identifier:    identifiers["dep"],
+					CurrentStatus: status.InProgressStatus,
+					Generation:    int64(42),
+					Resource:      availableDep,
+				},
+			},
+			waitTaskData: []resourceWaitData{
+				{
+					identifier: identifiers["dep"],
+					generation: int64(42),
+					condition:  conditionTypeCondition{conditionType: "Available"},
+				},
+			},
+			condition:      AllCurrent,
+			expectedResult: true,
+		},
+		"resource condition not met with current status": {
+			collectorState: map[object.ObjMetadata]resourceStatus{
+				identifiers["dep"]: {
+					Identifier:    identifiers["dep"],
+					CurrentStatus: status.CurrentStatus,
+					Generation:    int64(42),
+					Resource:      availableDep,
+				},
+			},
+			waitTaskData: []resourceWaitData{
+				{
+					identifier: identifiers["dep"],
+					generation: int64(42),
+					condition:  conditionTypeCondition{conditionType: "Progressing"},
+				},
+			},
+			condition:      AllCurrent,
+			expectedResult: false,
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -4,26 +4,43 @@
 package taskrunner
 
 import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
-// NewTaskContext returns a new TaskContext
-func NewTaskContext(eventChannel chan event.Event) *TaskContext {
+// NewTaskContext returns a new TaskContext. The passed context is
+// made available to the tasks so they can stop processing objects
+// once it has been cancelled.
+func NewTaskContext(ctx context.Context, eventChannel chan event.Event) *TaskContext {
 	return &TaskContext{
+		ctx:              ctx,
 		taskChannel:      make(chan TaskResult),
 		eventChannel:     eventChannel,
 		appliedResources: make(map[object.ObjMetadata]applyInfo),
 		failedResources:  make(map[object.ObjMetadata]struct{}),
 		pruneFailures:    make(map[object.ObjMetadata]struct{}),
+		previousObjects:  make(map[object.ObjMetadata]*unstructured.Unstructured),
+		rolledBack:       make(map[object.ObjMetadata]struct{}),
+		resourceStatuses: make(map[object.ObjMetadata]status.Status),
 	}
 }
 
 // TaskContext defines a context that is passed between all
-// the tasks that is in a taskqueue.
+// the tasks that is in a taskqueue. It is safe for concurrent
+// use, so a task can process several objects in parallel.
 type TaskContext struct {
+	ctx context.Context
+
+	// mu guards the maps below.
+	mu sync.RWMutex
+
 	taskChannel chan TaskResult
 
 	eventChannel chan event.Event
@@ -35,6 +52,31 @@ type TaskContext struct {
 
 	// pruneFailures records the IDs of resources that are failed during pruning.
 	pruneFailures map[object.ObjMetadata]struct{}
+
+	// previousObjects records the state of resources in the cluster
+	// before they were applied. A nil value means the resource did
+	// not exist. This is only captured when rollback is enabled.
+	previousObjects map[object.ObjMetadata]*unstructured.Unstructured
+
+	// rolledBack records the IDs of resources that have been rolled
+	// back to their previous state.
+	rolledBack map[object.ObjMetadata]struct{}
+
+	// resourceStatuses records the last observed status of resources.
+	resourceStatuses map[object.ObjMetadata]status.Status
+}
+
+// Context returns the context for the task run. Tasks should check
+// whether it has been cancelled between objects, and skip any
+// remaining work if it has.
+func (tc *TaskContext) Context() context.Context {
+	return tc.ctx
+}
+
+// Cancelled returns true if the context for the task run has
+// been cancelled or has exceeded its deadline.
+func (tc *TaskContext) Cancelled() bool {
+	return tc.ctx.Err() != nil
 }
 
 func (tc *TaskContext) TaskChannel() chan TaskResult {
@@ -49,6 +91,8 @@ func (tc *TaskContext) EventChannel() chan event.Event {
 // resource identified by the provided id. Currently, we keep information
 // about the generation of the resource after the apply operation completed.
 func (tc *TaskContext) ResourceApplied(id object.ObjMetadata, uid types.UID, gen int64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 	tc.appliedResources[id] = applyInfo{
 		generation: gen,
 		uid:        uid,
@@ -57,6 +101,8 @@ func (tc *TaskContext) ResourceApplied(id object.ObjMetadata, uid types.UID, gen
 
 // ResourceUID looks up the UID of the given resource
 func (tc *TaskContext) ResourceUID(id object.ObjMetadata) (types.UID, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	ai, found := tc.appliedResources[id]
 	if !found {
 		return "", false
@@ -67,6 +113,8 @@ func (tc *TaskContext) ResourceUID(id object.ObjMetadata) (types.UID, bool) {
 // AppliedResources returns all the objects (as ObjMetadata) that
 // were added as applied resources to the TaskContext.
 func (tc *TaskContext) AppliedResources() []object.ObjMetadata {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	all := make([]object.ObjMetadata, 0, len(tc.appliedResources))
 	for r := range tc.appliedResources {
 		all = append(all, r)
@@ -77,6 +125,8 @@ func (tc *TaskContext) AppliedResources() []object.ObjMetadata {
 // AppliedResourceUIDs returns a set with the UIDs of all the
 // successfully applied resources.
 func (tc *TaskContext) AppliedResourceUIDs() sets.String {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	uids := sets.NewString()
 	for _, ai := range tc.appliedResources {
 		uid := string(ai.uid)
@@ -90,6 +140,8 @@ func (tc *TaskContext) AppliedResourceUIDs() sets.String {
 // ResourceGeneration looks up the generation of the given resource
 // after it was applied.
 func (tc *TaskContext) ResourceGeneration(id object.ObjMetadata) (int64, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	ai, found := tc.appliedResources[id]
 	if !found {
 		return 0, false
@@ -98,15 +150,21 @@ func (tc *TaskContext) ResourceGeneration(id object.ObjMetadata) (int64, bool) {
 }
 
 func (tc *TaskContext) ResourceFailed(id object.ObjMetadata) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	_, found := tc.failedResources[id]
 	return found
 }
 
 func (tc *TaskContext) CaptureResourceFailure(id object.ObjMetadata) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 	tc.failedResources[id] = struct{}{}
 }
 
 func (tc *TaskContext) ResourceFailures() []object.ObjMetadata {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	failures := make([]object.ObjMetadata, 0, len(tc.failedResources))
 	for f := range tc.failedResources {
 		failures = append(failures, f)
@@ -115,10 +173,14 @@ func (tc *TaskContext) ResourceFailures() []object.ObjMetadata {
 }
 
 func (tc *TaskContext) CapturePruneFailure(id object.ObjMetadata) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 	tc.pruneFailures[id] = struct{}{}
 }
 
 func (tc *TaskContext) PruneFailures() []object.ObjMetadata {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	failures := make([]object.ObjMetadata, 0, len(tc.pruneFailures))
 	for f := range tc.pruneFailures {
 		failures = append(failures, f)
@@ -129,10 +191,67 @@ func (tc *TaskContext) PruneFailures() []object.ObjMetadata {
 // PruneFailed returns true if the passed object identifier
 // has been stored as a prune failure; false otherwise.
 func (tc *TaskContext) PruneFailed(id object.ObjMetadata) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	_, found := tc.pruneFailures[id]
 	return found
 }
 
+// CapturePreviousObject records the state of the resource in the
+// cluster before it was applied. The passed obj should be nil if the
+// resource did not exist in the cluster.
+func (tc *TaskContext) CapturePreviousObject(id object.ObjMetadata, obj *unstructured.Unstructured) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.previousObjects[id] = obj
+}
+
+// PreviousObject looks up the state of the resource in the cluster
+// before it was applied. The second return value is false if no
+// previous state has been captured for the resource. If the first
+// return value is nil while the second is true, the resource did
+// not exist before it was applied.
+func (tc *TaskContext) PreviousObject(id object.ObjMetadata) (*unstructured.Unstructured, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	obj, found := tc.previousObjects[id]
+	return obj, found
+}
+
+// CaptureResourceRollback records that the resource has been
+// rolled back to its previous state.
+func (tc *TaskContext) CaptureResourceRollback(id object.ObjMetadata) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.rolledBack[id] = struct{}{}
+}
+
+// ResourceRolledBack returns true if the passed object identifier
+// has been rolled back to its previous state; false otherwise.
+func (tc *TaskContext) ResourceRolledBack(id object.ObjMetadata) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	_, found := tc.rolledBack[id]
+	return found
+}
+
+// CaptureResourceStatus records the last observed status of the
+// resource.
+func (tc *TaskContext) CaptureResourceStatus(id object.ObjMetadata, s status.Status) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.resourceStatuses[id] = s
+}
+
+// ResourceStatus looks up the last observed status of the resource.
+// The second return value is false if no status has been observed.
+func (tc *TaskContext) ResourceStatus(id object.ObjMetadata) (status.Status, bool) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	s, found := tc.resourceStatuses[id]
+	return s, found
+}
+
 // applyInfo captures information about resources that have been
 // applied. This is captured in the TaskContext so other tasks
 // running later might use this information.
@@ -8,9 +8,12 @@ import (
 	"fmt"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -42,6 +45,13 @@ type Options struct {
 	PollInterval     time.Duration
 	UseCache         bool
 	EmitStatusEvents bool
+	// RollbackQueue contains the tasks that should be run if one of
+	// the tasks in the task queue fails. If it is nil, the processing
+	// of tasks ends as soon as a task fails.
+	RollbackQueue chan Task
+	// CustomStatusReadersFactoryFunc is passed on to the statusPoller,
+	// to compute the status of some resources with custom StatusReaders.
+	CustomStatusReadersFactoryFunc func(engine.ClusterReader, meta.RESTMapper) map[schema.GroupKind]engine.StatusReader
 }
 
 // Run starts the execution of the taskqueue. It will start the
@@ -53,10 +63,13 @@ func (tsr *taskStatusRunner) Run(ctx context.Context, taskQueue chan Task,
 	statusChannel := tsr.statusPoller.Poll(statusCtx, tsr.identifiers, polling.Options{
 		PollInterval: options.PollInterval,
 		UseCache:     options.UseCache,
+
+		CustomStatusReadersFactoryFunc: options.CustomStatusReadersFactoryFunc,
 	})
 
 	o := baseOptions{
 		emitStatusEvents: options.EmitStatusEvents,
+		rollbackQueue:    options.RollbackQueue,
 	}
 	err := tsr.baseRunner.run(ctx, taskQueue, statusChannel, eventChannel, o)
 	// cancel the statusPoller by cancelling the context.
@@ -68,6 +81,13 @@ func (tsr *taskStatusRunner) Run(ctx context.Context, taskQueue chan Task,
 	return err
 }
 
+// TaskContext returns the TaskContext of the last run, so the results
+// of the tasks can be inspected once Run has returned. It returns nil
+// if Run hasn't been called.
+func (tsr *taskStatusRunner) TaskContext() *TaskContext {
+	return tsr.baseRunner.taskContext
+}
+
 // NewTaskRunner returns a new taskRunner. It can process taskqueues
 // that does not contain any wait tasks.
 func NewTaskRunner() *taskRunner {
@@ -113,10 +133,14 @@ func newBaseRunner(collector *resourceStatusCollector) *baseRunner {
 // taskRunner and the taskStatusRunner.
 type baseRunner struct {
 	collector *resourceStatusCollector
+
+	// taskContext is the TaskContext of the last run.
+	taskContext *TaskContext
 }
 
 type baseOptions struct {
 	emitStatusEvents bool
+	rollbackQueue    chan Task
 }
 
 // run is the main function that implements the processing of
@@ -128,12 +152,13 @@ func (b *baseRunner) run(ctx context.Context, taskQueue chan Task,
 	// taskContext is passed into all tasks when they are started. It
 	// provides access to the eventChannel and the taskChannel, and
 	// also provides a way to pass data between tasks.
-	taskContext := NewTaskContext(eventChannel)
+	taskContext := NewTaskContext(ctx, eventChannel)
+	b.taskContext = taskContext
 
 	// Find and start the first task in the queue.
 	currentTask, done := b.nextTask(taskQueue, taskContext)
 	if done {
-		return nil
+		return ctx.Err()
 	}
 
 	// abort is used to signal that something has failed, and
@@ -144,6 +169,10 @@ func (b *baseRunner) run(ctx context.Context, taskQueue chan Task,
 	abort := false
 	var abortReason error
 
+	// rollbackReason is set to the error from the failed task once
+	// we have switched to processing the tasks in the rollback queue.
+	var rollbackReason error
+
 	// We do this so we can set the doneCh to a nil channel after
 	// it has been closed. This is needed to avoid a busy loop.
 	doneCh := ctx.Done()
@@ -195,6 +224,9 @@ func (b *baseRunner) run(ctx context.Context, taskQueue chan Task,
 			// for all resources so we can check whether wait task conditions
 			// has been met.
 			b.collector.resourceStatus(statusEvent.Resource)
+			// The latest status is also recorded in the task context, so
+			// it can be stored in the inventory.
+			taskContext.CaptureResourceStatus(statusEvent.Resource.Identifier, statusEvent.Resource.Status)
 			// If the current task is a wait task, we check whether
 			// the condition has been met. If so, we complete the task.
 			if wt, ok := currentTask.(*WaitTask); ok {
@@ -220,23 +252,41 @@ func (b *baseRunner) run(ctx context.Context, taskQueue chan Task,
 			}
 			if msg.Err != nil {
 				b.amendTimeoutError(msg.Err)
-				return msg.Err
+				// If a rollback queue has been provided, we skip the
+				// remaining tasks and process the rollback tasks instead.
+				// The error from the failed task is returned once all
+				// the rollback tasks have completed.
+				if o.rollbackQueue == nil || rollbackReason != nil {
+					return msg.Err
+				}
+				rollbackReason = msg.Err
+				taskQueue = o.rollbackQueue
 			}
 			if abort {
 				return abortReason
 			}
 			currentTask, done = b.nextTask(taskQueue, taskContext)
-			// If there are no more tasks, we are done. So just
-			// return.
+			// If there are no more tasks, we are done. If the tasks
+			// were rolled back or the context was cancelled along the
+			// way, we return the error so the caller knows the run
+			// didn't complete.
 			if done {
-				return nil
+				if rollbackReason != nil {
+					return rollbackReason
+				}
+				return ctx.Err()
 			}
 		// The doneCh will be closed if the passed in context is cancelled.
-		// If so, we just set the abort flag and wait for the currently running
-		// task to complete before we exit.
+		// If so, we complete the current task if it is a wait task. Any
+		// other task will notice the cancellation and finish early.
+		//
+		// Cancellation of the context is not handled as an abort. The
+		// remaining tasks are still started, but they are expected to
+		// check the context and skip their work. This makes sure tasks
+		// that update the inventory still get to record the objects
+		// that were actually applied or pruned.
 		case <-doneCh:
 			doneCh = nil // Set doneCh to nil so we don't enter a busy loop.
-			abort = true
 			completeIfWaitTask(currentTask, taskContext)
 		}
 	}
@@ -250,7 +300,12 @@ func (b *baseRunner) amendTimeoutError(err error) {
 			if !found {
 				continue
 			}
-			if timeoutErr.Condition.Meets(ls.CurrentStatus) {
+			if cond, found := timeoutErr.ResourceConditions[id]; found &&
+				timeoutErr.Condition == AllCurrent {
+				if cond.Meets(ls.Resource) {
+					continue
+				}
+			} else if timeoutErr.Condition.Meets(ls.CurrentStatus) {
 				continue
 			}
 			timedOutResources = append(timedOutResources, TimedOutResource{
@@ -302,7 +357,9 @@ func (b *baseRunner) nextTask(taskQueue chan Task,
 		// starting a new wait task, we check if the condition is already
 		// met. Without this check, a task might end up waiting for
 		// status events when the condition is in fact already met.
-		if st.checkCondition(taskContext, b.collector) {
+		// If the context has been cancelled, there is no point in
+		// waiting, so the task is completed right away.
+		if taskContext.Cancelled() || st.checkCondition(taskContext, b.collector) {
 			st.startAndComplete(taskContext)
 		} else {
 			st.Start(taskContext)
@@ -333,6 +390,10 @@ type TimeoutError struct {
 	// Condition defines the criteria for which the task was waiting.
 	Condition Condition
 
+	// ResourceConditions contains the conditions that override
+	// Condition for individual resources.
+	ResourceConditions map[object.ObjMetadata]ResourceCondition
+
 	TimedOutResources []TimedOutResource
 }
 
@@ -264,10 +264,14 @@ func TestBaseRunnerCancellation(t *testing.T) {
 				},
 			},
 			contextTimeout: 2 * time.Second,
+			expectedError:  context.DeadlineExceeded,
 			expectedEventTypes: []event.Type{
 				event.ActionGroupType,
 				event.ApplyType,
 				event.ActionGroupType,
+				event.ActionGroupType,
+				event.PruneType,
+				event.ActionGroupType,
 			},
 		},
 		"cancellation while wait task is running": {
@@ -283,7 +287,33 @@ func TestBaseRunnerCancellation(t *testing.T) {
 				},
 			},
 			contextTimeout: 2 * time.Second,
+			expectedError:  context.DeadlineExceeded,
+			expectedEventTypes: []event.Type{
+				event.ActionGroupType,
+				event.ActionGroupType,
+				event.ActionGroupType,
+				event.PruneType,
+				event.ActionGroupType,
+			},
+		},
+		"cancellation completes remaining wait tasks": {
+			identifiers: []object.ObjMetadata{depID},
+			tasks: []Task{
+				&fakeApplyTask{
+					resultEvent: event.Event{
+						Type: event.ApplyType,
+					},
+					duration: 4 * time.Second,
+				},
+				NewWaitTask("wait", []object.ObjMetadata{depID}, AllCurrent,
+					20*time.Second, testutil.NewFakeRESTMapper()),
+			},
+			contextTimeout: 2 * time.Second,
+			expectedError:  context.DeadlineExceeded,
 			expectedEventTypes: []event.Type{
+				event.ActionGroupType,
+				event.ApplyType,
+				event.ActionGroupType,
 				event.ActionGroupType,
 				event.ActionGroupType,
 			},
@@ -392,6 +422,10 @@ func TestBaseRunnerCancellation(t *testing.T) {
 				t.Errorf("expected error %v, but didn't get one", tc.expectedError)
 			}
 
+			if tc.expectedError == context.DeadlineExceeded && err != context.DeadlineExceeded {
+				t.Errorf("expected error %v, but got %v", tc.expectedError, err)
+			}
+
 			if want, got := len(tc.expectedEventTypes), len(events); want != got {
 				t.Errorf("expected %d events, but got %d", want, got)
 			}
@@ -406,6 +440,63 @@ func TestBaseRunnerCancellation(t *testing.T) {
 	}
 }
 
+func TestBaseRunnerRollback(t *testing.T) {
+	testError := fmt.Errorf("this is a test error")
+
+	taskQueue := make(chan Task, 2)
+	taskQueue <- &fakeApplyTask{
+		resultEvent: event.Event{
+			Type: event.ApplyType,
+		},
+		err: testError,
+	}
+	taskQueue <- &fakeApplyTask{
+		resultEvent: event.Event{
+			Type: event.PruneType,
+		},
+	}
+	rollbackQueue := make(chan Task, 1)
+	rollbackQueue <- &fakeApplyTask{
+		resultEvent: event.Event{
+			Type: event.RollbackType,
+		},
+	}
+
+	runner := newBaseRunner(newResourceStatusCollector([]object.ObjMetadata{}))
+	eventChannel := make(chan event.Event)
+	var events []event.Event
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range eventChannel {
+			events = append(events, msg)
+		}
+	}()
+
+	var nilStatusChannel chan pollevent.Event
+	err := runner.run(context.Background(), taskQueue, nilStatusChannel, eventChannel,
+		baseOptions{rollbackQueue: rollbackQueue})
+	close(eventChannel)
+	wg.Wait()
+
+	// The error from the failed task is returned after the rollback
+	// tasks have completed, and the remaining tasks are skipped.
+	assert.Equal(t, testError, err)
+	var eventTypes []event.Type
+	for _, e := range events {
+		eventTypes = append(eventTypes, e.Type)
+	}
+	assert.Equal(t, []event.Type{
+		event.ActionGroupType,
+		event.ApplyType,
+		event.ActionGroupType,
+		event.ActionGroupType,
+		event.RollbackType,
+		event.ActionGroupType,
+	}, eventTypes)
+}
+
 type fakeApplyTask struct {
 	name        string
 	resultEvent event.Event
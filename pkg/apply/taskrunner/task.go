@@ -63,6 +63,10 @@ type WaitTask struct {
 	// Timeout defines how long we are willing to wait for the condition
 	// to be met.
 	Timeout time.Duration
+	// ResourceConditions optionally overrides the Condition for
+	// individual resources. It is only used with the AllCurrent
+	// Condition.
+	ResourceConditions map[object.ObjMetadata]ResourceCondition
 
 	mapper meta.RESTMapper
 
@@ -114,9 +118,10 @@ func (w *WaitTask) setTimer(taskContext *TaskContext) {
 		case <-w.token:
 			taskContext.TaskChannel() <- TaskResult{
 				Err: &TimeoutError{
-					Identifiers: w.Ids,
-					Timeout:     w.Timeout,
-					Condition:   w.Condition,
+					Identifiers:        w.Ids,
+					Timeout:            w.Timeout,
+					Condition:          w.Condition,
+					ResourceConditions: w.ResourceConditions,
 				},
 			}
 		default:
@@ -149,15 +154,30 @@ func (w *WaitTask) computeResourceWaitData(taskContext *TaskContext) []resourceW
 			(w.Condition == AllNotFound && taskContext.PruneFailed(id)) {
 			continue
 		}
+		cond := w.resourceCondition(id)
+		// Resources that should not be waited for are also skipped.
+		if cond == NoWait {
+			continue
+		}
 		gen, _ := taskContext.ResourceGeneration(id)
 		rwd = append(rwd, resourceWaitData{
 			identifier: id,
 			generation: gen,
+			condition:  cond,
 		})
 	}
 	return rwd
 }
 
+// resourceCondition returns the ResourceCondition for the given
+// resource, or nil if the Condition of the task should be used.
+func (w *WaitTask) resourceCondition(id object.ObjMetadata) ResourceCondition {
+	if w.Condition != AllCurrent {
+		return nil
+	}
+	return w.ResourceConditions[id]
+}
+
 // startAndComplete is invoked when the condition is already
 // met when the task should be started. In this case there is no
 // need to start a timer. So it just sets the cancelFunc and then
@@ -206,6 +226,9 @@ func (w *WaitTask) ClearTimeout() {
 type resourceWaitData struct {
 	identifier object.ObjMetadata
 	generation int64
+	// condition overrides the condition of the WaitTask
+	// for this resource if it is not nil.
+	condition ResourceCondition
 }
 
 // Condition is a type that defines the types of conditions
@@ -4,6 +4,7 @@
 package taskrunner
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -18,7 +19,7 @@ func TestWaitTask_TimeoutTriggered(t *testing.T) {
 		2*time.Second, testutil.NewFakeRESTMapper())
 
 	eventChannel := make(chan event.Event)
-	taskContext := NewTaskContext(eventChannel)
+	taskContext := NewTaskContext(context.Background(), eventChannel)
 	defer close(eventChannel)
 
 	task.Start(taskContext)
@@ -41,7 +42,7 @@ func TestWaitTask_TimeoutCancelled(t *testing.T) {
 		2*time.Second, testutil.NewFakeRESTMapper())
 
 	eventChannel := make(chan event.Event)
-	taskContext := NewTaskContext(eventChannel)
+	taskContext := NewTaskContext(context.Background(), eventChannel)
 	defer close(eventChannel)
 
 	task.Start(taskContext)
@@ -61,7 +62,7 @@ func TestWaitTask_SingleTaskResult(t *testing.T) {
 		2*time.Second, testutil.NewFakeRESTMapper())
 
 	eventChannel := make(chan event.Event)
-	taskContext := NewTaskContext(eventChannel)
+	taskContext := NewTaskContext(context.Background(), eventChannel)
 	taskContext.taskChannel = make(chan TaskResult, 10)
 	defer close(eventChannel)
 
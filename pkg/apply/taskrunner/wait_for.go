@@ -0,0 +1,157 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	waitForNone          = "none"
+	waitForConditionType = "condition="
+	waitForJSONPath      = "jsonpath="
+)
+
+// ResourceCondition is a condition that a single resource must
+// meet. It is evaluated against the latest polled state of the
+// resource, and is used instead of the Condition of a WaitTask
+// for resources that specify their own condition with the
+// wait-for annotation.
+type ResourceCondition interface {
+	// Meets returns true if the provided resource meets the condition.
+	Meets(obj *unstructured.Unstructured) bool
+	String() string
+}
+
+// NoWait is the ResourceCondition for resources that should not
+// be waited for at all.
+var NoWait ResourceCondition = noWaitCondition{}
+
+type noWaitCondition struct{}
+
+func (n noWaitCondition) Meets(_ *unstructured.Unstructured) bool {
+	return true
+}
+
+func (n noWaitCondition) String() string {
+	return waitForNone
+}
+
+// conditionTypeCondition is met when the resource has a condition
+// of the given type with the status True.
+type conditionTypeCondition struct {
+	conditionType string
+}
+
+func (c conditionTypeCondition) Meets(obj *unstructured.Unstructured) bool {
+	if obj == nil {
+		return false
+	}
+	objWithConditions, err := status.GetObjectWithConditions(obj.Object)
+	if err != nil {
+		return false
+	}
+	for _, cond := range objWithConditions.Status.Conditions {
+		if cond.Type == c.conditionType && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (c conditionTypeCondition) String() string {
+	return waitForConditionType + c.conditionType
+}
+
+// jsonPathCondition is met when the field given by the JSONPath
+// expression has the expected value.
+type jsonPathCondition struct {
+	expression string
+	value      string
+	parser     *jsonpath.JSONPath
+}
+
+func (j jsonPathCondition) Meets(obj *unstructured.Unstructured) bool {
+	if obj == nil {
+		return false
+	}
+	results, err := j.parser.FindResults(obj.Object)
+	if err != nil {
+		return false
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		return false
+	}
+	return fmt.Sprint(results[0][0].Interface()) == j.value
+}
+
+func (j jsonPathCondition) String() string {
+	return fmt.Sprintf("%s%s=%s", waitForJSONPath, j.expression, j.value)
+}
+
+// ParseResourceCondition parses the value of the wait-for annotation
+// into a ResourceCondition.
+func ParseResourceCondition(value string) (ResourceCondition, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == waitForNone:
+		return NoWait, nil
+	case strings.HasPrefix(value, waitForConditionType):
+		conditionType := strings.TrimPrefix(value, waitForConditionType)
+		if conditionType == "" {
+			return nil, fmt.Errorf("wait-for condition is missing the condition type: %q", value)
+		}
+		return conditionTypeCondition{conditionType: conditionType}, nil
+	case strings.HasPrefix(value, waitForJSONPath):
+		return parseJSONPathCondition(value)
+	default:
+		return nil, fmt.Errorf("unable to parse wait-for annotation: %q", value)
+	}
+}
+
+// parseJSONPathCondition parses a value on the form
+// jsonpath={<expression>}=<value>.
+func parseJSONPathCondition(value string) (ResourceCondition, error) {
+	spec := strings.TrimPrefix(value, waitForJSONPath)
+	if !strings.HasPrefix(spec, "{") {
+		return nil, fmt.Errorf("wait-for jsonpath expression must be enclosed in braces: %q", value)
+	}
+	end := strings.Index(spec, "}=")
+	if end < 0 {
+		return nil, fmt.Errorf("wait-for jsonpath is missing the expected value: %q", value)
+	}
+	expression := spec[:end+1]
+	parser := jsonpath.New("wait-for")
+	if err := parser.Parse(expression); err != nil {
+		return nil, fmt.Errorf("unable to parse wait-for jsonpath %q: %w", expression, err)
+	}
+	return jsonPathCondition{
+		expression: expression,
+		value:      spec[end+2:],
+		parser:     parser,
+	}, nil
+}
+
+// ResourceConditionFromObject returns the ResourceCondition given by the
+// wait-for annotation on the object, or nil if the object doesn't
+// have the annotation.
+func ResourceConditionFromObject(u *unstructured.Unstructured) (ResourceCondition, error) {
+	value, found := object.HasAnnotation(u, object.WaitForAnnotation)
+	if !found {
+		return nil, nil
+	}
+	cond, err := ParseResourceCondition(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation on %s/%s: %w",
+			object.WaitForAnnotation, u.GetNamespace(), u.GetName(), err)
+	}
+	return cond, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var waitForPod = &unstructured.Unstructured{
+	Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "foo",
+			"namespace": "default",
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":   "Ready",
+					"status": "True",
+				},
+				map[string]interface{}{
+					"type":   "ContainersReady",
+					"status": "False",
+				},
+			},
+		},
+	},
+}
+
+func TestParseResourceCondition(t *testing.T) {
+	testCases := map[string]struct {
+		value       string
+		expected    string
+		expectedErr bool
+	}{
+		"none": {
+			value:    "none",
+			expected: "none",
+		},
+		"condition type": {
+			value:    "condition=Ready",
+			expected: "condition=Ready",
+		},
+		"jsonpath": {
+			value:    " jsonpath={.status.phase}=Running ",
+			expected: "jsonpath={.status.phase}=Running",
+		},
+		"jsonpath with empty value": {
+			value:    "jsonpath={.status.phase}=",
+			expected: "jsonpath={.status.phase}=",
+		},
+		"missing condition type": {
+			value:       "condition=",
+			expectedErr: true,
+		},
+		"jsonpath without braces": {
+			value:       "jsonpath=.status.phase=Running",
+			expectedErr: true,
+		},
+		"jsonpath without value": {
+			value:       "jsonpath={.status.phase}",
+			expectedErr: true,
+		},
+		"invalid jsonpath": {
+			value:       "jsonpath={.status[}=Running",
+			expectedErr: true,
+		},
+		"unknown format": {
+			value:       "delete",
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			cond, err := ParseResourceCondition(tc.value)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, cond.String())
+		})
+	}
+}
+
+func TestResourceCondition_Meets(t *testing.T) {
+	testCases := map[string]struct {
+		value    string
+		obj      *unstructured.Unstructured
+		expected bool
+	}{
+		"none is always met": {
+			value:    "none",
+			obj:      nil,
+			expected: true,
+		},
+		"condition is true": {
+			value:    "condition=Ready",
+			obj:      waitForPod,
+			expected: true,
+		},
+		"condition is false": {
+			value:    "condition=ContainersReady",
+			obj:      waitForPod,
+			expected: false,
+		},
+		"condition is missing": {
+			value:    "condition=Initialized",
+			obj:      waitForPod,
+			expected: false,
+		},
+		"condition without resource": {
+			value:    "condition=Ready",
+			obj:      nil,
+			expected: false,
+		},
+		"jsonpath matches": {
+			value:    "jsonpath={.status.phase}=Running",
+			obj:      waitForPod,
+			expected: true,
+		},
+		"jsonpath does not match": {
+			value:    "jsonpath={.status.phase}=Succeeded",
+			obj:      waitForPod,
+			expected: false,
+		},
+		"jsonpath field is missing": {
+			value:    "jsonpath={.status.podIP}=10.0.0.1",
+			obj:      waitForPod,
+			expected: false,
+		},
+		"jsonpath without resource": {
+			value:    "jsonpath={.status.phase}=Running",
+			obj:      nil,
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			cond, err := ParseResourceCondition(tc.value)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expected, cond.Meets(tc.obj))
+		})
+	}
+}
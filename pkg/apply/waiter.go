@@ -0,0 +1,158 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
+	"sigs.k8s.io/cli-utils/pkg/apply/poller"
+	"sigs.k8s.io/cli-utils/pkg/apply/solver"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// NewWaiter returns a new Waiter.
+func NewWaiter(factory cmdutil.Factory, invClient inventory.InventoryClient, statusPoller poller.Poller) (*Waiter, error) {
+	return &Waiter{
+		statusPoller: statusPoller,
+		factory:      factory,
+		invClient:    invClient,
+	}, nil
+}
+
+// Waiter waits for a set of resources to reach a condition, without
+// applying or pruning anything. This is useful when the resources have
+// been applied by some other tool. The Waiter uses the same task runner
+// as the Applier, with a task queue that only contains a wait task.
+type Waiter struct {
+	statusPoller poller.Poller
+	factory      cmdutil.Factory
+	invClient    inventory.InventoryClient
+}
+
+// WaitOptions defines the configuration of a Waiter run.
+type WaitOptions struct {
+	// Condition is the condition the resources must reach. If this is
+	// not provided, the default is AllCurrent.
+	Condition taskrunner.Condition
+
+	// Timeout defines how long to wait for the resources to reach the
+	// condition. If this is not provided, DefaultWaitTimeout is used.
+	Timeout time.Duration
+
+	// PollInterval defines how often we should poll for the status
+	// of resources.
+	PollInterval time.Duration
+
+	// EmitStatusEvents defines whether status events should be
+	// emitted on the eventChannel to the caller.
+	EmitStatusEvents bool
+}
+
+// DefaultWaitTimeout is the default time to wait for resources to reach
+// the condition.
+const DefaultWaitTimeout = 5 * time.Minute
+
+func setWaitDefaults(o *WaitOptions) {
+	if o.Condition == "" {
+		o.Condition = taskrunner.AllCurrent
+	}
+	if o.Timeout == time.Duration(0) {
+		o.Timeout = DefaultWaitTimeout
+	}
+	if o.PollInterval == time.Duration(0) {
+		o.PollInterval = poller.DefaultPollInterval
+	}
+}
+
+// Run waits for the passed objects to reach the condition. Hooks are
+// not waited for. If no objects are passed, it waits for all the
+// objects in the inventory instead. Progress and any errors are
+// reported back on the event channel. If the resources don't reach the condition before the
+// timeout, the last event on the channel is an error event with a
+// TimeoutError.
+func (w *Waiter) Run(ctx context.Context, invInfo inventory.InventoryInfo, objects []*unstructured.Unstructured,
+	options WaitOptions) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+	setWaitDefaults(&options)
+	go func() {
+		defer close(eventChannel)
+		if options.Condition != taskrunner.AllCurrent && options.Condition != taskrunner.AllNotFound {
+			handleError(eventChannel, fmt.Errorf("unknown wait condition %q", options.Condition))
+			return
+		}
+		mapper, err := w.factory.ToRESTMapper()
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		// The inventory is only waited for if no objects are passed. A
+		// package with only hooks has nothing to wait for.
+		fromInventory := len(objects) == 0
+		// Hooks are not applied with the other objects, so they are
+		// not waited for either.
+		_, objects, err := hook.Split(objects)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		taskBuilder := &solver.TaskQueueBuilder{
+			Mapper: mapper,
+		}
+		ids, err := w.waitIds(invInfo, objects, fromInventory)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		if fromInventory {
+			taskBuilder.AppendWaitTask(ids, options.Condition, options.Timeout)
+		} else {
+			taskBuilder.AppendObjsWaitTask(objects, options.Condition, options.Timeout)
+		}
+		taskQueue, err := taskBuilder.Build()
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		klog.V(4).Infof("waiter waiting for %d objects to reach condition %s", len(ids), options.Condition)
+		eventChannel <- event.Event{
+			Type: event.InitType,
+			InitEvent: event.InitEvent{
+				ActionGroups: taskQueue.ToActionGroups(),
+			},
+		}
+		runner := taskrunner.NewTaskStatusRunner(ids, w.statusPoller)
+		err = runner.Run(ctx, taskQueue.ToChannel(), eventChannel, taskrunner.Options{
+			PollInterval:     options.PollInterval,
+			UseCache:         true,
+			EmitStatusEvents: options.EmitStatusEvents,
+		})
+		if err != nil {
+			handleError(eventChannel, err)
+		}
+	}()
+	return eventChannel
+}
+
+// waitIds returns the identifiers of the passed objects, or of the
+// objects in the inventory if fromInventory is true.
+func (w *Waiter) waitIds(invInfo inventory.InventoryInfo, objects []*unstructured.Unstructured,
+	fromInventory bool) ([]object.ObjMetadata, error) {
+	if !fromInventory {
+		return object.UnstructuredsToObjMetas(objects)
+	}
+	if invInfo == nil {
+		return nil, fmt.Errorf("the inventory can't be nil when no objects are provided")
+	}
+	return w.invClient.GetClusterObjs(invInfo, common.DryRunNone)
+}
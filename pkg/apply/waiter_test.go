@@ -0,0 +1,153 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/hook"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func statusEvent(obj *unstructured.Unstructured, s status.Status) pollevent.Event {
+	return pollevent.Event{
+		EventType: pollevent.ResourceUpdateEvent,
+		Resource: &pollevent.ResourceStatus{
+			Identifier: object.UnstructuredToObjMetaOrDie(obj),
+			Status:     s,
+		},
+	}
+}
+
+var hookObj = &unstructured.Unstructured{
+	Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      "hook",
+			"namespace": namespace,
+			"annotations": map[string]interface{}{
+				hook.Annotation: string(hook.PreApply),
+			},
+		},
+	},
+}
+
+func TestWaiter_Run(t *testing.T) {
+	testCases := map[string]struct {
+		objects       []*unstructured.Unstructured
+		invObjs       []object.ObjMetadata
+		invErr        error
+		options       WaitOptions
+		statusEvents  []pollevent.Event
+		expectedIds   []object.ObjMetadata
+		expectedErr   string
+		expectTimeout bool
+	}{
+		"local objects become current": {
+			objects: []*unstructured.Unstructured{obj1, obj2},
+			invObjs: object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{clusterScopedObj}),
+			statusEvents: []pollevent.Event{
+				statusEvent(obj1, status.CurrentStatus),
+				statusEvent(obj2, status.CurrentStatus),
+			},
+			expectedIds: object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{obj1, obj2}),
+		},
+		"inventory objects are deleted": {
+			invObjs: object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{obj1}),
+			options: WaitOptions{
+				Condition: taskrunner.AllNotFound,
+			},
+			statusEvents: []pollevent.Event{
+				statusEvent(obj1, status.NotFoundStatus),
+			},
+			expectedIds: object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{obj1}),
+		},
+		"package with only hooks": {
+			objects:     []*unstructured.Unstructured{hookObj},
+			invObjs:     object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{obj1}),
+			expectedIds: []object.ObjMetadata{},
+		},
+		"timeout": {
+			objects: []*unstructured.Unstructured{obj1},
+			options: WaitOptions{
+				Timeout: 100 * time.Millisecond,
+			},
+			statusEvents: []pollevent.Event{
+				statusEvent(obj1, status.InProgressStatus),
+			},
+			expectedIds:   object.UnstructuredsToObjMetasOrDie([]*unstructured.Unstructured{obj1}),
+			expectTimeout: true,
+		},
+		"unknown condition": {
+			objects: []*unstructured.Unstructured{obj1},
+			options: WaitOptions{
+				Condition: "Unknown",
+			},
+			expectedErr: `unknown wait condition "Unknown"`,
+		},
+		"inventory error": {
+			invErr:      fmt.Errorf("inventory not found"),
+			expectedErr: "inventory not found",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace(namespace)
+			defer tf.Cleanup()
+
+			invClient := inventory.NewFakeInventoryClient(tc.invObjs)
+			invClient.Err = tc.invErr
+			poller := &fakePoller{
+				events: tc.statusEvents,
+				start:  make(chan struct{}),
+			}
+			close(poller.start)
+			waiter, err := NewWaiter(tf, invClient, poller)
+			require.NoError(t, err)
+
+			var events []event.Event
+			for e := range waiter.Run(context.Background(), localInv, tc.objects, tc.options) {
+				events = append(events, e)
+			}
+			require.NotEmpty(t, events)
+			last := events[len(events)-1]
+
+			if tc.expectedErr != "" {
+				require.Equal(t, event.ErrorType, last.Type)
+				assert.EqualError(t, last.ErrorEvent.Err, tc.expectedErr)
+				return
+			}
+
+			require.Equal(t, event.InitType, events[0].Type)
+			require.Len(t, events[0].InitEvent.ActionGroups, 1)
+			ag := events[0].InitEvent.ActionGroups[0]
+			assert.Equal(t, event.WaitAction, ag.Action)
+			assert.Equal(t, tc.expectedIds, ag.Identifiers)
+
+			if tc.expectTimeout {
+				require.Equal(t, event.ErrorType, last.Type)
+				timeoutErr, ok := taskrunner.IsTimeoutError(last.ErrorEvent.Err)
+				require.True(t, ok)
+				assert.Len(t, timeoutErr.TimedOutResources, 1)
+				return
+			}
+			assert.Equal(t, event.ActionGroupType, last.Type)
+			assert.Equal(t, event.Finished, last.ActionGroupEvent.Type)
+		})
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"text/template"
 
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/diff"
+	"sigs.k8s.io/cli-utils/pkg/apply/drift"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
@@ -20,6 +22,8 @@ import (
 
 const (
 	DefaultErrorExitCode = 1
+	DiffErrorExitCode    = 1
+	DriftErrorExitCode   = 2
 	TimeoutErrorExitCode = 3
 )
 
@@ -58,10 +62,31 @@ Unknown type(s) encountered. Every type must either be already installed in the
 {{- range .err.GroupKinds}}
 {{ printf "%s" . }}
 {{- end}}
+`
+
+	errorMsgForType[reflect.TypeOf(drift.DriftError{})] = `
+{{printf "%d" (len .err.Identifiers)}} resource(s) drifted from the local configuration:
+
+{{- range .err.Identifiers}}
+{{printf "%s/%s" .GroupKind.Kind .Name }}
+{{- end}}
+`
+
+	// The differences have already been printed, so like kubectl diff
+	// nothing more is printed before exiting.
+	errorMsgForType[reflect.TypeOf(diff.DiffError{})] = ""
+
+	errorMsgForType[reflect.TypeOf(inventory.InventoryLockedError{})] = `
+Inventory is locked by {{printf "%q" .err.Holder}} since {{.err.Since.Format "2006-01-02T15:04:05Z07:00"}} (lock {{.err.Namespace}}/{{.err.Name}}).
+
+Another apply or destroy of the same package is in progress. Wait
+for it to complete and try again.
 `
 
 	statusCodeForType = make(map[reflect.Type]int)
 	statusCodeForType[reflect.TypeOf(taskrunner.TimeoutError{})] = TimeoutErrorExitCode
+	statusCodeForType[reflect.TypeOf(drift.DriftError{})] = DriftErrorExitCode
+	statusCodeForType[reflect.TypeOf(diff.DiffError{})] = DiffErrorExitCode
 }
 
 // CheckErr looks up the appropriate error message and exit status for known
@@ -11,6 +11,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/diff"
+	"sigs.k8s.io/cli-utils/pkg/apply/drift"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
@@ -83,6 +85,54 @@ func TestTextForError(t *testing.T) {
 			expectedErrText: `
 Timeout after 2 seconds waiting for 1 out of 1 resources to reach condition AllCurrent:
 Deployment/foo InProgress
+`,
+		},
+		"drift error": {
+			err: drift.DriftError{
+				Identifiers: []object.ObjMetadata{
+					{
+						GroupKind: schema.GroupKind{
+							Kind:  "Deployment",
+							Group: "apps",
+						},
+						Name: "foo",
+					},
+				},
+			},
+			cmdNameBase: "kapply",
+			expectFound: true,
+			expectedErrText: `
+1 resource(s) drifted from the local configuration:
+Deployment/foo
+`,
+		},
+		"diff error": {
+			err: diff.DiffError{
+				Identifiers: []object.ObjMetadata{
+					{
+						GroupKind: schema.GroupKind{
+							Kind:  "Deployment",
+							Group: "apps",
+						},
+						Name: "foo",
+					},
+				},
+			},
+			cmdNameBase:     "kapply",
+			expectFound:     true,
+			expectedErrText: "",
+		},
+		"inventory locked error": {
+			err: inventory.InventoryLockedError{
+				Namespace: "default",
+				Name:      "inventory-lock-foo",
+				Holder:    "ci-runner",
+				Since:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			cmdNameBase: "kapply",
+			expectFound: true,
+			expectedErrText: `
+Inventory is locked by "ci-runner" since 2021-01-01T00:00:00Z (lock default/inventory-lock-foo).
 `,
 		},
 	}
@@ -0,0 +1,214 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the adoption of live objects into an inventory,
+// and their release from an inventory, without applying them. Together
+// they hand an object from one inventory to another: the object is
+// released from the old inventory and adopted into the new one.
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Adopter adopts live objects into an inventory, and releases them
+// from an inventory.
+type Adopter struct {
+	InvClient InventoryClient
+	Client    dynamic.Interface
+	Mapper    meta.RESTMapper
+}
+
+// NewAdopter returns an Adopter that stores the inventory through the
+// passed inventory client.
+func NewAdopter(factory cmdutil.Factory, invClient InventoryClient) (*Adopter, error) {
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Adopter{
+		InvClient: invClient,
+		Client:    client,
+		Mapper:    mapper,
+	}, nil
+}
+
+// Adopt adds the live objects identified by the passed ids to the
+// passed inventory, and sets their owning-inventory annotation. Whether
+// an object can be adopted follows the same rules as applying it with
+// the passed policy: with AdoptIfNoInventory only objects that are not
+// owned by another inventory are adopted, while AdoptAll also takes
+// objects from other inventories. If any of the objects can't be
+// adopted, none are. The adopted objects are returned.
+func (a *Adopter) Adopt(inv InventoryInfo, ids []object.ObjMetadata, policy InventoryPolicy,
+	dryRun common.DryRunStrategy) ([]object.ObjMetadata, error) {
+	objs := make([]*unstructured.Unstructured, 0, len(ids))
+	for _, id := range ids {
+		obj, err := a.getObject(id)
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			return nil, fmt.Errorf("can't adopt %s: object not found", id)
+		}
+		if _, err := CanApply(inv, obj, policy); err != nil {
+			return nil, fmt.Errorf("can't adopt %s: %w", id, err)
+		}
+		objs = append(objs, obj)
+	}
+	if len(objs) == 0 {
+		return nil, nil
+	}
+
+	// The objects are stored in the inventory before they are annotated,
+	// so an interrupted adoption never leaves an object that claims to be
+	// owned by an inventory that does not know about it.
+	if _, err := a.InvClient.Merge(inv, ids, dryRun); err != nil {
+		return nil, err
+	}
+	clusterObjs, err := a.InvClient.GetClusterObjs(inv, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	// The UID of the adopted objects is recorded, so they are not pruned
+	// if they are recreated outside of the inventory.
+	statuses := make([]ObjectStatus, 0, len(objs))
+	for _, obj := range objs {
+		statuses = append(statuses, ObjectStatus{
+			Identifier: object.UnstructuredToObjMetaOrDie(obj),
+			Generation: obj.GetGeneration(),
+			UID:        obj.GetUID(),
+		})
+	}
+	if err := a.InvClient.ReplaceWithStatus(inv, object.Union(clusterObjs, ids), statuses, dryRun); err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if InventoryIDMatch(inv, obj) == Match {
+			continue
+		}
+		klog.V(4).Infof("adopting %s into inventory %s", object.UnstructuredToObjMetaOrDie(obj), inv.ID())
+		if err := a.patchOwningInventory(obj, inv.ID(), dryRun); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// Release removes the objects identified by the passed ids from the
+// passed inventory, and removes their owning-inventory annotation. The
+// objects are not deleted. Objects that are not in the inventory are
+// ignored. Objects that were already adopted by another inventory are
+// removed from the inventory, but keep their annotation. The released
+// objects are returned.
+func (a *Adopter) Release(inv InventoryInfo, ids []object.ObjMetadata,
+	dryRun common.DryRunStrategy) ([]object.ObjMetadata, error) {
+	clusterObjs, err := a.InvClient.GetClusterObjs(inv, common.DryRunNone)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[object.ObjMetadata]bool, len(clusterObjs))
+	for _, id := range clusterObjs {
+		stored[id] = true
+	}
+	var released []object.ObjMetadata
+	var objs []*unstructured.Unstructured
+	for _, id := range ids {
+		if !stored[id] {
+			klog.V(4).Infof("not releasing %s: not in inventory %s", id, inv.ID())
+			continue
+		}
+		obj, err := a.getObject(id)
+		if err != nil {
+			return nil, err
+		}
+		if obj != nil && InventoryIDMatch(inv, obj) == Match {
+			objs = append(objs, obj)
+		}
+		released = append(released, id)
+	}
+	if len(released) == 0 {
+		return nil, nil
+	}
+
+	// The objects are removed from the inventory before the annotation
+	// is removed, so an interrupted release never leaves an object that
+	// can be pruned by the inventory it was released from.
+	if err := a.InvClient.Replace(inv, object.SetDiff(clusterObjs, released), dryRun); err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		klog.V(4).Infof("releasing %s from inventory %s", object.UnstructuredToObjMetaOrDie(obj), inv.ID())
+		if err := a.patchOwningInventory(obj, "", dryRun); err != nil {
+			return nil, err
+		}
+	}
+	return released, nil
+}
+
+// getObject returns the live object identified by the passed id, or nil
+// if it does not exist.
+func (a *Adopter) getObject(id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	client, err := a.resourceClient(id)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return obj, err
+}
+
+// patchOwningInventory sets the owning-inventory annotation of the
+// passed object to the passed inventory id, or removes it if the id is
+// empty.
+func (a *Adopter) patchOwningInventory(obj *unstructured.Unstructured, id string, dryRun common.DryRunStrategy) error {
+	if dryRun.ClientOrServerDryRun() {
+		return nil
+	}
+	var value interface{}
+	if id != "" {
+		value = id
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{owningInventoryKey: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	client, err := a.resourceClient(object.UnstructuredToObjMetaOrDie(obj))
+	if err != nil {
+		return err
+	}
+	_, err = client.Patch(context.TODO(), obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (a *Adopter) resourceClient(id object.ObjMetadata) (dynamic.ResourceInterface, error) {
+	mapping, err := a.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return a.Client.Resource(mapping.Resource).Namespace(id.Namespace), nil
+}
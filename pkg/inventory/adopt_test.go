@@ -0,0 +1,210 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestAdopter_Adopt(t *testing.T) {
+	tests := map[string]struct {
+		owner          string
+		policy         InventoryPolicy
+		dryRun         common.DryRunStrategy
+		isError        bool
+		expectedStored bool
+		expectedOwner  string
+	}{
+		"Object without inventory is adopted": {
+			owner:          "",
+			policy:         AdoptIfNoInventory,
+			dryRun:         common.DryRunNone,
+			expectedStored: true,
+			expectedOwner:  testInventoryLabel,
+		},
+		"Object already owned by the inventory is stored": {
+			owner:          testInventoryLabel,
+			policy:         InventoryPolicyMustMatch,
+			dryRun:         common.DryRunNone,
+			expectedStored: true,
+			expectedOwner:  testInventoryLabel,
+		},
+		"Object without inventory is not adopted with MustMatch": {
+			owner:   "",
+			policy:  InventoryPolicyMustMatch,
+			dryRun:  common.DryRunNone,
+			isError: true,
+		},
+		"Object owned by another inventory is not adopted": {
+			owner:         "other",
+			policy:        AdoptIfNoInventory,
+			dryRun:        common.DryRunNone,
+			isError:       true,
+			expectedOwner: "other",
+		},
+		"Object owned by another inventory is adopted with AdoptAll": {
+			owner:          "other",
+			policy:         AdoptAll,
+			dryRun:         common.DryRunNone,
+			expectedStored: true,
+			expectedOwner:  testInventoryLabel,
+		},
+		"Dry-run adoption changes nothing": {
+			owner:  "",
+			policy: AdoptIfNoInventory,
+			dryRun: common.DryRunClient,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			adopter, server, client := newTestAdopter(t, tc.owner)
+			inv := copyInventory()
+			id := ignoreErrInfoToObjMeta(pod1Info)
+
+			adopted, err := adopter.Adopt(inv, []object.ObjMetadata{id}, tc.policy, tc.dryRun)
+			if tc.isError {
+				if err == nil {
+					t.Fatalf("expected error but received none")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+				if !object.SetEquals([]object.ObjMetadata{id}, adopted) {
+					t.Errorf("expected adopted objects (%s), got (%s)", id, adopted)
+				}
+			}
+
+			stored, err := adopter.InvClient.GetClusterObjs(inv, common.DryRunNone)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if tc.expectedStored != object.ObjMetas(stored).Contains(id) {
+				t.Errorf("expected object stored in inventory (%t), got (%s)", tc.expectedStored, stored)
+			}
+			if tc.expectedStored {
+				statuses, err := adopter.InvClient.GetClusterObjStatuses(inv)
+				if err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+				if len(statuses) != 1 || statuses[0].UID != "pod-1-uid" {
+					t.Errorf("expected the UID of the adopted object to be stored, got %v", statuses)
+				}
+			} else if server.count() != 0 {
+				t.Errorf("expected no inventory object, got %d", server.count())
+			}
+			assertOwner(t, client, tc.expectedOwner)
+		})
+	}
+}
+
+func TestAdopter_Release(t *testing.T) {
+	tests := map[string]struct {
+		owner          string
+		dryRun         common.DryRunStrategy
+		expectedStored bool
+		expectedOwner  string
+	}{
+		"Object owned by the inventory is released": {
+			owner:         testInventoryLabel,
+			dryRun:        common.DryRunNone,
+			expectedOwner: "",
+		},
+		"Object owned by another inventory keeps its annotation": {
+			owner:         "other",
+			dryRun:        common.DryRunNone,
+			expectedOwner: "other",
+		},
+		"Dry-run release changes nothing": {
+			owner:          testInventoryLabel,
+			dryRun:         common.DryRunClient,
+			expectedStored: true,
+			expectedOwner:  testInventoryLabel,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			adopter, _, client := newTestAdopter(t, tc.owner)
+			inv := copyInventory()
+			id := ignoreErrInfoToObjMeta(pod1Info)
+			other := ignoreErrInfoToObjMeta(pod2Info)
+			if _, err := adopter.InvClient.Merge(inv, []object.ObjMetadata{id, other}, common.DryRunNone); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+
+			notStored := ignoreErrInfoToObjMeta(pod3Info)
+			released, err := adopter.Release(inv, []object.ObjMetadata{id, notStored}, tc.dryRun)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if !object.SetEquals([]object.ObjMetadata{id}, released) {
+				t.Errorf("expected released objects (%s), got (%s)", id, released)
+			}
+
+			stored, err := adopter.InvClient.GetClusterObjs(inv, common.DryRunNone)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if tc.expectedStored != object.ObjMetas(stored).Contains(id) {
+				t.Errorf("expected object stored in inventory (%t), got (%s)", tc.expectedStored, stored)
+			}
+			if !object.ObjMetas(stored).Contains(other) {
+				t.Errorf("expected other object to stay in inventory, got (%s)", stored)
+			}
+			assertOwner(t, client, tc.expectedOwner)
+		})
+	}
+}
+
+// newTestAdopter returns an Adopter storing inventories in a fake
+// ConfigMap server, and a fake dynamic client with pod1 owned by the
+// inventory with the passed id.
+func newTestAdopter(t *testing.T, owner string) (*Adopter, *fakeConfigMapServer, *dynamicfake.FakeDynamicClient) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	t.Cleanup(tf.Cleanup)
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+	mapper, err := tf.ToRESTMapper()
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+
+	pod := pod1.DeepCopy()
+	pod.SetUID(types.UID("pod-1-uid"))
+	if owner != "" {
+		pod.SetAnnotations(map[string]string{owningInventoryKey: owner})
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), pod)
+	return &Adopter{InvClient: invClient, Client: client, Mapper: mapper}, server, client
+}
+
+// assertOwner checks the owning-inventory annotation of the live pod1.
+func assertOwner(t *testing.T, client *dynamicfake.FakeDynamicClient, expected string) {
+	podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	live, err := client.Resource(podGVR).Namespace(testNamespace).Get(context.TODO(), pod1.GetName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if actual := OwningInventory(live); actual != expected {
+		t.Errorf("expected %s owned by %q, got %q", live.GetName(), expected, actual)
+	}
+}
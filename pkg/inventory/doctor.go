@@ -0,0 +1,209 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the checks for an inconsistent inventory, and the
+// repair of the problems they find. An inventory becomes inconsistent
+// when it is changed by hand, when an apply is interrupted, or when the
+// cluster changes underneath it, for example when a CRD is removed.
+
+package inventory
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/ordering"
+)
+
+// ProblemType is the kind of inconsistency found in an inventory.
+type ProblemType string
+
+const (
+	// DuplicateInventory is an inventory object with the same inventory
+	// id as another inventory object. It is fixed by merging the
+	// duplicate inventory objects into one.
+	DuplicateInventory ProblemType = "DuplicateInventory"
+	// UnservedGroupKind is an object in the inventory whose GroupKind
+	// is no longer served by the cluster. It is fixed by removing the
+	// object from the inventory.
+	UnservedGroupKind ProblemType = "UnservedGroupKind"
+	// OwnershipMismatch is an object in the inventory whose live object
+	// is owned by another inventory. It is fixed by removing the object
+	// from the inventory.
+	OwnershipMismatch ProblemType = "OwnershipMismatch"
+	// MissingFromInventory is a live object owned by the inventory that
+	// is not stored in it. It is fixed by adding the object to the
+	// inventory.
+	MissingFromInventory ProblemType = "MissingFromInventory"
+)
+
+// Problem is an inconsistency found in an inventory.
+type Problem struct {
+	Type ProblemType
+	// Identifier is the object with the problem. For a
+	// DuplicateInventory, it is the duplicate inventory object.
+	Identifier object.ObjMetadata
+	Message    string
+}
+
+// Doctor checks an inventory for inconsistencies and fixes them.
+type Doctor struct {
+	InvClient InventoryClient
+	Client    dynamic.Interface
+	Mapper    meta.RESTMapper
+	// OwnedObjects returns the live objects with an owning-inventory
+	// annotation in all namespaces.
+	OwnedObjects func() ([]*unstructured.Unstructured, error)
+}
+
+// NewDoctor returns a Doctor for the inventories of the passed
+// inventory client.
+func NewDoctor(factory cmdutil.Factory, invClient InventoryClient) (*Doctor, error) {
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Doctor{
+		InvClient: invClient,
+		Client:    client,
+		Mapper:    mapper,
+		OwnedObjects: func() ([]*unstructured.Unstructured, error) {
+			return ListOwnedObjects(factory, metav1.NamespaceAll)
+		},
+	}, nil
+}
+
+// Check returns the inconsistencies of the passed inventory. It does not
+// change the inventory or the objects in it. The live objects of every
+// resource type are searched for objects missing from the inventory, so
+// this can be slow on large clusters.
+func (d *Doctor) Check(inv InventoryInfo) ([]Problem, error) {
+	var problems []Problem
+	invObjs, err := d.InvClient.GetClusterInventoryObjs(inv)
+	if err != nil {
+		return nil, err
+	}
+	if len(invObjs) > 1 {
+		// The first inventory object is retained when they are merged.
+		sort.Sort(ordering.SortableUnstructureds(invObjs))
+		for _, invObj := range invObjs[1:] {
+			problems = append(problems, Problem{
+				Type:       DuplicateInventory,
+				Identifier: object.UnstructuredToObjMetaOrDie(invObj),
+				Message: fmt.Sprintf("duplicate of inventory object %s/%s",
+					invObjs[0].GetNamespace(), invObjs[0].GetName()),
+			})
+		}
+	}
+
+	// A dry-run read merges duplicate inventory objects without storing
+	// the result.
+	stored, err := d.InvClient.GetClusterObjs(inv, common.DryRunClient)
+	if err != nil {
+		return nil, err
+	}
+	adopter := &Adopter{InvClient: d.InvClient, Client: d.Client, Mapper: d.Mapper}
+	for _, id := range stored {
+		if _, err := d.Mapper.RESTMapping(id.GroupKind); err != nil {
+			if !meta.IsNoMatchError(err) {
+				return nil, err
+			}
+			problems = append(problems, Problem{
+				Type:       UnservedGroupKind,
+				Identifier: id,
+				Message:    fmt.Sprintf("%s is not served by the cluster", id.GroupKind),
+			})
+			continue
+		}
+		obj, err := adopter.getObject(id)
+		if err != nil {
+			return nil, err
+		}
+		if obj != nil && InventoryIDMatch(inv, obj) == NoMatch {
+			problems = append(problems, Problem{
+				Type:       OwnershipMismatch,
+				Identifier: id,
+				Message:    fmt.Sprintf("owned by inventory %s", OwningInventory(obj)),
+			})
+		}
+	}
+
+	owned, err := d.OwnedObjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range owned {
+		if InventoryIDMatch(inv, obj) != Match {
+			continue
+		}
+		id := object.UnstructuredToObjMetaOrDie(obj)
+		if object.ObjMetas(stored).Contains(id) {
+			continue
+		}
+		problems = append(problems, Problem{
+			Type:       MissingFromInventory,
+			Identifier: id,
+			Message:    "owned by the inventory but not stored in it",
+		})
+	}
+	return problems, nil
+}
+
+// Fix fixes the passed problems of the passed inventory, as returned by
+// Check. Duplicate inventory objects are merged first, then objects are
+// removed from the inventory, and finally missing objects are added.
+// The objects themselves are not changed.
+func (d *Doctor) Fix(inv InventoryInfo, problems []Problem, dryRun common.DryRunStrategy) error {
+	merge := false
+	var remove, missing []object.ObjMetadata
+	for _, p := range problems {
+		switch p.Type {
+		case DuplicateInventory:
+			merge = true
+		case UnservedGroupKind, OwnershipMismatch:
+			remove = append(remove, p.Identifier)
+		case MissingFromInventory:
+			missing = append(missing, p.Identifier)
+		default:
+			return fmt.Errorf("unknown inventory problem %q", p.Type)
+		}
+	}
+
+	if merge {
+		klog.V(4).Infof("merging duplicate inventory objects of %s", inv.ID())
+		if _, err := d.InvClient.GetClusterInventoryInfo(inv, dryRun); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		stored, err := d.InvClient.GetClusterObjs(inv, dryRun)
+		if err != nil {
+			return err
+		}
+		klog.V(4).Infof("removing %d objects from inventory %s", len(remove), inv.ID())
+		if err := d.InvClient.Replace(inv, object.SetDiff(stored, remove), dryRun); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		// The missing objects are already owned by the inventory, so
+		// adopting them only stores them.
+		adopter := &Adopter{InvClient: d.InvClient, Client: d.Client, Mapper: d.Mapper}
+		if _, err := adopter.Adopt(inv, missing, InventoryPolicyMustMatch, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestDoctor(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+	mapper, err := tf.ToRESTMapper()
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+
+	inv := copyInventory()
+	pod1ID := ignoreErrInfoToObjMeta(pod1Info)
+	pod2ID := ignoreErrInfoToObjMeta(pod2Info)
+	pod3ID := ignoreErrInfoToObjMeta(pod3Info)
+	widgetID := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "example.com", Kind: "Widget"},
+		Namespace: testNamespace,
+		Name:      "widget",
+	}
+	if _, err := invClient.Merge(inv, []object.ObjMetadata{pod1ID, pod2ID, widgetID}, common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	// A second inventory object with the same inventory id.
+	duplicate := storeObjsInInventory(inv, []object.ObjMetadata{pod3ID})
+	duplicate.SetName("zz-duplicate-inventory-obj")
+	server.store(duplicate)
+
+	owned := pod1.DeepCopy()
+	owned.SetAnnotations(map[string]string{owningInventoryKey: testInventoryLabel})
+	other := pod2.DeepCopy()
+	other.SetAnnotations(map[string]string{owningInventoryKey: "other"})
+	missing := pod1.DeepCopy()
+	missing.SetName("pod-4")
+	missing.SetAnnotations(map[string]string{owningInventoryKey: testInventoryLabel})
+	missingID := object.UnstructuredToObjMetaOrDie(missing)
+	doctor := &Doctor{
+		InvClient: invClient,
+		Client:    dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), owned, other, missing),
+		Mapper:    mapper,
+		OwnedObjects: func() ([]*unstructured.Unstructured, error) {
+			return []*unstructured.Unstructured{owned, other, missing}, nil
+		},
+	}
+
+	problems, err := doctor.Check(inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	expected := map[ProblemType]object.ObjMetadata{
+		DuplicateInventory:   object.UnstructuredToObjMetaOrDie(duplicate),
+		UnservedGroupKind:    widgetID,
+		OwnershipMismatch:    pod2ID,
+		MissingFromInventory: missingID,
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %v", len(expected), problems)
+	}
+	for _, p := range problems {
+		if id, found := expected[p.Type]; !found || id != p.Identifier {
+			t.Errorf("unexpected problem %s for %s", p.Type, p.Identifier)
+		}
+	}
+
+	// A dry-run fix changes nothing.
+	if err := doctor.Fix(inv, problems, common.DryRunClient); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if server.count() != 2 {
+		t.Errorf("expected dry-run to keep the duplicate inventory object, got %d objects", server.count())
+	}
+
+	if err := doctor.Fix(inv, problems, common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if server.count() != 1 {
+		t.Errorf("expected a single inventory object, got %d", server.count())
+	}
+	stored, err := invClient.GetClusterObjs(inv, common.DryRunNone)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	expectedStored := []object.ObjMetadata{pod1ID, pod3ID, missingID}
+	if !object.SetEquals(expectedStored, stored) {
+		t.Errorf("expected stored objects (%s), got (%s)", expectedStored, stored)
+	}
+	problems, err = doctor.Check(inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected no problems after fix, got %v", problems)
+	}
+}
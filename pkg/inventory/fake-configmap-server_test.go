@@ -0,0 +1,199 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	"k8s.io/client-go/restmapper"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+var cmNamePathRegex = regexp.MustCompile(`^/namespaces/([^/]+)/configmaps/([^/]+)$`)
+var cmAllNamespacesPathRegex = regexp.MustCompile(`^/configmaps$`)
+
+// fakeConfigMapServer stores ConfigMaps in memory and serves them
+// through a fake REST client. Updates with a stale resourceVersion
+// fail with a conflict.
+type fakeConfigMapServer struct {
+	mu      sync.Mutex
+	objects map[string]*unstructured.Unstructured
+	version int
+	// beforeUpdate is called before a ConfigMap is updated. It can
+	// change the stored objects to simulate a concurrent update.
+	beforeUpdate func(s *fakeConfigMapServer, name string)
+}
+
+func newFakeConfigMapServer() *fakeConfigMapServer {
+	return &fakeConfigMapServer{objects: map[string]*unstructured.Unstructured{}}
+}
+
+func (s *fakeConfigMapServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+func (s *fakeConfigMapServer) builder() *resource.Builder {
+	return resource.NewFakeBuilder(
+		func(version schema.GroupVersion) (resource.RESTClient, error) {
+			return s.client(), nil
+		},
+		func() (meta.RESTMapper, error) {
+			return testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme), nil
+		},
+		func() (restmapper.CategoryExpander, error) {
+			return resource.FakeCategoryExpander, nil
+		})
+}
+
+func (s *fakeConfigMapServer) client() *fake.RESTClient {
+	return &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client:               fake.CreateHTTPClient(s.handle),
+	}
+}
+
+func (s *fakeConfigMapServer) handle(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m := cmNamePathRegex.FindStringSubmatch(req.URL.Path); m != nil {
+		obj, found := s.objects[m[2]]
+		switch req.Method {
+		case "GET":
+			if !found {
+				return response(http.StatusNotFound, notFoundStatus(m[2]))
+			}
+			return response(http.StatusOK, obj.Object)
+		case "PUT":
+			if s.beforeUpdate != nil {
+				s.beforeUpdate(s, m[2])
+				obj, found = s.objects[m[2]]
+			}
+			if !found {
+				return response(http.StatusNotFound, notFoundStatus(m[2]))
+			}
+			update, err := readObject(req)
+			if err != nil {
+				return nil, err
+			}
+			if rv := update.GetResourceVersion(); rv != "" && rv != obj.GetResourceVersion() {
+				return response(http.StatusConflict, map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Status",
+					"status":     "Failure",
+					"reason":     "Conflict",
+					"code":       http.StatusConflict,
+				})
+			}
+			s.store(update)
+			return response(http.StatusOK, update.Object)
+		case "DELETE":
+			if !found {
+				return response(http.StatusNotFound, notFoundStatus(m[2]))
+			}
+			delete(s.objects, m[2])
+			return response(http.StatusOK, obj.Object)
+		}
+	}
+	if cmPathRegex.MatchString(req.URL.Path) || cmAllNamespacesPathRegex.MatchString(req.URL.Path) {
+		switch req.Method {
+		case "GET":
+			selector, err := labels.Parse(req.URL.Query().Get("labelSelector"))
+			if err != nil {
+				return nil, err
+			}
+			var names []string
+			for name, obj := range s.objects {
+				if selector.Matches(labels.Set(obj.GetLabels())) {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			items := []interface{}{}
+			for _, name := range names {
+				items = append(items, s.objects[name].Object)
+			}
+			return response(http.StatusOK, map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "List",
+				"items":      items,
+			})
+		case "POST":
+			obj, err := readObject(req)
+			if err != nil {
+				return nil, err
+			}
+			if _, found := s.objects[obj.GetName()]; found {
+				return response(http.StatusConflict, map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Status",
+					"status":     "Failure",
+					"reason":     "AlreadyExists",
+					"code":       http.StatusConflict,
+				})
+			}
+			s.store(obj)
+			return response(http.StatusCreated, obj.Object)
+		}
+	}
+	return nil, fmt.Errorf("unexpected request: %s %s", req.Method, req.URL.Path)
+}
+
+// store stores the passed object with a new resourceVersion.
+func (s *fakeConfigMapServer) store(obj *unstructured.Unstructured) {
+	s.version++
+	obj.SetResourceVersion(fmt.Sprintf("%d", s.version))
+	s.objects[obj.GetName()] = obj
+}
+
+func readObject(req *http.Request) (*unstructured.Unstructured, error) {
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(b, &obj.Object); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func notFoundStatus(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Status",
+		"status":     "Failure",
+		"reason":     "NotFound",
+		"message":    fmt.Sprintf("configmaps %q not found", name),
+		"code":       http.StatusNotFound,
+	}
+}
+
+func response(code int, body map[string]interface{}) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     cmdtesting.DefaultHeader(),
+		Body:       ioutil.NopCloser(bytes.NewReader(b)),
+	}, nil
+}
@@ -13,12 +13,25 @@ import (
 // FakeInventoryClient is a testing implementation of the InventoryClient interface.
 type FakeInventoryClient struct {
 	Objs []object.ObjMetadata
-	Err  error
+	// Revisions is the revision history, with the most recent
+	// revision first. A revision is added by every Replace.
+	Revisions []Revision
+	// Statuses is the status of the objects, as stored by
+	// ReplaceWithStatus.
+	Statuses []ObjectStatus
+	// Applier is recorded in the revisions. The default field manager
+	// is recorded if it is empty.
+	Applier string
+	// Namespaces are the names of the namespaces applied with
+	// ApplyInventoryNamespace.
+	Namespaces []string
+	Err        error
 }
 
 var (
 	_ InventoryClient        = &FakeInventoryClient{}
 	_ InventoryClientFactory = FakeInventoryClientFactory{}
+	_ ApplierSetter          = &FakeInventoryClient{}
 )
 
 type FakeInventoryClientFactory []object.ObjMetadata
@@ -55,6 +68,11 @@ func (fic *FakeInventoryClient) Merge(_ InventoryInfo, objs []object.ObjMetadata
 	return diffObjs, nil
 }
 
+// SetApplier sets the identity recorded in the revisions.
+func (fic *FakeInventoryClient) SetApplier(applier string) {
+	fic.Applier = applier
+}
+
 // Replace the stored cluster inventory objs with the passed obj, or an
 // error if one is set up.
 
@@ -63,9 +81,47 @@ func (fic *FakeInventoryClient) Replace(_ InventoryInfo, objs []object.ObjMetada
 		return fic.Err
 	}
 	fic.Objs = objs
+	var number int64 = 1
+	if len(fic.Revisions) > 0 {
+		number = fic.Revisions[0].Number + 1
+	}
+	hash, err := object.Hash(objs)
+	if err != nil {
+		return err
+	}
+	applier := fic.Applier
+	if applier == "" {
+		applier = common.DefaultFieldManager
+	}
+	fic.Revisions = append([]Revision{{
+		Number:  number,
+		Applier: applier,
+		Hash:    hash,
+		Objects: objs,
+	}}, fic.Revisions...)
 	return nil
 }
 
+// ReplaceWithStatus replaces the stored cluster inventory objs and statuses
+// with the passed ones, or returns an error if one is set up.
+func (fic *FakeInventoryClient) ReplaceWithStatus(inv InventoryInfo, objs []object.ObjMetadata,
+	statuses []ObjectStatus, dryRun common.DryRunStrategy) error {
+	if err := fic.Replace(inv, objs, dryRun); err != nil {
+		return err
+	}
+	fic.Statuses = statuses
+	return nil
+}
+
+// GetClusterObjStatuses returns the stored statuses, or an error if one
+// is set up.
+func (fic *FakeInventoryClient) GetClusterObjStatuses(InventoryInfo) ([]ObjectStatus, error) {
+	if fic.Err != nil {
+		return nil, fic.Err
+	}
+	return fic.Statuses, nil
+}
+
 // DeleteInventoryObj returns an error if one is forced; does nothing otherwise.
 func (fic *FakeInventoryClient) DeleteInventoryObj(InventoryInfo, common.DryRunStrategy) error {
 	if fic.Err != nil {
@@ -74,10 +130,11 @@ func (fic *FakeInventoryClient) DeleteInventoryObj(InventoryInfo, common.DryRunS
 	return nil
 }
 
-func (fic *FakeInventoryClient) ApplyInventoryNamespace(*unstructured.Unstructured, common.DryRunStrategy) error {
+func (fic *FakeInventoryClient) ApplyInventoryNamespace(obj *unstructured.Unstructured, _ common.DryRunStrategy) error {
 	if fic.Err != nil {
 		return fic.Err
 	}
+	fic.Namespaces = append(fic.Namespaces, obj.GetName())
 	return nil
 }
 
@@ -98,3 +155,26 @@ func (fic *FakeInventoryClient) GetClusterInventoryInfo(InventoryInfo, common.Dr
 func (fic *FakeInventoryClient) GetClusterInventoryObjs(_ InventoryInfo) ([]*unstructured.Unstructured, error) {
 	return []*unstructured.Unstructured{}, nil
 }
+
+// GetClusterRevisions returns the stored revisions, or an error if one
+// is set up.
+func (fic *FakeInventoryClient) GetClusterRevisions(InventoryInfo) ([]Revision, error) {
+	if fic.Err != nil {
+		return nil, fic.Err
+	}
+	return fic.Revisions, nil
+}
+
+// GetClusterRevision returns the stored revision with the passed number,
+// or an error if one is set up.
+func (fic *FakeInventoryClient) GetClusterRevision(_ InventoryInfo, number int64) (*Revision, error) {
+	if fic.Err != nil {
+		return nil, fic.Err
+	}
+	for i := range fic.Revisions {
+		if fic.Revisions[i].Number == number {
+			return &fic.Revisions[i], nil
+		}
+	}
+	return nil, RevisionNotFoundError{Number: number}
+}
@@ -7,6 +7,7 @@ import cmdutil "k8s.io/kubectl/pkg/cmd/util"
 
 var (
 	_ InventoryClientFactory = ClusterInventoryClientFactory{}
+	_ InventoryClientFactory = ShardedInventoryClientFactory{}
 )
 
 // InventoryClientFactory is a factory that constructs new InventoryClient instances.
@@ -21,3 +22,12 @@ type ClusterInventoryClientFactory struct {
 func (ClusterInventoryClientFactory) NewInventoryClient(factory cmdutil.Factory) (InventoryClient, error) {
 	return NewInventoryClient(factory, WrapInventoryObj, InvInfoToConfigMap)
 }
+
+// ShardedInventoryClientFactory is a factory that creates instances of ClusterInventoryClient
+// inventory client, which store the inventory in ConfigMaps sharded by ShardedInventoryConfigMap.
+type ShardedInventoryClientFactory struct {
+}
+
+func (ShardedInventoryClientFactory) NewInventoryClient(factory cmdutil.Factory) (InventoryClient, error) {
+	return NewInventoryClient(factory, WrapShardedInventoryObj, InvInfoToConfigMap)
+}
@@ -6,11 +6,14 @@ package inventory
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util"
@@ -36,6 +39,11 @@ type InventoryClient interface {
 	// Replace replaces the set of objects stored in the inventory
 	// object with the passed set of objects, or an error if one occurs.
 	Replace(inv InventoryInfo, objs []object.ObjMetadata, dryRun common.DryRunStrategy) error
+	// ReplaceWithStatus replaces the set of objects stored in the inventory
+	// object with the passed set of objects, and stores the passed status
+	// of the objects if the inventory supports it. Status fields that are
+	// not set keep the value previously stored for the object.
+	ReplaceWithStatus(inv InventoryInfo, objs []object.ObjMetadata, statuses []ObjectStatus, dryRun common.DryRunStrategy) error
 	// DeleteInventoryObj deletes the passed inventory object from the APIServer.
 	DeleteInventoryObj(inv InventoryInfo, dryRun common.DryRunStrategy) error
 	// ApplyInventoryNamespace applies the Namespace that the inventory object should be in.
@@ -44,6 +52,32 @@ type InventoryClient interface {
 	GetClusterInventoryInfo(inv InventoryInfo, dryRun common.DryRunStrategy) (*unstructured.Unstructured, error)
 	// GetInventoryObjs looks up the inventory objects from the cluster.
 	GetClusterInventoryObjs(inv InventoryInfo) ([]*unstructured.Unstructured, error)
+	// GetClusterRevisions returns the revision history of the cluster
+	// inventory object, with the most recent revision first.
+	GetClusterRevisions(inv InventoryInfo) ([]Revision, error)
+	// GetClusterRevision returns the revision with the passed number
+	// from the revision history of the cluster inventory object.
+	GetClusterRevision(inv InventoryInfo, number int64) (*Revision, error)
+	// GetClusterObjStatuses returns the status of the objects stored in
+	// the cluster inventory object. Objects without a stored status are
+	// not included.
+	GetClusterObjStatuses(inv InventoryInfo) ([]ObjectStatus, error)
+}
+
+// InventoryLister is implemented by InventoryClients that can find the
+// inventory objects in the cluster without knowing their inventory id.
+type InventoryLister interface {
+	// ListClusterInventoryObjs returns the inventory objects of the passed
+	// kind in the passed namespace, or in all namespaces if the namespace
+	// is empty.
+	ListClusterInventoryObjs(gk schema.GroupKind, namespace string) ([]*unstructured.Unstructured, error)
+}
+
+// ApplierSetter is implemented by InventoryClients that record the
+// identity of the client in the revisions of the inventory.
+type ApplierSetter interface {
+	// SetApplier sets the identity recorded in the revisions.
+	SetApplier(applier string)
 }
 
 // ClusterInventoryClient is a concrete implementation of the
@@ -56,9 +90,20 @@ type ClusterInventoryClient struct {
 	InventoryFactoryFunc  InventoryFactoryFunc
 	invToUnstructuredFunc InventoryToUnstructuredFunc
 	InfoHelper            info.InfoHelper
+	// Applier identifies the client in the revisions recorded when
+	// the inventory is replaced. The default is DefaultApplier.
+	Applier string
+	// RevisionHistoryLimit is the number of revisions kept in the
+	// inventory object. If this is zero, DefaultRevisionHistoryLimit
+	// is used. If it is negative, no revisions are recorded.
+	RevisionHistoryLimit int
+	// now returns the timestamp of new revisions.
+	now func() time.Time
 }
 
 var _ InventoryClient = &ClusterInventoryClient{}
+var _ InventoryLister = &ClusterInventoryClient{}
+var _ ApplierSetter = &ClusterInventoryClient{}
 
 // NewInventoryClient returns a concrete implementation of the
 // InventoryClient interface or an error.
@@ -83,18 +128,44 @@ func NewInventoryClient(factory cmdutil.Factory,
 		InventoryFactoryFunc:  invFunc,
 		invToUnstructuredFunc: invToUnstructuredFunc,
 		InfoHelper:            info.NewInfoHelper(mapper, factory),
+		Applier:               DefaultApplier(),
+		now:                   time.Now,
 	}
 	return &clusterInventoryClient, nil
 }
 
+// SetApplier sets the identity recorded in the revisions of the
+// inventory.
+func (cic *ClusterInventoryClient) SetApplier(applier string) {
+	cic.Applier = applier
+}
+
 // Merge stores the union of the passed objects with the objects currently
 // stored in the cluster inventory object. Retrieves and caches the cluster
 // inventory object. Returns the set differrence of the cluster inventory
 // objects and the currently applied objects. This is the set of objects
 // to prune. Creates the initial cluster inventory object storing the passed
-// objects if an inventory object does not exist. Returns an error if one
-// occurred.
+// objects if an inventory object does not exist. The child inventories
+// declared on the local inventory object are copied to the cluster
+// inventory object. Returns an error if one occurred.
+//
+// The cluster inventory object is updated with its resourceVersion as a
+// precondition. If another client changed it in the meantime, the merge
+// is retried with the objects stored by the other client.
 func (cic *ClusterInventoryClient) Merge(localInv InventoryInfo, objs []object.ObjMetadata, dryRun common.DryRunStrategy) ([]object.ObjMetadata, error) {
+	var pruneIds []object.ObjMetadata
+	err := retry.OnError(retry.DefaultRetry, isInventoryConflict, func() error {
+		var err error
+		pruneIds, err = cic.merge(localInv, objs, dryRun)
+		if isInventoryConflict(err) {
+			klog.V(4).Infof("inventory changed during merge: retrying")
+		}
+		return err
+	})
+	return pruneIds, err
+}
+
+func (cic *ClusterInventoryClient) merge(localInv InventoryInfo, objs []object.ObjMetadata, dryRun common.DryRunStrategy) ([]object.ObjMetadata, error) {
 	pruneIds := []object.ObjMetadata{}
 	invObj := cic.invToUnstructuredFunc(localInv)
 	clusterInv, err := cic.GetClusterInventoryInfo(localInv, dryRun)
@@ -112,16 +183,21 @@ func (cic *ClusterInventoryClient) Merge(localInv InventoryInfo, objs []object.O
 			return nil, err
 		}
 		klog.V(4).Infof("creating initial inventory object with %d objects", len(objs))
+		if err := cic.applyShards(inv, dryRun); err != nil {
+			return nil, err
+		}
 		if err := cic.createInventoryObj(invInfo, dryRun); err != nil {
 			return nil, err
 		}
 	} else {
-		// Update existing cluster inventory with merged union of objects
+		// Update existing cluster inventory with merged union of objects,
+		// and with the child inventories declared by the local inventory.
+		childrenChanged := syncChildren(clusterInv, invObj)
 		clusterObjs, err := cic.GetClusterObjs(localInv, dryRun)
 		if err != nil {
 			return pruneIds, err
 		}
-		if object.SetEquals(objs, clusterObjs) {
+		if object.SetEquals(objs, clusterObjs) && !childrenChanged {
 			klog.V(4).Infof("applied objects same as cluster inventory: do nothing")
 			return pruneIds, nil
 		}
@@ -129,17 +205,21 @@ func (cic *ClusterInventoryClient) Merge(localInv InventoryInfo, objs []object.O
 		unionObjs := object.Union(clusterObjs, objs)
 		klog.V(4).Infof("num objects to prune: %d", len(pruneIds))
 		klog.V(4).Infof("num merged objects to store in inventory: %d", len(unionObjs))
-		wrappedInv := cic.InventoryFactoryFunc(clusterInv)
+		wrappedInv, err := cic.wrapClusterInventory(clusterInv)
+		if err != nil {
+			return pruneIds, err
+		}
 		if err = wrappedInv.Store(unionObjs); err != nil {
 			return pruneIds, err
 		}
 		if !dryRun.ClientOrServerDryRun() {
+			prevInv := clusterInv
 			clusterInv, err = wrappedInv.GetObject()
 			if err != nil {
 				return pruneIds, err
 			}
 			klog.V(4).Infof("update cluster inventory: %s/%s", clusterInv.GetNamespace(), clusterInv.GetName())
-			if err := cic.applyInventoryObj(clusterInv, dryRun); err != nil {
+			if err := cic.storeInventoryObj(wrappedInv, prevInv, clusterInv, dryRun); err != nil {
 				return pruneIds, err
 			}
 		}
@@ -150,49 +230,200 @@ func (cic *ClusterInventoryClient) Merge(localInv InventoryInfo, objs []object.O
 
 // Replace stores the passed objects in the cluster inventory object, or
 // an error if one occurred.
+//
+// The cluster inventory object is updated with its resourceVersion as a
+// precondition. If another client changed it in the meantime, the replace
+// is retried, keeping the objects the other client added to the inventory
+// so they are not lost from the set of objects to prune.
 func (cic *ClusterInventoryClient) Replace(localInv InventoryInfo, objs []object.ObjMetadata, dryRun common.DryRunStrategy) error {
+	return cic.ReplaceWithStatus(localInv, objs, nil, dryRun)
+}
+
+// ReplaceWithStatus stores the passed objects in the cluster inventory
+// object like Replace, along with the passed status of the objects if the
+// inventory implements the StatusInventory interface.
+func (cic *ClusterInventoryClient) ReplaceWithStatus(localInv InventoryInfo, objs []object.ObjMetadata,
+	statuses []ObjectStatus, dryRun common.DryRunStrategy) error {
 	// Skip entire function for dry-run.
 	if dryRun.ClientOrServerDryRun() {
 		klog.V(4).Infoln("dry-run replace inventory object: not applied")
 		return nil
 	}
-	clusterObjs, err := cic.GetClusterObjs(localInv, dryRun)
-	if err != nil {
-		return err
-	}
-	if object.SetEquals(objs, clusterObjs) {
-		klog.V(4).Infof("applied objects same as cluster inventory: do nothing")
-		return nil
-	}
-	clusterInv, err := cic.GetClusterInventoryInfo(localInv, dryRun)
-	if err != nil {
-		return err
-	}
-	clusterInv, err = cic.replaceInventory(clusterInv, objs)
+	var readObjs []object.ObjMetadata
+	retried := false
+	return retry.OnError(retry.DefaultRetry, isInventoryConflict, func() error {
+		clusterInv, err := cic.GetClusterInventoryInfo(localInv, dryRun)
+		if err != nil {
+			return err
+		}
+		// First time; no inventory obj yet.
+		if clusterInv == nil && len(objs) == 0 {
+			return nil
+		}
+		var wrappedInv Inventory
+		var clusterObjs []object.ObjMetadata
+		if clusterInv != nil {
+			wrappedInv, err = cic.wrapClusterInventory(clusterInv)
+			if err != nil {
+				return err
+			}
+			clusterObjs, err = wrappedInv.Load()
+			if err != nil {
+				return err
+			}
+		}
+		if retried {
+			added := object.SetDiff(clusterObjs, readObjs)
+			klog.V(4).Infof("inventory changed during replace: keeping %d added objects", len(added))
+			objs = object.Union(objs, added)
+		}
+		readObjs = clusterObjs
+		retried = true
+		if clusterInv != nil && len(statuses) == 0 {
+			upToDate, err := cic.upToDate(clusterInv, clusterObjs, objs)
+			if err != nil {
+				return err
+			}
+			if upToDate {
+				klog.V(4).Infof("applied objects same as cluster inventory: do nothing")
+				return nil
+			}
+		}
+		if wrappedInv == nil {
+			wrappedInv = cic.InventoryFactoryFunc(clusterInv)
+		}
+		return cic.replace(clusterInv, wrappedInv, objs, statuses, dryRun)
+	})
+}
+
+// replace stores the passed objects and statuses in the passed wrapped
+// cluster inventory object, and applies it.
+func (cic *ClusterInventoryClient) replace(clusterInv *unstructured.Unstructured, wrappedInv Inventory,
+	objs []object.ObjMetadata, statuses []ObjectStatus, dryRun common.DryRunStrategy) error {
+	prevInv := clusterInv
+	clusterInv, err := cic.replaceInventory(wrappedInv, objs, statuses)
 	if err != nil {
 		return err
 	}
 	klog.V(4).Infof("replace cluster inventory: %s/%s", clusterInv.GetNamespace(), clusterInv.GetName())
 	klog.V(4).Infof("replace cluster inventory %d objects", len(objs))
-	if err := cic.applyInventoryObj(clusterInv, dryRun); err != nil {
+	if err := cic.storeInventoryObj(wrappedInv, prevInv, clusterInv, dryRun); err != nil {
 		return err
 	}
 	return nil
 }
 
-// replaceInventory stores the passed objects into the passed inventory object.
-func (cic *ClusterInventoryClient) replaceInventory(inv *unstructured.Unstructured, objs []object.ObjMetadata) (*unstructured.Unstructured, error) {
-	wrappedInv := cic.InventoryFactoryFunc(inv)
+// upToDate returns true if the passed inventory object, which stores
+// the passed cluster objects, already stores the passed objects, and the
+// most recent revision has the same objects.
+func (cic *ClusterInventoryClient) upToDate(inv *unstructured.Unstructured, clusterObjs, objs []object.ObjMetadata) (bool, error) {
+	if !object.SetEquals(objs, clusterObjs) {
+		return false, nil
+	}
+	if cic.revisionHistoryLimit() < 1 {
+		return true, nil
+	}
+	return hasLatestRevision(inv, objs)
+}
+
+// replaceInventory stores the passed objects and statuses into the
+// passed wrapped inventory, and returns the updated inventory object.
+// The objects are recorded as a new revision if they differ from the
+// most recent revision.
+func (cic *ClusterInventoryClient) replaceInventory(wrappedInv Inventory, objs []object.ObjMetadata,
+	statuses []ObjectStatus) (*unstructured.Unstructured, error) {
 	if err := wrappedInv.Store(objs); err != nil {
 		return nil, err
 	}
+	if statusInv, ok := wrappedInv.(StatusInventory); ok && len(statuses) > 0 {
+		prevStatuses, err := statusInv.LoadStatus()
+		if err != nil {
+			return nil, err
+		}
+		prev := statusMap(prevStatuses)
+		merged := make([]ObjectStatus, 0, len(statuses))
+		for _, s := range statuses {
+			merged = append(merged, mergeObjectStatus(prev[s.Identifier], s))
+		}
+		if err := statusInv.StoreStatus(merged); err != nil {
+			return nil, err
+		}
+	}
 	clusterInv, err := wrappedInv.GetObject()
 	if err != nil {
 		return nil, err
 	}
+	if limit := cic.revisionHistoryLimit(); limit > 0 {
+		found, err := hasLatestRevision(clusterInv, objs)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if err := addRevision(clusterInv, objs, cic.Applier, cic.now(), limit); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return clusterInv, nil
 }
 
+func (cic *ClusterInventoryClient) revisionHistoryLimit() int {
+	if cic.RevisionHistoryLimit == 0 {
+		return DefaultRevisionHistoryLimit
+	}
+	return cic.RevisionHistoryLimit
+}
+
+// GetClusterRevisions returns the revision history stored in the
+// cluster inventory object, with the most recent revision first. An
+// empty slice is returned if there is no cluster inventory object.
+func (cic *ClusterInventoryClient) GetClusterRevisions(localInv InventoryInfo) ([]Revision, error) {
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv, common.DryRunNone)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInv == nil {
+		return []Revision{}, nil
+	}
+	return LoadRevisions(clusterInv)
+}
+
+// GetClusterRevision returns the revision with the passed number from
+// the revision history stored in the cluster inventory object, or a
+// RevisionNotFoundError if it is not in the history.
+func (cic *ClusterInventoryClient) GetClusterRevision(localInv InventoryInfo, number int64) (*Revision, error) {
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv, common.DryRunNone)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInv == nil {
+		return nil, RevisionNotFoundError{Number: number}
+	}
+	return FindRevision(clusterInv, number)
+}
+
+// GetClusterObjStatuses returns the status of the objects stored in the
+// cluster inventory object. An empty slice is returned if there is no
+// cluster inventory object, or if the inventory does not store status.
+func (cic *ClusterInventoryClient) GetClusterObjStatuses(localInv InventoryInfo) ([]ObjectStatus, error) {
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv, common.DryRunNone)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInv == nil {
+		return []ObjectStatus{}, nil
+	}
+	wrappedInv, err := cic.wrapClusterInventory(clusterInv)
+	if err != nil {
+		return nil, err
+	}
+	statusInv, ok := wrappedInv.(StatusInventory)
+	if !ok {
+		return []ObjectStatus{}, nil
+	}
+	return statusInv.LoadStatus()
+}
+
 // DeleteInventoryObj deletes the inventory object from the cluster.
 func (cic *ClusterInventoryClient) DeleteInventoryObj(localInv InventoryInfo, dryRun common.DryRunStrategy) error {
 	if localInv == nil {
@@ -200,6 +431,15 @@ func (cic *ClusterInventoryClient) DeleteInventoryObj(localInv InventoryInfo, dr
 	}
 	switch localInv.Strategy() {
 	case NameStrategy:
+		// Delete the cluster inventory object if it exists, so that
+		// the shards it references are deleted as well.
+		clusterInvObjs, err := cic.getClusterInventoryObjsByName(localInv)
+		if err != nil {
+			return err
+		}
+		if len(clusterInvObjs) == 1 {
+			return cic.deleteInventoryObjByName(clusterInvObjs[0], dryRun)
+		}
 		return cic.deleteInventoryObjByName(cic.invToUnstructuredFunc(localInv), dryRun)
 	case LabelStrategy:
 		return cic.deleteInventoryObjsByLabel(localInv, dryRun)
@@ -209,7 +449,8 @@ func (cic *ClusterInventoryClient) DeleteInventoryObj(localInv InventoryInfo, dr
 }
 
 func (cic *ClusterInventoryClient) deleteInventoryObjsByLabel(inv InventoryInfo, dryRun common.DryRunStrategy) error {
-	clusterInvObjs, err := cic.getClusterInventoryObjsByLabel(inv)
+	// Shards are deleted along with the inventory object referencing them.
+	clusterInvObjs, err := cic.GetClusterInventoryObjs(inv)
 	if err != nil {
 		return err
 	}
@@ -233,10 +474,35 @@ func (cic *ClusterInventoryClient) GetClusterObjs(localInv InventoryInfo, dryRun
 	if clusterInv == nil {
 		return objs, nil
 	}
-	wrapped := cic.InventoryFactoryFunc(clusterInv)
+	wrapped, err := cic.wrapClusterInventory(clusterInv)
+	if err != nil {
+		return objs, err
+	}
 	return wrapped.Load()
 }
 
+// wrapClusterInventory wraps the passed cluster inventory object with
+// the InventoryFactoryFunc. If the wrapped inventory is sharded, the
+// shards referenced by the inventory object are fetched from the
+// cluster, so the inventory can be loaded.
+func (cic *ClusterInventoryClient) wrapClusterInventory(clusterInv *unstructured.Unstructured) (Inventory, error) {
+	wrapped := cic.InventoryFactoryFunc(clusterInv)
+	sharded, ok := wrapped.(ShardedInventory)
+	if !ok {
+		return wrapped, nil
+	}
+	var shards []*unstructured.Unstructured
+	for _, name := range sharded.ShardNames() {
+		shard, err := cic.getShard(clusterInv, name)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, shard)
+	}
+	sharded.SetShards(shards)
+	return sharded, nil
+}
+
 // getClusterInventoryObj returns a pointer to the cluster inventory object, or
 // an error if one occurred. Returns the cached cluster inventory object if it
 // has been previously retrieved. Uses the ResourceBuilder to retrieve the
@@ -282,13 +548,53 @@ func (cic *ClusterInventoryClient) getClusterInventoryObjsByLabel(inv InventoryI
 	}
 	labelSelector := fmt.Sprintf("%s=%s", common.InventoryLabel, label)
 	klog.V(4).Infof("prune inventory object fetch: %s/%s/%s", groupResource, namespace, labelSelector)
-	builder := cic.builderFunc()
-	retrievedInventoryInfos, err := builder.
+	builder := cic.builderFunc().
 		Unstructured().
 		// TODO: Check if this validator is necessary.
 		Schema(cic.validator).
 		ContinueOnError().
-		NamespaceParam(namespace).DefaultNamespace().
+		NamespaceParam(namespace).DefaultNamespace()
+	return cic.listInventoryObjs(builder, groupResource, labelSelector)
+}
+
+// ListClusterInventoryObjs returns the inventory objects of the passed
+// kind in the passed namespace, or in all namespaces if the namespace is
+// empty. Inventory objects are found by the inventory-id label. The
+// shards of inventory objects are not included.
+func (cic *ClusterInventoryClient) ListClusterInventoryObjs(gk schema.GroupKind, namespace string) ([]*unstructured.Unstructured, error) {
+	mapping, err := cic.mapper.RESTMapping(gk)
+	if err != nil {
+		return nil, err
+	}
+	groupResource := mapping.Resource.GroupResource().String()
+	klog.V(4).Infof("list inventory objects: %s/%s", groupResource, namespace)
+	builder := cic.builderFunc().
+		Unstructured().
+		Schema(cic.validator).
+		ContinueOnError()
+	if namespace == "" {
+		builder = builder.AllNamespaces(true)
+	} else {
+		builder = builder.NamespaceParam(namespace)
+	}
+	invObjs, err := cic.listInventoryObjs(builder, groupResource, common.InventoryLabel)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]*unstructured.Unstructured, 0, len(invObjs))
+	for _, obj := range invObjs {
+		if !isInventoryShard(obj) {
+			filtered = append(filtered, obj)
+		}
+	}
+	return filtered, nil
+}
+
+// listInventoryObjs returns the objects of the passed resource matching
+// the passed label selector, using the passed builder.
+func (cic *ClusterInventoryClient) listInventoryObjs(builder *resource.Builder, groupResource,
+	labelSelector string) ([]*unstructured.Unstructured, error) {
+	retrievedInventoryInfos, err := builder.
 		ResourceTypes(groupResource).
 		LabelSelectorParam(labelSelector).
 		Flatten().
@@ -345,7 +651,18 @@ func (cic *ClusterInventoryClient) GetClusterInventoryObjs(inv InventoryInfo) ([
 	default:
 		panic(fmt.Errorf("unknown inventory strategy: %s", inv.Strategy()))
 	}
-	return clusterInvObjects, err
+	if err != nil {
+		return nil, err
+	}
+	// The shards of a sharded inventory share the inventory label, but
+	// they are part of the inventory object that references them.
+	invObjs := make([]*unstructured.Unstructured, 0, len(clusterInvObjects))
+	for _, obj := range clusterInvObjects {
+		if !isInventoryShard(obj) {
+			invObjs = append(invObjs, obj)
+		}
+	}
+	return invObjs, nil
 }
 
 // mergeClusterInventory merges the inventory of multiple inventory objects
@@ -364,7 +681,10 @@ func (cic *ClusterInventoryClient) mergeClusterInventory(invObjs []*unstructured
 	// choosing the first inventory object as the one to retain.
 	sort.Sort(ordering.SortableUnstructureds(invObjs))
 	retained := invObjs[0]
-	wrapRetained := cic.InventoryFactoryFunc(retained)
+	wrapRetained, err := cic.wrapClusterInventory(retained)
+	if err != nil {
+		return nil, err
+	}
 	retainedObjs, err := wrapRetained.Load()
 	if err != nil {
 		return nil, err
@@ -373,7 +693,10 @@ func (cic *ClusterInventoryClient) mergeClusterInventory(invObjs []*unstructured
 	// the retained objects.
 	for i := 1; i < len(invObjs); i++ {
 		merge := invObjs[i]
-		wrapMerge := cic.InventoryFactoryFunc(merge)
+		wrapMerge, err := cic.wrapClusterInventory(merge)
+		if err != nil {
+			return nil, err
+		}
 		mergeObjs, err := wrapMerge.Load()
 		if err != nil {
 			return nil, err
@@ -393,7 +716,7 @@ func (cic *ClusterInventoryClient) mergeClusterInventory(invObjs []*unstructured
 	// IMPORTANT: This must happen BEFORE deleting the other
 	// inventory objects, in order to ensure we always have
 	// access to the union of the inventory.
-	if err := cic.applyInventoryObj(retainInfo, dryRun); err != nil {
+	if err := cic.storeInventoryObj(wrapRetained, retained, retainInfo, dryRun); err != nil {
 		return nil, err
 	}
 	// Finally, delete the other inventory objects.
@@ -406,7 +729,123 @@ func (cic *ClusterInventoryClient) mergeClusterInventory(invObjs []*unstructured
 	return retainInfo, nil
 }
 
+// storeInventoryObj applies the passed inventory object to the APIServer,
+// replacing the previous inventory object. If the passed wrapped inventory
+// is sharded, the shards are created before the inventory object that
+// references them is applied, and the shards of the previous inventory
+// object that are no longer referenced are deleted afterwards.
+func (cic *ClusterInventoryClient) storeInventoryObj(wrapped Inventory, prevInv, obj *unstructured.Unstructured, dryRun common.DryRunStrategy) error {
+	if err := cic.applyShards(wrapped, dryRun); err != nil {
+		return err
+	}
+	if err := cic.applyInventoryObj(obj, dryRun); err != nil {
+		return err
+	}
+	for _, name := range staleShardNames(prevInv, obj) {
+		if err := cic.deleteShard(prevInv, name, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyShards creates the shards of the passed inventory if it is
+// sharded. Shards are named after their content, so shards that
+// already exist are left as they are.
+func (cic *ClusterInventoryClient) applyShards(wrapped Inventory, dryRun common.DryRunStrategy) error {
+	sharded, ok := wrapped.(ShardedInventory)
+	if !ok {
+		return nil
+	}
+	if dryRun.ClientOrServerDryRun() {
+		klog.V(4).Infof("dry-run create inventory shards: not created")
+		return nil
+	}
+	for _, shard := range sharded.GetShards() {
+		invInfo, err := cic.toInfo(shard)
+		if err != nil {
+			return err
+		}
+		helper, err := cic.helperFromInfo(invInfo)
+		if err != nil {
+			return err
+		}
+		klog.V(4).Infof("creating inventory shard: %s/%s", invInfo.Namespace, invInfo.Name)
+		var clearResourceVersion = false
+		_, err = helper.Create(invInfo.Namespace, clearResourceVersion, invInfo.Object)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getShard returns the shard with the passed name of the passed
+// inventory object from the cluster, or an error if it does not exist.
+func (cic *ClusterInventoryClient) getShard(inv *unstructured.Unstructured, name string) (*unstructured.Unstructured, error) {
+	helper, err := cic.shardHelper(inv, name)
+	if err != nil {
+		return nil, err
+	}
+	res, err := helper.Get(inv.GetNamespace(), name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("shard %s of inventory object %s/%s not found",
+				name, inv.GetNamespace(), inv.GetName())
+		}
+		return nil, err
+	}
+	shard, ok := res.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("retrieved inventory shard is not of type *Unstructured")
+	}
+	return shard, nil
+}
+
+// deleteShard deletes the shard with the passed name of the passed
+// inventory object. Shards that no longer exist are ignored.
+func (cic *ClusterInventoryClient) deleteShard(inv *unstructured.Unstructured, name string, dryRun common.DryRunStrategy) error {
+	if dryRun.ClientOrServerDryRun() {
+		klog.V(4).Infof("dry-run delete inventory shard: not deleted")
+		return nil
+	}
+	helper, err := cic.shardHelper(inv, name)
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("deleting inventory shard: %s/%s", inv.GetNamespace(), name)
+	_, err = helper.Delete(inv.GetNamespace(), name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// shardHelper returns the resource.Helper for the shard with the passed
+// name. Shards have the same type and namespace as their inventory object.
+func (cic *ClusterInventoryClient) shardHelper(inv *unstructured.Unstructured, name string) (*resource.Helper, error) {
+	shard := &unstructured.Unstructured{}
+	shard.SetAPIVersion(inv.GetAPIVersion())
+	shard.SetKind(inv.GetKind())
+	shard.SetName(name)
+	shard.SetNamespace(inv.GetNamespace())
+	invInfo, err := cic.toInfo(shard)
+	if err != nil {
+		return nil, err
+	}
+	return cic.helperFromInfo(invInfo)
+}
+
+// isInventoryConflict returns true if the passed error means the cluster
+// inventory object was changed or created by another client since it
+// was read.
+func isInventoryConflict(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
 // applyInventoryObj applies the passed inventory object to the APIServer.
+// The resourceVersion of the passed object is used as a precondition, so
+// the update fails with a conflict if the object changed since it was read.
 func (cic *ClusterInventoryClient) applyInventoryObj(obj *unstructured.Unstructured, dryRun common.DryRunStrategy) error {
 	if dryRun.ClientOrServerDryRun() {
 		klog.V(4).Infof("dry-run apply inventory object: not applied")
@@ -419,7 +858,10 @@ func (cic *ClusterInventoryClient) applyInventoryObj(obj *unstructured.Unstructu
 	if err != nil {
 		return err
 	}
-	helper := resource.NewHelper(invInfo.Client, invInfo.Mapping)
+	helper, err := cic.helperFromInfo(invInfo)
+	if err != nil {
+		return err
+	}
 	klog.V(4).Infof("replacing inventory object: %s/%s", invInfo.Namespace, invInfo.Name)
 	var overwrite = true
 	replacedObj, err := helper.Replace(invInfo.Namespace, invInfo.Name, overwrite, invInfo.Object)
@@ -483,7 +925,17 @@ func (cic *ClusterInventoryClient) deleteInventoryObjByName(obj *unstructured.Un
 	}
 	klog.V(4).Infof("deleting inventory object: %s/%s", invInfo.Namespace, invInfo.Name)
 	_, err = helper.Delete(invInfo.Namespace, invInfo.Name)
-	return err
+	if err != nil {
+		return err
+	}
+	// The shards are deleted after the inventory object, so the
+	// inventory object never references a missing shard.
+	for _, name := range shardNames(obj) {
+		if err := cic.deleteShard(obj, name, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ApplyInventoryNamespace creates the passed namespace if it does not already
@@ -330,7 +330,7 @@ func TestReplace(t *testing.T) {
 				t.Fatalf("unexpected error storing inventory objects: %s", err)
 			}
 			// Call replaceInventory with the new set of "localObjs"
-			inv, err = invClient.replaceInventory(inv, tc.localObjs)
+			inv, err = invClient.replaceInventory(invClient.InventoryFactoryFunc(inv), tc.localObjs, nil)
 			if err != nil {
 				t.Fatalf("unexpected error received: %s", err)
 			}
@@ -343,6 +343,20 @@ func TestReplace(t *testing.T) {
 			if !object.SetEquals(tc.localObjs, actualObjs) {
 				t.Errorf("expected objects (%s), got (%s)", tc.localObjs, actualObjs)
 			}
+			// Validate that the "localObjs" are recorded as a new revision.
+			revisions, err := LoadRevisions(inv)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if len(revisions) != 1 {
+				t.Fatalf("expected 1 revision, got %d", len(revisions))
+			}
+			if !object.SetEquals(tc.localObjs, revisions[0].Objects) {
+				t.Errorf("expected revision objects (%s), got (%s)", tc.localObjs, revisions[0].Objects)
+			}
+			if revisions[0].Applier != DefaultApplier() {
+				t.Errorf("expected revision applier %s, got %s", DefaultApplier(), revisions[0].Applier)
+			}
 		})
 	}
 }
@@ -583,3 +597,71 @@ func ignoreErrInfoToObjMeta(info *resource.Info) object.ObjMetadata {
 	objMeta, _ := object.InfoToObjMeta(info)
 	return objMeta
 }
+
+func TestInventoryConflict(t *testing.T) {
+	pods := testPods(10)
+	// concurrentObj is added to the inventory by another client between
+	// the read and the update of the inventory object.
+	concurrentObj := pods[9]
+
+	tests := map[string]struct {
+		update   func(invClient *ClusterInventoryClient, inv InventoryInfo) error
+		expected []object.ObjMetadata
+	}{
+		"Merge keeps the objects added concurrently": {
+			update: func(invClient *ClusterInventoryClient, inv InventoryInfo) error {
+				_, err := invClient.Merge(inv, pods[0:4], common.DryRunNone)
+				return err
+			},
+			expected: append([]object.ObjMetadata{concurrentObj}, pods[0:4]...),
+		},
+		"Replace keeps the objects added concurrently": {
+			update: func(invClient *ClusterInventoryClient, inv InventoryInfo) error {
+				return invClient.Replace(inv, pods[0:1], common.DryRunNone)
+			},
+			expected: []object.ObjMetadata{pods[0], concurrentObj},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+			defer tf.Cleanup()
+			server := newFakeConfigMapServer()
+			tf.UnstructuredClient = server.client()
+			tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+			invClient, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			invClient.builderFunc = server.builder
+			inv := copyInventory()
+			if _, err := invClient.Merge(inv, pods[0:3], common.DryRunNone); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+
+			server.beforeUpdate = func(s *fakeConfigMapServer, name string) {
+				s.beforeUpdate = nil
+				obj := s.objects[name].DeepCopy()
+				data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+				data[concurrentObj.String()] = ""
+				_ = unstructured.SetNestedStringMap(obj.Object, data, "data")
+				s.store(obj)
+			}
+			if err := tc.update(invClient, inv); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if server.beforeUpdate != nil {
+				t.Fatalf("expected the inventory object to be updated")
+			}
+			actual, err := invClient.GetClusterObjs(inv, common.DryRunNone)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if !object.SetEquals(tc.expected, actual) {
+				t.Errorf("expected cluster objs (%s), got (%s)", tc.expected, actual)
+			}
+		})
+	}
+}
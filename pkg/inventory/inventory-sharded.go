@@ -0,0 +1,305 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// Introduces the ShardedInventoryConfigMap which implements the
+// Inventory interface. Like the InventoryConfigMap, it stores the
+// object metadata as keys in the data field of a ConfigMap. When the
+// inventory is too large for a single ConfigMap, the keys are split
+// across additional shard ConfigMaps. The shards are linked to the
+// inventory object with the inventory-id label, and the inventory
+// object references the shards that belong to it by name.
+//
+// Shards are named after the hash of their content, so they are never
+// modified once created. When the inventory is stored, new shards are
+// created first, then the inventory object is updated to reference
+// them, and finally the shards that are no longer referenced are
+// deleted. This means the shards referenced by an inventory object
+// always exist, and loading an inventory always sees a consistent set
+// of objects.
+
+package inventory
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// ShardsAnnotation is the annotation on a sharded inventory object
+	// that lists the names of its shards, separated by commas.
+	ShardsAnnotation = "cli-utils.sigs.k8s.io/inventory-shards"
+
+	// ShardLabel is the label on the shards of an inventory object. The
+	// value is the name of the inventory object the shard belongs to.
+	ShardLabel = "cli-utils.sigs.k8s.io/inventory-shard"
+
+	// DefaultMaxShardSize is the default maximum size in bytes of the
+	// object metadata stored in a single ConfigMap. It leaves plenty of
+	// room below the 1MiB limit on the size of an object.
+	DefaultMaxShardSize = 512 * 1024
+
+	// shardKeyOverhead is added to the length of each key and value when
+	// the size of a shard is computed, to account for their encoding.
+	shardKeyOverhead = 8
+)
+
+// ShardedInventory is implemented by Inventory implementations that
+// store the inventory across several objects. The ClusterInventoryClient
+// fetches the shards of a cluster inventory object before it is loaded,
+// and stores the shards before the inventory object that references them.
+type ShardedInventory interface {
+	Inventory
+	// ShardNames returns the names of the shards referenced by the
+	// wrapped inventory object.
+	ShardNames() []string
+	// SetShards sets the shards of the wrapped inventory object, as
+	// fetched from the cluster.
+	SetShards(shards []*unstructured.Unstructured)
+	// GetShards returns the shards that must exist in the cluster for
+	// the object returned by GetObject. It must be called after
+	// GetObject.
+	GetShards() []*unstructured.Unstructured
+}
+
+// WrapShardedInventoryObj takes a passed ConfigMap, wraps it with the
+// ShardedInventoryConfigMap and upcasts the wrapper as the Inventory
+// interface.
+func WrapShardedInventoryObj(inv *unstructured.Unstructured) Inventory {
+	return &ShardedInventoryConfigMap{
+		inv:          inv,
+		maxShardSize: DefaultMaxShardSize,
+	}
+}
+
+// ShardedInventoryConfigMap wraps a ConfigMap resource and implements
+// the ShardedInventory and StatusInventory interfaces.
+type ShardedInventoryConfigMap struct {
+	inv          *unstructured.Unstructured
+	shards       []*unstructured.Unstructured
+	objMetas     []object.ObjMetadata
+	statuses     map[object.ObjMetadata]ObjectStatus
+	maxShardSize int
+	// newShards are the shards computed by GetObject.
+	newShards []*unstructured.Unstructured
+}
+
+var _ ShardedInventory = &ShardedInventoryConfigMap{}
+var _ StatusInventory = &ShardedInventoryConfigMap{}
+
+// Load returns the set of object metadata from the wrapped ConfigMap
+// and its shards, or an error.
+func (s *ShardedInventoryConfigMap) Load() ([]object.ObjMetadata, error) {
+	objs, err := WrapInventoryObj(s.inv).Load()
+	if err != nil {
+		return objs, err
+	}
+	for _, shard := range s.shards {
+		shardObjs, err := WrapInventoryObj(shard).Load()
+		if err != nil {
+			return objs, err
+		}
+		objs = append(objs, shardObjs...)
+	}
+	return objs, nil
+}
+
+// Store sets the object metadata to store in the wrapped ConfigMap and
+// its shards. Actual storing happens in "GetObject".
+func (s *ShardedInventoryConfigMap) Store(objMetas []object.ObjMetadata) error {
+	s.objMetas = objMetas
+	return nil
+}
+
+// LoadStatus returns the status of the objects stored in the values of
+// the wrapped ConfigMap and its shards.
+func (s *ShardedInventoryConfigMap) LoadStatus() ([]ObjectStatus, error) {
+	objMap, err := s.loadObjMap()
+	if err != nil {
+		return nil, err
+	}
+	return loadObjectStatuses(objMap)
+}
+
+// StoreStatus sets the status of the objects to store in the wrapped
+// ConfigMap and its shards. Actual storing happens in "GetObject".
+func (s *ShardedInventoryConfigMap) StoreStatus(statuses []ObjectStatus) error {
+	s.statuses = statusMap(statuses)
+	return nil
+}
+
+// loadObjMap returns the data of the wrapped ConfigMap and its shards.
+func (s *ShardedInventoryConfigMap) loadObjMap() (map[string]string, error) {
+	objMap := map[string]string{}
+	for _, obj := range append([]*unstructured.Unstructured{s.inv}, s.shards...) {
+		data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving object metadata from inventory object")
+		}
+		for key, value := range data {
+			objMap[key] = value
+		}
+	}
+	return objMap, nil
+}
+
+// GetObject returns a copy of the wrapped ConfigMap with the first
+// part of the object metadata, and computes the shards with the
+// remaining object metadata. The shards are returned by GetShards.
+// The status of the objects already stored is kept, unless a new
+// status was stored.
+func (s *ShardedInventoryConfigMap) GetObject() (*unstructured.Unstructured, error) {
+	prevObjMap, err := s.loadObjMap()
+	if err != nil {
+		return nil, err
+	}
+	objMap, err := buildObjMapWithStatus(s.objMetas, s.statuses, prevObjMap)
+	if err != nil {
+		return nil, err
+	}
+	chunks := splitObjMap(objMap, s.maxShardSize)
+	invCopy := s.inv.DeepCopy()
+	var first map[string]string
+	if len(chunks) > 0 {
+		first = chunks[0]
+	} else {
+		first = map[string]string{}
+	}
+	if err := unstructured.SetNestedStringMap(invCopy.UnstructuredContent(), first, "data"); err != nil {
+		return nil, err
+	}
+	s.newShards = nil
+	var names []string
+	for i := 1; i < len(chunks); i++ {
+		shard, err := s.newShard(chunks[i])
+		if err != nil {
+			return nil, err
+		}
+		s.newShards = append(s.newShards, shard)
+		names = append(names, shard.GetName())
+	}
+	annotations := invCopy.GetAnnotations()
+	if len(names) > 0 {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[ShardsAnnotation] = strings.Join(names, ",")
+	} else {
+		delete(annotations, ShardsAnnotation)
+	}
+	invCopy.SetAnnotations(annotations)
+	return invCopy, nil
+}
+
+// newShard returns a ConfigMap in the namespace of the wrapped
+// ConfigMap that stores the passed inventory data. The name of the
+// shard is derived from the name of the wrapped ConfigMap and the hash
+// of the data.
+func (s *ShardedInventoryConfigMap) newShard(objMap map[string]string) (*unstructured.Unstructured, error) {
+	keys := make([]string, 0, len(objMap))
+	for key := range objMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := fnv.New32a()
+	for _, key := range keys {
+		if _, err := h.Write([]byte(key + "=" + objMap[key] + "\n")); err != nil {
+			return nil, err
+		}
+	}
+	shard := &unstructured.Unstructured{}
+	shard.SetAPIVersion(s.inv.GetAPIVersion())
+	shard.SetKind(s.inv.GetKind())
+	shard.SetName(fmt.Sprintf("%s-shard-%s", s.inv.GetName(), strconv.FormatUint(uint64(h.Sum32()), 16)))
+	shard.SetNamespace(s.inv.GetNamespace())
+	shard.SetLabels(map[string]string{
+		common.InventoryLabel: s.inv.GetLabels()[common.InventoryLabel],
+		ShardLabel:            s.inv.GetName(),
+	})
+	if err := unstructured.SetNestedStringMap(shard.Object, objMap, "data"); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
+// ShardNames returns the names of the shards referenced by the wrapped
+// ConfigMap.
+func (s *ShardedInventoryConfigMap) ShardNames() []string {
+	return shardNames(s.inv)
+}
+
+// SetShards sets the shards of the wrapped ConfigMap.
+func (s *ShardedInventoryConfigMap) SetShards(shards []*unstructured.Unstructured) {
+	s.shards = shards
+}
+
+// GetShards returns the shards computed by the last call to GetObject.
+func (s *ShardedInventoryConfigMap) GetShards() []*unstructured.Unstructured {
+	return s.newShards
+}
+
+// shardNames returns the names of the shards referenced by the passed
+// inventory object.
+func shardNames(inv *unstructured.Unstructured) []string {
+	value := inv.GetAnnotations()[ShardsAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// staleShardNames returns the names of the shards referenced by the
+// previous inventory object that are not referenced by the new one.
+func staleShardNames(prevInv, newInv *unstructured.Unstructured) []string {
+	if prevInv == nil {
+		return nil
+	}
+	current := sets.NewString(shardNames(newInv)...)
+	var stale []string
+	for _, name := range shardNames(prevInv) {
+		if !current.Has(name) {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+// isInventoryShard returns true if the passed object is the shard of
+// an inventory object.
+func isInventoryShard(obj *unstructured.Unstructured) bool {
+	_, found := obj.GetLabels()[ShardLabel]
+	return found
+}
+
+// splitObjMap splits the passed object metadata into chunks where the
+// size of each chunk is at most maxSize, unless a single key and value
+// are larger. The keys are sorted, so the same inventory data is always
+// split the same way.
+func splitObjMap(objMap map[string]string, maxSize int) []map[string]string {
+	keys := make([]string, 0, len(objMap))
+	for key := range objMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var chunks []map[string]string
+	var chunk map[string]string
+	size := 0
+	for _, key := range keys {
+		keySize := len(key) + len(objMap[key]) + shardKeyOverhead
+		if chunk == nil || (size+keySize > maxSize && len(chunk) > 0) {
+			chunk = map[string]string{}
+			chunks = append(chunks, chunk)
+			size = 0
+		}
+		chunk[key] = objMap[key]
+		size += keySize
+	}
+	return chunks
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// testShardSize is small enough that a few objects are split across
+// several shards.
+const testShardSize = 200
+
+func wrapTestShardedInventoryObj(inv *unstructured.Unstructured) Inventory {
+	return &ShardedInventoryConfigMap{inv: inv, maxShardSize: testShardSize}
+}
+
+func testPods(n int) []object.ObjMetadata {
+	var objs []object.ObjMetadata
+	for i := 0; i < n; i++ {
+		obj, _ := object.CreateObjMetadata(testNamespace, fmt.Sprintf("pod-%d", i), schema.GroupKind{Kind: "Pod"})
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+func TestShardedInventoryConfigMap(t *testing.T) {
+	tests := map[string]struct {
+		objs           []object.ObjMetadata
+		expectedShards int
+	}{
+		"No objects": {
+			objs:           []object.ObjMetadata{},
+			expectedShards: 0,
+		},
+		"Objects fit in the inventory object": {
+			objs:           testPods(2),
+			expectedShards: 0,
+		},
+		"Objects split across shards": {
+			objs:           testPods(20),
+			expectedShards: 4,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			wrapped := wrapTestShardedInventoryObj(copyInventoryInfo()).(*ShardedInventoryConfigMap)
+			if err := wrapped.Store(tc.objs); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			inv, err := wrapped.GetObject()
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			shards := wrapped.GetShards()
+			if len(shards) != tc.expectedShards {
+				t.Fatalf("expected %d shards, got %d", tc.expectedShards, len(shards))
+			}
+			names := shardNames(inv)
+			if len(names) != len(shards) {
+				t.Fatalf("expected %d shard names, got %d", len(shards), len(names))
+			}
+			for i, shard := range shards {
+				if shard.GetName() != names[i] {
+					t.Errorf("expected shard name %s, got %s", names[i], shard.GetName())
+				}
+				if shard.GetNamespace() != testNamespace {
+					t.Errorf("expected shard namespace %s, got %s", testNamespace, shard.GetNamespace())
+				}
+				if shard.GetLabels()[common.InventoryLabel] != testInventoryLabel {
+					t.Errorf("expected shard inventory label %s, got %s", testInventoryLabel,
+						shard.GetLabels()[common.InventoryLabel])
+				}
+				if !isInventoryShard(shard) {
+					t.Errorf("expected shard label on shard %s", shard.GetName())
+				}
+			}
+
+			loaded := wrapTestShardedInventoryObj(inv).(*ShardedInventoryConfigMap)
+			loaded.SetShards(shards)
+			actualObjs, err := loaded.Load()
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if !object.SetEquals(tc.objs, actualObjs) {
+				t.Errorf("expected objects (%s), got (%s)", tc.objs, actualObjs)
+			}
+		})
+	}
+}
+
+func TestShardedInventoryConfigMap_RemovesShards(t *testing.T) {
+	wrapped := wrapTestShardedInventoryObj(copyInventoryInfo())
+	if err := wrapped.Store(testPods(20)); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	sharded, err := wrapped.GetObject()
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	wrapped = wrapTestShardedInventoryObj(sharded)
+	if err := wrapped.Store(testPods(1)); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	inv, err := wrapped.GetObject()
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if _, found := inv.GetAnnotations()[ShardsAnnotation]; found {
+		t.Errorf("expected no %s annotation", ShardsAnnotation)
+	}
+	if stale := staleShardNames(sharded, inv); len(stale) != len(shardNames(sharded)) {
+		t.Errorf("expected all %d shards to be stale, got %d", len(shardNames(sharded)), len(stale))
+	}
+}
+
+func TestSplitObjMap(t *testing.T) {
+	objMap := buildObjMap(testPods(50))
+	chunks := splitObjMap(objMap, testShardSize)
+	total := 0
+	for _, chunk := range chunks {
+		size := 0
+		for key := range chunk {
+			size += len(key) + shardKeyOverhead
+		}
+		if size > testShardSize {
+			t.Errorf("expected chunk of at most %d bytes, got %d", testShardSize, size)
+		}
+		total += len(chunk)
+	}
+	if total != len(objMap) {
+		t.Errorf("expected %d keys, got %d", len(objMap), total)
+	}
+	again := splitObjMap(objMap, testShardSize)
+	for i := range chunks {
+		for key := range chunks[i] {
+			if _, found := again[i][key]; !found {
+				t.Errorf("expected split to be deterministic, key %s moved", key)
+			}
+		}
+	}
+}
+
+func TestShardedInventory_ClusterRoundTrip(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	invClient, err := NewInventoryClient(tf, wrapTestShardedInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+	inv := copyInventory()
+
+	// Create the inventory with enough objects to need shards.
+	objs := testPods(20)
+	if _, err := invClient.Merge(inv, objs, common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if server.count() != 5 {
+		t.Errorf("expected inventory object and 4 shards, got %d objects", server.count())
+	}
+	assertClusterObjs(t, invClient, inv, objs)
+
+	// Replace with fewer objects; the stale shards are deleted.
+	objs = testPods(10)
+	if err := invClient.Replace(inv, objs, common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if server.count() != 3 {
+		t.Errorf("expected inventory object and 2 shards, got %d objects", server.count())
+	}
+	assertClusterObjs(t, invClient, inv, objs)
+
+	// Deleting the inventory deletes the shards.
+	if err := invClient.DeleteInventoryObj(inv, common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if server.count() != 0 {
+		t.Errorf("expected no objects, got %d", server.count())
+	}
+}
+
+func assertClusterObjs(t *testing.T, invClient *ClusterInventoryClient, inv InventoryInfo, expected []object.ObjMetadata) {
+	invObjs, err := invClient.GetClusterInventoryObjs(inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if len(invObjs) != 1 {
+		t.Fatalf("expected a single inventory object, got %d", len(invObjs))
+	}
+	actual, err := invClient.GetClusterObjs(inv, common.DryRunNone)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if !object.SetEquals(expected, actual) {
+		t.Errorf("expected cluster objs (%s), got (%s)", expected, actual)
+	}
+}
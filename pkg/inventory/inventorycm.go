@@ -44,10 +44,12 @@ func InvInfoToConfigMap(inv InventoryInfo) *unstructured.Unstructured {
 type InventoryConfigMap struct {
 	inv      *unstructured.Unstructured
 	objMetas []object.ObjMetadata
+	statuses map[object.ObjMetadata]ObjectStatus
 }
 
 var _ InventoryInfo = &InventoryConfigMap{}
 var _ Inventory = &InventoryConfigMap{}
+var _ StatusInventory = &InventoryConfigMap{}
 
 func (icm *InventoryConfigMap) Name() string {
 	return icm.inv.GetName()
@@ -99,15 +101,41 @@ func (icm *InventoryConfigMap) Store(objMetas []object.ObjMetadata) error {
 	return nil
 }
 
+// LoadStatus is a StatusInventory interface function returning the
+// status of the objects stored in the values of the wrapped ConfigMap.
+func (icm *InventoryConfigMap) LoadStatus() ([]ObjectStatus, error) {
+	objMap, _, err := unstructured.NestedStringMap(icm.inv.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving object metadata from inventory object")
+	}
+	return loadObjectStatuses(objMap)
+}
+
+// StoreStatus is a StatusInventory interface function implemented to
+// store the status of the objects in the wrapped ConfigMap. Actual
+// storing happens in "GetObject".
+func (icm *InventoryConfigMap) StoreStatus(statuses []ObjectStatus) error {
+	icm.statuses = statusMap(statuses)
+	return nil
+}
+
 // GetObject returns the wrapped object (ConfigMap) as a resource.Info
-// or an error if one occurs.
+// or an error if one occurs. The status of the objects already stored
+// in the ConfigMap is kept, unless a new status was stored.
 func (icm *InventoryConfigMap) GetObject() (*unstructured.Unstructured, error) {
+	prevObjMap, _, err := unstructured.NestedStringMap(icm.inv.Object, "data")
+	if err != nil {
+		return nil, err
+	}
 	// Create the objMap of all the resources, and compute the hash.
-	objMap := buildObjMap(icm.objMetas)
+	objMap, err := buildObjMapWithStatus(icm.objMetas, icm.statuses, prevObjMap)
+	if err != nil {
+		return nil, err
+	}
 	// Create the inventory object by copying the template.
 	invCopy := icm.inv.DeepCopy()
 	// Adds the inventory map to the ConfigMap "data" section.
-	err := unstructured.SetNestedStringMap(invCopy.UnstructuredContent(),
+	err = unstructured.SetNestedStringMap(invCopy.UnstructuredContent(),
 		objMap, "data")
 	if err != nil {
 		return nil, err
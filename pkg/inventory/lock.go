@@ -0,0 +1,316 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the locking of an inventory. A lock prevents two
+// clients from applying or destroying the same inventory at the same
+// time. The LeaseLocker implements the lock with a coordination.k8s.io
+// Lease in the namespace of the inventory object. The lease is renewed
+// while the lock is held, so a lock held by a client that crashed
+// expires after the lease duration. A client that can't renew the
+// lease before it expires has lost the lock, and must stop changing
+// the inventory.
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+// DefaultLeaseDuration is the default duration of the lease that
+// holds the lock of an inventory. The lease is renewed every third of
+// the duration while the lock is held.
+const DefaultLeaseDuration = 60 * time.Second
+
+// InventoryLocker locks an inventory while it is applied or destroyed.
+type InventoryLocker interface {
+	// Lock acquires the lock of the passed inventory, or returns an
+	// InventoryLockedError if another client holds it. The lock is held
+	// until the returned unlock function is called. The returned context
+	// is derived from the passed one, and is cancelled if the lock is
+	// lost before that. The unlock function then returns an
+	// InventoryLockLostError.
+	Lock(ctx context.Context, inv InventoryInfo) (lockCtx context.Context, unlock func() error, err error)
+}
+
+// InventoryLockedError is returned when the lock of an inventory is held
+// by another client.
+type InventoryLockedError struct {
+	// Namespace and Name identify the lock.
+	Namespace string
+	Name      string
+	// Holder identifies the client that holds the lock.
+	Holder string
+	// Since is the time the lock was acquired.
+	Since time.Time
+}
+
+func (e InventoryLockedError) Error() string {
+	return fmt.Sprintf("inventory is locked by %q since %s (lock %s/%s)",
+		e.Holder, e.Since.Format(time.RFC3339), e.Namespace, e.Name)
+}
+
+// InventoryLockLostError is returned when the lock of an inventory was
+// lost while it was held, for example because it could not be renewed
+// before it expired.
+type InventoryLockLostError struct {
+	// Namespace and Name identify the lock.
+	Namespace string
+	Name      string
+}
+
+func (e InventoryLockLostError) Error() string {
+	return fmt.Sprintf("inventory lock %s/%s was lost while it was held", e.Namespace, e.Name)
+}
+
+// LeaseLocker is an InventoryLocker that holds the lock of an inventory
+// in a Lease.
+type LeaseLocker struct {
+	client coordinationv1client.LeasesGetter
+	// Holder identifies this client in the lease.
+	Holder string
+	// LeaseDuration is how long the lock is held without being renewed.
+	LeaseDuration time.Duration
+	// now returns the current time.
+	now func() time.Time
+}
+
+var _ InventoryLocker = &LeaseLocker{}
+
+// NewLeaseLocker returns a LeaseLocker that identifies itself with
+// the passed holder. If the holder is empty, DefaultLockHolder is used.
+func NewLeaseLocker(factory cmdutil.Factory, holder string) (*LeaseLocker, error) {
+	clientset, err := factory.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	if holder == "" {
+		holder = DefaultLockHolder()
+	}
+	return &LeaseLocker{
+		client:        clientset.CoordinationV1(),
+		Holder:        holder,
+		LeaseDuration: DefaultLeaseDuration,
+		now:           time.Now,
+	}, nil
+}
+
+// DefaultLockHolder returns an identity that is unique for this process,
+// made of the hostname, the process id and a random suffix.
+func DefaultLockHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s_%d_%s", hostname, os.Getpid(), common.RandomStr())
+}
+
+// LockName returns the name of the Lease that holds the lock of the
+// passed inventory. It is derived from the inventory id when it is a
+// valid name, since the name of an inventory object may change.
+func LockName(inv InventoryInfo) string {
+	id := strings.ToLower(inv.ID())
+	name := fmt.Sprintf("inventory-lock-%s", id)
+	if id == "" || len(validation.IsDNS1123Subdomain(name)) > 0 {
+		name = fmt.Sprintf("%s-lock", inv.Name())
+	}
+	return name
+}
+
+// Lock acquires the lock of the passed inventory and renews it until
+// the returned unlock function is called or the context is cancelled.
+// The lock is lost if another client took over the lease, or if the
+// lease could not be renewed before it expired.
+func (l *LeaseLocker) Lock(ctx context.Context, inv InventoryInfo) (context.Context, func() error, error) {
+	namespace := inv.Namespace()
+	name := LockName(inv)
+	lease, err := l.acquire(ctx, namespace, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	klog.V(4).Infof("acquired inventory lock %s/%s", namespace, name)
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	lost := false
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(l.LeaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lockCtx.Done():
+				return
+			case <-ticker.C:
+				mu.Lock()
+				renewed, err := l.renew(lockCtx, lease)
+				if err == nil {
+					lease = renewed
+				} else if lockCtx.Err() == nil {
+					klog.Warningf("unable to renew inventory lock %s/%s: %s", namespace, name, err)
+					if apierrors.IsConflict(err) || apierrors.IsNotFound(err) || l.expired(lease) {
+						lost = true
+					}
+				}
+				mu.Unlock()
+				if lost {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	var unlockErr error
+	unlock := func() error {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+			mu.Lock()
+			defer mu.Unlock()
+			if lost {
+				unlockErr = InventoryLockLostError{Namespace: namespace, Name: name}
+				return
+			}
+			unlockErr = l.release(lease)
+			if unlockErr == nil {
+				klog.V(4).Infof("released inventory lock %s/%s", namespace, name)
+			}
+		})
+		return unlockErr
+	}
+	return lockCtx, unlock, nil
+}
+
+// acquire creates the lease with this client as the holder, or takes
+// over the existing lease if it has expired.
+func (l *LeaseLocker) acquire(ctx context.Context, namespace, name string) (*coordinationv1.Lease, error) {
+	leases := l.client.Leases(namespace)
+	now := metav1.NewMicroTime(l.now())
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+		l.setHolder(lease, now)
+		created, err := leases.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return nil, l.lockedError(ctx, namespace, name)
+		}
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to lock inventory: namespace %q of the inventory doesn't exist: %w",
+				namespace, err)
+		}
+		return created, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if l.heldByOther(lease) {
+		return nil, lockedError(lease)
+	}
+	l.setHolder(lease, now)
+	// The update fails with a conflict if another client took over
+	// the lease since it was read.
+	updated, err := leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return nil, l.lockedError(ctx, namespace, name)
+	}
+	return updated, err
+}
+
+// renew updates the renew time of the lease held by this client.
+func (l *LeaseLocker) renew(ctx context.Context, lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	lease = lease.DeepCopy()
+	renewTime := metav1.NewMicroTime(l.now())
+	lease.Spec.RenewTime = &renewTime
+	return l.client.Leases(lease.Namespace).Update(ctx, lease, metav1.UpdateOptions{})
+}
+
+// release deletes the lease, unless another client took it over.
+func (l *LeaseLocker) release(lease *coordinationv1.Lease) error {
+	err := l.client.Leases(lease.Namespace).Delete(context.Background(), lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &lease.UID,
+			ResourceVersion: &lease.ResourceVersion,
+		},
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+func (l *LeaseLocker) setHolder(lease *coordinationv1.Lease, now metav1.MicroTime) {
+	seconds := int32(l.LeaseDuration.Seconds())
+	lease.Spec.HolderIdentity = &l.Holder
+	lease.Spec.LeaseDurationSeconds = &seconds
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+}
+
+// expired returns true if the lease has not been renewed within its
+// duration.
+func (l *LeaseLocker) expired(lease *coordinationv1.Lease) bool {
+	spec := lease.Spec
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	expiry := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)
+	return !l.now().Before(expiry)
+}
+
+// heldByOther returns true if the lease is held by another client and
+// has not expired.
+func (l *LeaseLocker) heldByOther(lease *coordinationv1.Lease) bool {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || *spec.HolderIdentity == l.Holder {
+		return false
+	}
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return !l.expired(lease)
+}
+
+// lockedError fetches the lease to report the client that holds it.
+func (l *LeaseLocker) lockedError(ctx context.Context, namespace, name string) error {
+	lease, err := l.client.Leases(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return lockedError(lease)
+}
+
+func lockedError(lease *coordinationv1.Lease) InventoryLockedError {
+	e := InventoryLockedError{
+		Namespace: lease.Namespace,
+		Name:      lease.Name,
+	}
+	if lease.Spec.HolderIdentity != nil {
+		e.Holder = *lease.Spec.HolderIdentity
+	}
+	if lease.Spec.AcquireTime != nil {
+		e.Since = lease.Spec.AcquireTime.Time
+	}
+	return e
+}
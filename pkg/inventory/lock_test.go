@@ -0,0 +1,165 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func newTestLeaseLocker(clientset *fake.Clientset, holder string, now *time.Time) *LeaseLocker {
+	return &LeaseLocker{
+		client:        clientset.CoordinationV1(),
+		Holder:        holder,
+		LeaseDuration: DefaultLeaseDuration,
+		now:           func() time.Time { return *now },
+	}
+}
+
+func TestLeaseLocker(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clientset := fake.NewSimpleClientset()
+	first := newTestLeaseLocker(clientset, "first", &now)
+	second := newTestLeaseLocker(clientset, "second", &now)
+	inv := copyInventory()
+	ctx := context.Background()
+
+	_, unlock, err := first.Lock(ctx, inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+
+	// The second client can't acquire the lock held by the first one.
+	now = now.Add(10 * time.Second)
+	_, _, err = second.Lock(ctx, inv)
+	lockedErr, ok := err.(InventoryLockedError)
+	if !ok {
+		t.Fatalf("expected InventoryLockedError, got %v", err)
+	}
+	if lockedErr.Holder != "first" {
+		t.Errorf("expected lock holder first, got %s", lockedErr.Holder)
+	}
+	if !lockedErr.Since.Equal(now.Add(-10 * time.Second)) {
+		t.Errorf("expected lock acquired at %s, got %s", now.Add(-10*time.Second), lockedErr.Since)
+	}
+	if !strings.Contains(lockedErr.Error(), `locked by "first"`) {
+		t.Errorf("expected error to show the lock holder, got %q", lockedErr.Error())
+	}
+
+	// Releasing the lock deletes the lease.
+	if err := unlock(); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	_, err = clientset.CoordinationV1().Leases(testNamespace).Get(ctx, LockName(inv), metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected lease to be deleted, got %v", err)
+	}
+
+	// The lock can be acquired again once released.
+	_, unlock, err = second.Lock(ctx, inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+}
+
+func TestLeaseLocker_Expired(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clientset := fake.NewSimpleClientset()
+	crashed := newTestLeaseLocker(clientset, "crashed", &now)
+	other := newTestLeaseLocker(clientset, "other", &now)
+	inv := copyInventory()
+	ctx := context.Background()
+
+	if _, _, err := crashed.Lock(ctx, inv); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	// The lease of a client that stopped renewing it expires.
+	now = now.Add(DefaultLeaseDuration + time.Second)
+	_, unlock, err := other.Lock(ctx, inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	defer func() { _ = unlock() }()
+	lease, err := clientset.CoordinationV1().Leases(testNamespace).Get(ctx, LockName(inv), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if *lease.Spec.HolderIdentity != "other" {
+		t.Errorf("expected lease holder other, got %s", *lease.Spec.HolderIdentity)
+	}
+}
+
+func TestLeaseLocker_NamespaceNotFound(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "leases", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(corev1.Resource("namespaces"), testNamespace)
+	})
+	locker := newTestLeaseLocker(clientset, "first", &now)
+
+	_, _, err := locker.Lock(context.Background(), copyInventory())
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("namespace %q of the inventory doesn't exist", testNamespace)) {
+		t.Errorf("expected error about the missing namespace, got %q", err.Error())
+	}
+}
+
+func TestLeaseLocker_Lost(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clientset := fake.NewSimpleClientset()
+	locker := newTestLeaseLocker(clientset, "first", &now)
+	locker.LeaseDuration = 30 * time.Millisecond
+	inv := copyInventory()
+
+	lockCtx, unlock, err := locker.Lock(context.Background(), inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	// Another client takes over the lease, so it can't be renewed.
+	clientset.PrependReactor("update", "leases", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(coordinationv1.Resource("leases"), LockName(inv), nil)
+	})
+
+	select {
+	case <-lockCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the context to be cancelled when the lock is lost")
+	}
+	err = unlock()
+	if _, ok := err.(InventoryLockLostError); !ok {
+		t.Fatalf("expected InventoryLockLostError, got %v", err)
+	}
+	// The lease of the other client is left alone.
+	if _, err := clientset.CoordinationV1().Leases(testNamespace).Get(context.Background(),
+		LockName(inv), metav1.GetOptions{}); err != nil {
+		t.Errorf("expected lease to be kept, got %v", err)
+	}
+}
+
+func TestLockName(t *testing.T) {
+	if name := LockName(copyInventory()); name != "inventory-lock-"+testInventoryLabel {
+		t.Errorf("expected lock name from the inventory id, got %s", name)
+	}
+	inv := copyInventoryInfo()
+	inv.SetLabels(map[string]string{})
+	if name := LockName(WrapInventoryInfoObj(inv)); name != inventoryObjName+"-lock" {
+		t.Errorf("expected lock name from the inventory name, got %s", name)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the migration of an inventory from one storage
+// backend to another, for example from the ConfigMap inventory to a
+// custom resource inventory. The objects stored in the inventory are
+// not applied again. Only the inventory object is moved, and the
+// owning-inventory annotation of the objects is rewritten if the
+// inventory id changes.
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Migrator moves an inventory from the storage of one InventoryClient
+// to the storage of another.
+type Migrator struct {
+	// From is the client of the inventory to migrate.
+	From InventoryClient
+	// To is the client of the inventory to migrate to.
+	To InventoryClient
+	// Client and Mapper are used to rewrite the owning-inventory
+	// annotation of the objects in the inventory.
+	Client dynamic.Interface
+	Mapper meta.RESTMapper
+}
+
+// NewMigrator returns a Migrator that reads the inventory through a
+// client created by the from factory, and writes it through a client
+// created by the to factory.
+func NewMigrator(factory cmdutil.Factory, from, to InventoryClientFactory) (*Migrator, error) {
+	fromClient, err := from.NewInventoryClient(factory)
+	if err != nil {
+		return nil, err
+	}
+	toClient, err := to.NewInventoryClient(factory)
+	if err != nil {
+		return nil, err
+	}
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		From:   fromClient,
+		To:     toClient,
+		Client: client,
+		Mapper: mapper,
+	}, nil
+}
+
+// MigrateResult describes the changes made by a migration, or the
+// changes that would be made for a dry-run.
+type MigrateResult struct {
+	// Objects are the objects stored in the migrated inventory.
+	Objects []object.ObjMetadata
+	// Annotated are the objects whose owning-inventory annotation was
+	// rewritten with the id of the new inventory.
+	Annotated []object.ObjMetadata
+}
+
+// Migrate moves the inventory identified by fromInv to the inventory
+// identified by toInv. The new inventory is stored first, then the
+// owning-inventory annotation of the objects is rewritten if the id of
+// the inventory changes, and finally the old inventory is deleted.
+// A migration that failed part way can be run again.
+//
+// If both refer to the same cluster object, which happens for backends
+// that store the inventory in the same kind of object like the ConfigMap
+// and sharded ConfigMap backends, the inventory is converted in place:
+// it is stored again by the new backend, and nothing is deleted.
+//
+// Returns an error if the old inventory does not exist, if it can not be
+// converted in place because it has shards the new backend does not
+// read, or if the new inventory already exists and stores objects that
+// are not in the old inventory.
+func (m *Migrator) Migrate(fromInv, toInv InventoryInfo, dryRun common.DryRunStrategy) (MigrateResult, error) {
+	result := MigrateResult{}
+	fromObj, err := m.From.GetClusterInventoryInfo(fromInv, common.DryRunNone)
+	if err != nil {
+		return result, err
+	}
+	if fromObj == nil {
+		return result, fmt.Errorf("inventory %s/%s not found", fromInv.Namespace(), fromInv.Name())
+	}
+	toObj, err := m.To.GetClusterInventoryInfo(toInv, common.DryRunNone)
+	if err != nil {
+		return result, err
+	}
+	objs, err := m.From.GetClusterObjs(fromInv, common.DryRunNone)
+	if err != nil {
+		return result, err
+	}
+	statuses, err := m.From.GetClusterObjStatuses(fromInv)
+	if err != nil {
+		return result, err
+	}
+	result.Objects = objs
+	if toObj != nil && sameObject(fromObj, toObj) {
+		return result, m.convert(fromInv, toInv, fromObj, objs, statuses, dryRun)
+	}
+	if toObj != nil {
+		// The new inventory may exist from a migration that failed part
+		// way, in which case it only stores objects of the old inventory.
+		toObjs, err := m.To.GetClusterObjs(toInv, common.DryRunNone)
+		if err != nil {
+			return result, err
+		}
+		if extra := object.SetDiff(toObjs, objs); len(extra) > 0 {
+			return result, fmt.Errorf("target inventory %s/%s already exists with %d other objects",
+				toInv.Namespace(), toInv.Name(), len(extra))
+		}
+	}
+
+	klog.V(4).Infof("migrating inventory %s/%s (%d objects) to %s/%s", fromInv.Namespace(), fromInv.Name(),
+		len(objs), toInv.Namespace(), toInv.Name())
+	if _, err := m.To.Merge(toInv, objs, dryRun); err != nil {
+		return result, err
+	}
+	if len(statuses) > 0 {
+		if err := m.To.ReplaceWithStatus(toInv, objs, statuses, dryRun); err != nil {
+			return result, err
+		}
+	}
+	if fromInv.ID() != toInv.ID() {
+		for _, id := range objs {
+			annotated, err := m.rewriteOwningInventory(id, fromInv.ID(), toInv.ID(), dryRun)
+			if err != nil {
+				return result, err
+			}
+			if annotated {
+				result.Annotated = append(result.Annotated, id)
+			}
+		}
+	}
+	return result, m.From.DeleteInventoryObj(fromInv, dryRun)
+}
+
+// convert stores the passed objects and statuses of the inventory again
+// with the new backend, when both backends store it in the same cluster
+// object. The inventory id does not change, so the objects are not
+// annotated again.
+func (m *Migrator) convert(fromInv, toInv InventoryInfo, fromObj *unstructured.Unstructured,
+	objs []object.ObjMetadata, statuses []ObjectStatus, dryRun common.DryRunStrategy) error {
+	// The shards of the old inventory would be left behind, and their
+	// objects lost, if the new backend does not read them.
+	if len(shardNames(fromObj)) > 0 {
+		toObjs, err := m.To.GetClusterObjs(toInv, common.DryRunNone)
+		if err != nil {
+			return err
+		}
+		if missing := object.SetDiff(objs, toObjs); len(missing) > 0 {
+			return fmt.Errorf("inventory %s/%s is stored in shards that the target backend does not read; "+
+				"migrate to an inventory with a different name", fromInv.Namespace(), fromInv.Name())
+		}
+	}
+	klog.V(4).Infof("converting inventory %s/%s (%d objects) in place", fromInv.Namespace(), fromInv.Name(),
+		len(objs))
+	return m.To.ReplaceWithStatus(toInv, objs, statuses, dryRun)
+}
+
+// rewriteOwningInventory sets the owning-inventory annotation of the
+// object identified by the passed id to the passed new inventory id, if
+// it is owned by the inventory with the passed old id. Returns true if
+// the annotation was rewritten, or would be for a dry-run.
+func (m *Migrator) rewriteOwningInventory(id object.ObjMetadata, oldID, newID string,
+	dryRun common.DryRunStrategy) (bool, error) {
+	mapping, err := m.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return false, err
+	}
+	client := m.Client.Resource(mapping.Resource).Namespace(id.Namespace)
+	obj, err := client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if obj.GetAnnotations()[owningInventoryKey] != oldID {
+		return false, nil
+	}
+	if dryRun.ClientOrServerDryRun() {
+		return true, nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{owningInventoryKey: newID},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	klog.V(4).Infof("rewriting owning inventory of %s to %s", id, newID)
+	_, err = client.Patch(context.TODO(), id.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err == nil, err
+}
+
+// sameObject returns true if the passed objects are the same cluster
+// object.
+func sameObject(a, b *unstructured.Unstructured) bool {
+	if a.GetUID() != "" && b.GetUID() != "" {
+		return a.GetUID() == b.GetUID()
+	}
+	return a.GroupVersionKind().GroupKind() == b.GroupVersionKind().GroupKind() &&
+		a.GetNamespace() == b.GetNamespace() && a.GetName() == b.GetName()
+}
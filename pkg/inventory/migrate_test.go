@@ -0,0 +1,210 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestMigrator(t *testing.T) {
+	tests := map[string]struct {
+		dryRun            common.DryRunStrategy
+		expectedInventory string
+		expectedOwner     string
+	}{
+		"Migrate to a new inventory": {
+			dryRun:            common.DryRunNone,
+			expectedInventory: "new-inventory-obj",
+			expectedOwner:     "new-app-label",
+		},
+		"Dry-run migration changes nothing": {
+			dryRun:            common.DryRunClient,
+			expectedInventory: inventoryObjName,
+			expectedOwner:     testInventoryLabel,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+			defer tf.Cleanup()
+			server := newFakeConfigMapServer()
+			tf.UnstructuredClient = server.client()
+			tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+			mapper, err := tf.ToRESTMapper()
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+
+			from, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			from.builderFunc = server.builder
+			to, err := NewInventoryClient(tf, wrapTestShardedInventoryObj, InvInfoToConfigMap)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			to.builderFunc = server.builder
+
+			owned := pod1.DeepCopy()
+			owned.SetAnnotations(map[string]string{owningInventoryKey: testInventoryLabel})
+			other := pod2.DeepCopy()
+			other.SetAnnotations(map[string]string{owningInventoryKey: "other"})
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), owned, other)
+			migrator := &Migrator{From: from, To: to, Client: client, Mapper: mapper}
+
+			fromInv := copyInventory()
+			toObj := copyInventoryInfo()
+			toObj.SetName("new-inventory-obj")
+			toObj.SetLabels(map[string]string{common.InventoryLabel: "new-app-label"})
+			toInv := WrapInventoryInfoObj(toObj)
+			objs := []object.ObjMetadata{
+				ignoreErrInfoToObjMeta(pod1Info),
+				ignoreErrInfoToObjMeta(pod2Info),
+				ignoreErrInfoToObjMeta(pod3Info),
+			}
+			if _, err := from.Merge(fromInv, objs, common.DryRunNone); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+
+			result, err := migrator.Migrate(fromInv, toInv, tc.dryRun)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if !object.SetEquals(objs, result.Objects) {
+				t.Errorf("expected migrated objects (%s), got (%s)", objs, result.Objects)
+			}
+			// Only the object owned by the old inventory is annotated.
+			expectedAnnotated := []object.ObjMetadata{ignoreErrInfoToObjMeta(pod1Info)}
+			if !object.SetEquals(expectedAnnotated, result.Annotated) {
+				t.Errorf("expected annotated objects (%s), got (%s)", expectedAnnotated, result.Annotated)
+			}
+
+			if server.count() != 1 {
+				t.Fatalf("expected a single inventory object, got %d", server.count())
+			}
+			if _, found := server.objects[tc.expectedInventory]; !found {
+				t.Errorf("expected inventory object %s", tc.expectedInventory)
+			}
+			podGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+			for _, pod := range []*unstructured.Unstructured{owned, other} {
+				live, err := client.Resource(podGVR).Namespace(testNamespace).Get(context.TODO(), pod.GetName(), metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+				expected := pod.GetAnnotations()[owningInventoryKey]
+				if pod == owned {
+					expected = tc.expectedOwner
+				}
+				if actual := live.GetAnnotations()[owningInventoryKey]; actual != expected {
+					t.Errorf("expected %s owned by %s, got %s", pod.GetName(), expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrator_InPlace(t *testing.T) {
+	tests := map[string]struct {
+		fromFunc    InventoryFactoryFunc
+		toFunc      InventoryFactoryFunc
+		objs        []object.ObjMetadata
+		expectedErr bool
+	}{
+		"ConfigMap converted to sharded ConfigMap in place": {
+			fromFunc: WrapInventoryObj,
+			toFunc:   wrapTestShardedInventoryObj,
+			objs:     testPods(20),
+		},
+		"Sharded ConfigMap without shards converted to ConfigMap in place": {
+			fromFunc: wrapTestShardedInventoryObj,
+			toFunc:   WrapInventoryObj,
+			objs:     testPods(1),
+		},
+		"Sharded ConfigMap with shards can not be converted to ConfigMap in place": {
+			fromFunc:    wrapTestShardedInventoryObj,
+			toFunc:      WrapInventoryObj,
+			objs:        testPods(20),
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+			defer tf.Cleanup()
+			server := newFakeConfigMapServer()
+			tf.UnstructuredClient = server.client()
+			tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+			from, err := NewInventoryClient(tf, tc.fromFunc, InvInfoToConfigMap)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			from.builderFunc = server.builder
+			to, err := NewInventoryClient(tf, tc.toFunc, InvInfoToConfigMap)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			to.builderFunc = server.builder
+			inv := copyInventory()
+			if _, err := from.Merge(inv, tc.objs, common.DryRunNone); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+
+			// Both backends find the inventory in the same object, since
+			// the inventory id does not change.
+			migrator := &Migrator{From: from, To: to}
+			result, err := migrator.Migrate(inv, copyInventory(), common.DryRunNone)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected error converting the inventory in place")
+				}
+				if !strings.Contains(err.Error(), "shards that the target backend does not read") {
+					t.Errorf("expected error about the shards, got %q", err.Error())
+				}
+				actual, err := from.GetClusterObjs(inv, common.DryRunNone)
+				if err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+				if !object.SetEquals(tc.objs, actual) {
+					t.Errorf("expected inventory objects (%s) to be kept, got (%s)", tc.objs, actual)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+				if !object.SetEquals(tc.objs, result.Objects) {
+					t.Errorf("expected migrated objects (%s), got (%s)", tc.objs, result.Objects)
+				}
+				if len(result.Annotated) > 0 {
+					t.Errorf("expected no annotated objects, got (%s)", result.Annotated)
+				}
+				actual, err := to.GetClusterObjs(inv, common.DryRunNone)
+				if err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+				if !object.SetEquals(tc.objs, actual) {
+					t.Errorf("expected inventory objects (%s), got (%s)", tc.objs, actual)
+				}
+			}
+			// The inventory object is kept in both cases.
+			if _, found := server.objects[inventoryObjName]; !found {
+				t.Errorf("expected inventory object %s to be kept", inventoryObjName)
+			}
+		})
+	}
+}
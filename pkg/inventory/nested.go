@@ -0,0 +1,271 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains nested inventories. An inventory can declare the
+// inventories of its sub-packages as child inventories, so that a tree
+// of packages can be destroyed or checked as a whole. The children are
+// declared in an annotation on the inventory object template of the
+// parent package, and are copied to the inventory object in the cluster
+// whenever the objects of the package are merged into it. The children
+// of a child inventory are read from
+// its inventory object in the cluster. Child inventories are wrapped
+// with the InventoryFactoryFunc of the inventory client, and have the
+// type of their parent unless they declare their own.
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+// ChildrenAnnotation is the annotation on the inventory object that
+// declares its child inventories as a JSON list, for example:
+//
+//	[{"namespace": "platform", "name": "inventory-monitoring", "inventoryID": "monitoring"}]
+//
+// A child can also declare the apiVersion and kind of its inventory
+// object. The default is the apiVersion and kind of the parent.
+const ChildrenAnnotation = "cli-utils.sigs.k8s.io/inventory-children"
+
+// NestedInventoryInfo is implemented by InventoryInfo implementations
+// that can declare child inventories.
+type NestedInventoryInfo interface {
+	InventoryInfo
+	// Children returns the child inventories declared by the inventory.
+	Children() ([]InventoryInfo, error)
+}
+
+// NestedInventoryClient is implemented by InventoryClients that can
+// resolve the child inventories declared on an inventory.
+type NestedInventoryClient interface {
+	// Children returns the child inventories declared on the passed
+	// inventory.
+	Children(inv InventoryInfo) ([]InventoryInfo, error)
+	// ClusterChildren returns the child inventories declared on the
+	// inventory object of the passed inventory in the cluster. There
+	// are no children if the inventory object doesn't exist.
+	ClusterChildren(inv InventoryInfo) ([]InventoryInfo, error)
+}
+
+// childRecord is the serialized form of a child inventory.
+type childRecord struct {
+	APIVersion  string `json:"apiVersion,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	InventoryID string `json:"inventoryID"`
+}
+
+// Children is a NestedInventoryInfo interface function returning the
+// child inventories declared on the wrapped ConfigMap.
+func (icm *InventoryConfigMap) Children() ([]InventoryInfo, error) {
+	return LoadChildren(icm.inv, WrapInventoryObj)
+}
+
+var _ NestedInventoryInfo = &InventoryConfigMap{}
+
+// Children is a NestedInventoryClient interface function returning the
+// child inventories declared on the local inventory object.
+func (cic *ClusterInventoryClient) Children(inv InventoryInfo) ([]InventoryInfo, error) {
+	obj := cic.invToUnstructuredFunc(inv)
+	if obj == nil {
+		return []InventoryInfo{}, nil
+	}
+	return LoadChildren(obj, cic.InventoryFactoryFunc)
+}
+
+// ClusterChildren is a NestedInventoryClient interface function
+// returning the child inventories declared on the inventory object
+// in the cluster.
+func (cic *ClusterInventoryClient) ClusterChildren(inv InventoryInfo) ([]InventoryInfo, error) {
+	// A dry-run read merges duplicate inventory objects without
+	// storing the result.
+	clusterInv, err := cic.GetClusterInventoryInfo(inv, common.DryRunClient)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInv == nil {
+		return []InventoryInfo{}, nil
+	}
+	return LoadChildren(clusterInv, cic.InventoryFactoryFunc)
+}
+
+var _ NestedInventoryClient = &ClusterInventoryClient{}
+
+// syncChildren sets the children annotation of the passed cluster
+// inventory object to the children declared on the passed local
+// inventory object. Returns true if the annotation changed.
+func syncChildren(clusterInv, localInv *unstructured.Unstructured) bool {
+	if localInv == nil {
+		return false
+	}
+	value, found := localInv.GetAnnotations()[ChildrenAnnotation]
+	annotations := clusterInv.GetAnnotations()
+	current, stored := annotations[ChildrenAnnotation]
+	if found == stored && value == current {
+		return false
+	}
+	if found {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[ChildrenAnnotation] = value
+	} else {
+		delete(annotations, ChildrenAnnotation)
+	}
+	clusterInv.SetAnnotations(annotations)
+	return true
+}
+
+// LoadChildren returns the child inventories declared on the passed
+// inventory object, wrapped with the passed InventoryFactoryFunc. An
+// empty slice is returned if the inventory object has no children.
+func LoadChildren(inv *unstructured.Unstructured, invFunc InventoryFactoryFunc) ([]InventoryInfo, error) {
+	value, found := inv.GetAnnotations()[ChildrenAnnotation]
+	if !found || value == "" {
+		return []InventoryInfo{}, nil
+	}
+	var records []childRecord
+	if err := json.Unmarshal([]byte(value), &records); err != nil {
+		return nil, fmt.Errorf("unable to parse %s annotation on inventory %s/%s: %w",
+			ChildrenAnnotation, inv.GetNamespace(), inv.GetName(), err)
+	}
+	children := make([]InventoryInfo, 0, len(records))
+	for _, r := range records {
+		if r.Name == "" || r.InventoryID == "" {
+			return nil, fmt.Errorf("child inventory of %s/%s must have a name and an inventoryID",
+				inv.GetNamespace(), inv.GetName())
+		}
+		child := &unstructured.Unstructured{}
+		child.SetAPIVersion(inv.GetAPIVersion())
+		child.SetKind(inv.GetKind())
+		if r.APIVersion != "" || r.Kind != "" {
+			child.SetAPIVersion(r.APIVersion)
+			child.SetKind(r.Kind)
+		}
+		child.SetNamespace(r.Namespace)
+		child.SetName(r.Name)
+		child.SetLabels(map[string]string{common.InventoryLabel: r.InventoryID})
+		info, ok := invFunc(child).(InventoryInfo)
+		if !ok {
+			return nil, fmt.Errorf("child inventory %s/%s of %s/%s is not an InventoryInfo",
+				r.Namespace, r.Name, inv.GetNamespace(), inv.GetName())
+		}
+		children = append(children, info)
+	}
+	return children, nil
+}
+
+// InventoryCycleError is returned when an inventory is its own
+// descendant.
+type InventoryCycleError struct {
+	ID string
+}
+
+func (e InventoryCycleError) Error() string {
+	return fmt.Sprintf("inventory %q is a child of itself", e.ID)
+}
+
+// InventoryTree is an inventory with its child inventories.
+type InventoryTree struct {
+	Info InventoryInfo
+	// Parent is the tree of the parent inventory, or nil for the root.
+	Parent   *InventoryTree
+	Children []*InventoryTree
+}
+
+// NewInventoryTree returns the tree of inventories rooted at the passed
+// inventory. The children of the passed inventory are declared on it,
+// and the children of child inventories are read from their inventory
+// objects in the cluster. A child inventory that doesn't exist in the
+// cluster has no children. Only the passed inventory can have children
+// if the inventory client is not a NestedInventoryClient.
+func NewInventoryTree(invClient InventoryClient, inv InventoryInfo) (*InventoryTree, error) {
+	return newInventoryTree(invClient, inv, nil, map[string]bool{})
+}
+
+func newInventoryTree(invClient InventoryClient, inv InventoryInfo, parent *InventoryTree,
+	ancestors map[string]bool) (*InventoryTree, error) {
+	if ancestors[inv.ID()] {
+		return nil, InventoryCycleError{ID: inv.ID()}
+	}
+	ancestors[inv.ID()] = true
+	defer delete(ancestors, inv.ID())
+
+	tree := &InventoryTree{Info: inv, Parent: parent}
+	children, err := loadChildren(invClient, inv, parent == nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childTree, err := newInventoryTree(invClient, child, tree, ancestors)
+		if err != nil {
+			return nil, err
+		}
+		tree.Children = append(tree.Children, childTree)
+	}
+	return tree, nil
+}
+
+// loadChildren returns the child inventories of the passed inventory.
+// The children of the root are declared on the inventory itself, and
+// the children of the other inventories on their inventory objects in
+// the cluster.
+func loadChildren(invClient InventoryClient, inv InventoryInfo, root bool) ([]InventoryInfo, error) {
+	nestedClient, ok := invClient.(NestedInventoryClient)
+	switch {
+	case ok && root:
+		return nestedClient.Children(inv)
+	case ok:
+		return nestedClient.ClusterChildren(inv)
+	case root:
+		if nested, ok := inv.(NestedInventoryInfo); ok {
+			return nested.Children()
+		}
+	}
+	return []InventoryInfo{}, nil
+}
+
+// Depth returns the depth of the inventory in the tree. The depth of
+// the root is zero.
+func (t *InventoryTree) Depth() int {
+	depth := 0
+	for p := t.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+// HasChildren returns true if the inventory has child inventories.
+func (t *InventoryTree) HasChildren() bool {
+	return len(t.Children) > 0
+}
+
+// Walk calls the passed function for every inventory in the tree,
+// parents before their children.
+func (t *InventoryTree) Walk(fn func(tree *InventoryTree) error) error {
+	if err := fn(t); err != nil {
+		return err
+	}
+	for _, child := range t.Children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DestroyOrder returns the inventories in the tree in the order they
+// must be destroyed: children before their parents, since a parent
+// package usually provides the namespaces and CRDs of its sub-packages.
+func (t *InventoryTree) DestroyOrder() []*InventoryTree {
+	var trees []*InventoryTree
+	for _, child := range t.Children {
+		trees = append(trees, child.DestroyOrder()...)
+	}
+	return append(trees, t)
+}
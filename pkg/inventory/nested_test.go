@@ -0,0 +1,207 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newChildInventory(name, id, children string) *unstructured.Unstructured {
+	obj := inventoryObj.DeepCopy()
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{common.InventoryLabel: id})
+	if children != "" {
+		obj.SetAnnotations(map[string]string{ChildrenAnnotation: children})
+	}
+	return obj
+}
+
+func TestLoadChildren(t *testing.T) {
+	testCases := map[string]struct {
+		annotation string
+		expected   []string
+		kinds      []string
+		isError    bool
+	}{
+		"no annotation": {
+			expected: []string{},
+		},
+		"children": {
+			annotation: `[{"namespace": "a", "name": "inv-a", "inventoryID": "id-a"},
+				{"namespace": "b", "name": "inv-b", "inventoryID": "id-b"}]`,
+			expected: []string{"a/inv-a/id-a", "b/inv-b/id-b"},
+			kinds:    []string{"ConfigMap", "ConfigMap"},
+		},
+		"child with its own kind": {
+			annotation: `[{"apiVersion": "custom.io/v1", "kind": "Inventory", "namespace": "a",
+				"name": "inv-a", "inventoryID": "id-a"}]`,
+			expected: []string{"a/inv-a/id-a"},
+			kinds:    []string{"Inventory"},
+		},
+		"invalid annotation": {
+			annotation: "inv-a",
+			isError:    true,
+		},
+		"missing inventory id": {
+			annotation: `[{"namespace": "a", "name": "inv-a"}]`,
+			isError:    true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			inv := newChildInventory("parent", "parent-id", tc.annotation)
+			var kinds []string
+			invFunc := func(obj *unstructured.Unstructured) Inventory {
+				kinds = append(kinds, obj.GetKind())
+				return WrapInventoryObj(obj)
+			}
+			children, err := LoadChildren(inv, invFunc)
+			if tc.isError {
+				if err == nil {
+					t.Fatalf("expected error but received none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if len(children) != len(tc.expected) {
+				t.Fatalf("expected %d children, got %d", len(tc.expected), len(children))
+			}
+			for i, child := range children {
+				actual := child.Namespace() + "/" + child.Name() + "/" + child.ID()
+				if actual != tc.expected[i] {
+					t.Errorf("expected child %s, got %s", tc.expected[i], actual)
+				}
+				if kinds[i] != tc.kinds[i] {
+					t.Errorf("expected child of kind %s, got %s", tc.kinds[i], kinds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewInventoryTree(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+	invClient, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+
+	// The grandchild is declared on the child in the cluster, and the
+	// missing child doesn't exist in the cluster.
+	server.store(newChildInventory("child", "child-id",
+		`[{"namespace": "`+testNamespace+`", "name": "grandchild", "inventoryID": "grandchild-id"}]`))
+	server.store(newChildInventory("grandchild", "grandchild-id", ""))
+	root := WrapInventoryInfoObj(newChildInventory("root", "root-id",
+		`[{"namespace": "`+testNamespace+`", "name": "child", "inventoryID": "child-id"},
+		  {"namespace": "`+testNamespace+`", "name": "missing", "inventoryID": "missing-id"}]`))
+
+	tree, err := NewInventoryTree(invClient, root)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	var walked []string
+	var depths []int
+	_ = tree.Walk(func(tree *InventoryTree) error {
+		walked = append(walked, tree.Info.ID())
+		depths = append(depths, tree.Depth())
+		return nil
+	})
+	expectedWalk := []string{"root-id", "child-id", "grandchild-id", "missing-id"}
+	expectedDepths := []int{0, 1, 2, 1}
+	for i := range expectedWalk {
+		if i >= len(walked) || walked[i] != expectedWalk[i] || depths[i] != expectedDepths[i] {
+			t.Fatalf("expected walk %v with depths %v, got %v with depths %v",
+				expectedWalk, expectedDepths, walked, depths)
+		}
+	}
+
+	var destroyed []string
+	for _, tree := range tree.DestroyOrder() {
+		destroyed = append(destroyed, tree.Info.ID())
+	}
+	expectedDestroy := []string{"grandchild-id", "child-id", "missing-id", "root-id"}
+	for i := range expectedDestroy {
+		if i >= len(destroyed) || destroyed[i] != expectedDestroy[i] {
+			t.Fatalf("expected destroy order %v, got %v", expectedDestroy, destroyed)
+		}
+	}
+
+	// The grandchild declares the root as its child.
+	server.store(newChildInventory("grandchild", "grandchild-id",
+		`[{"namespace": "`+testNamespace+`", "name": "root", "inventoryID": "root-id"}]`))
+	_, err = NewInventoryTree(invClient, root)
+	if _, ok := err.(InventoryCycleError); !ok {
+		t.Errorf("expected InventoryCycleError, got %v", err)
+	}
+}
+
+func TestClusterChildren_Updated(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+	invClient, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+	objs := []object.ObjMetadata{ignoreErrInfoToObjMeta(pod1Info)}
+
+	// The children declared by the local inventory are stored with the
+	// inventory object, and updated when the local inventory changes,
+	// even if the objects of the package are the same.
+	steps := []struct {
+		annotation string
+		expected   []string
+	}{
+		{
+			annotation: `[{"namespace": "a", "name": "inv-a", "inventoryID": "id-a"}]`,
+			expected:   []string{"a/inv-a/id-a"},
+		},
+		{
+			annotation: `[{"namespace": "a", "name": "inv-a", "inventoryID": "id-a"},
+				{"namespace": "b", "name": "inv-b", "inventoryID": "id-b"}]`,
+			expected: []string{"a/inv-a/id-a", "b/inv-b/id-b"},
+		},
+		{
+			annotation: "",
+			expected:   []string{},
+		},
+	}
+	for i, step := range steps {
+		inv := WrapInventoryInfoObj(newChildInventory(inventoryObjName, testInventoryLabel, step.annotation))
+		if _, err := invClient.Merge(inv, objs, common.DryRunNone); err != nil {
+			t.Fatalf("step %d: unexpected error received: %s", i, err)
+		}
+		children, err := invClient.ClusterChildren(inv)
+		if err != nil {
+			t.Fatalf("step %d: unexpected error received: %s", i, err)
+		}
+		actual := []string{}
+		for _, child := range children {
+			actual = append(actual, child.Namespace()+"/"+child.Name()+"/"+child.ID())
+		}
+		if !reflect.DeepEqual(step.expected, actual) {
+			t.Errorf("step %d: expected children %v, got %v", i, step.expected, actual)
+		}
+	}
+	if server.count() != 1 {
+		t.Errorf("expected a single inventory object, got %d", server.count())
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the lookup of orphaned objects. An object is
+// orphaned if its owning-inventory annotation refers to an inventory
+// that no longer exists, which happens when an inventory object is
+// deleted without destroying the objects in it.
+
+package inventory
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// ListOwnedObjects returns the live objects with an owning-inventory
+// annotation, in the passed namespace or in all namespaces if the
+// namespace is empty. Cluster-scoped objects are only included for all
+// namespaces. Every resource type that can be listed is searched, so
+// this can be slow on large clusters. Resource types that can't be
+// listed, for example because of missing permissions, are skipped.
+func ListOwnedObjects(factory cmdutil.Factory, namespace string) ([]*unstructured.Unstructured, error) {
+	dc, err := factory.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	resourceLists, err := discovery.ServerPreferredResources(dc)
+	if err != nil {
+		// Some API groups may not be available, but the resources of
+		// the other groups can still be searched.
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, err
+		}
+		klog.V(4).Infof("partial discovery of resources: %s", err)
+	}
+	var owned []*unstructured.Unstructured
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resourceList.APIResources {
+			if !sets.NewString(r.Verbs...).Has("list") {
+				continue
+			}
+			if namespace != "" && !r.Namespaced {
+				continue
+			}
+			objs, err := listOwnedObjects(client, gv.WithResource(r.Name), r.Namespaced, namespace)
+			if err != nil {
+				return nil, err
+			}
+			owned = append(owned, objs...)
+		}
+	}
+	return owned, nil
+}
+
+// listOwnedObjects returns the objects of the passed resource with an
+// owning-inventory annotation.
+func listOwnedObjects(client dynamic.Interface, gvr schema.GroupVersionResource, namespaced bool,
+	namespace string) ([]*unstructured.Unstructured, error) {
+	var resourceClient dynamic.ResourceInterface = client.Resource(gvr)
+	if namespaced {
+		resourceClient = client.Resource(gvr).Namespace(namespace)
+	}
+	list, err := resourceClient.List(context.TODO(), metav1.ListOptions{})
+	if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+		klog.V(4).Infof("skipping resource %s: %s", gvr, err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var owned []*unstructured.Unstructured
+	for i := range list.Items {
+		if _, found := list.Items[i].GetAnnotations()[owningInventoryKey]; found {
+			owned = append(owned, &list.Items[i])
+		}
+	}
+	return owned, nil
+}
+
+// FindOrphans returns the passed objects whose owning-inventory
+// annotation does not match any of the passed inventories. Objects
+// without the annotation are not orphans.
+func FindOrphans(invs []InventoryInfo, objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	var orphans []*unstructured.Unstructured
+	for _, obj := range objs {
+		if OwningInventory(obj) == "" {
+			continue
+		}
+		orphaned := true
+		for _, inv := range invs {
+			if InventoryIDMatch(inv, obj) != NoMatch {
+				orphaned = false
+				break
+			}
+		}
+		if orphaned {
+			orphans = append(orphans, obj)
+		}
+	}
+	return orphans
+}
+
+// OwningInventory returns the id of the inventory in the
+// owning-inventory annotation of the passed object, or an empty string
+// if the object has no such annotation.
+func OwningInventory(obj *unstructured.Unstructured) string {
+	return obj.GetAnnotations()[owningInventoryKey]
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+func TestListClusterInventoryObjs(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	invClient, err := NewInventoryClient(tf, wrapTestShardedInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+	// The sharded inventory is stored across several ConfigMaps.
+	if _, err := invClient.Merge(copyInventory(), testPods(20), common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	// ConfigMaps without the inventory-id label are not inventories.
+	other := copyInventoryInfo()
+	other.SetName("not-an-inventory")
+	other.SetLabels(nil)
+	server.store(other)
+
+	for name, namespace := range map[string]string{
+		"Namespace":      testNamespace,
+		"All namespaces": "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			invObjs, err := invClient.ListClusterInventoryObjs(schema.GroupKind{Kind: "ConfigMap"}, namespace)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if len(invObjs) != 1 {
+				t.Fatalf("expected a single inventory object, got %d", len(invObjs))
+			}
+			if invObjs[0].GetName() != inventoryObjName {
+				t.Errorf("expected inventory object %s, got %s", inventoryObjName, invObjs[0].GetName())
+			}
+		})
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	owned := func(name, inv string) *unstructured.Unstructured {
+		obj := pod1.DeepCopy()
+		obj.SetName(name)
+		if inv != "" {
+			obj.SetAnnotations(map[string]string{owningInventoryKey: inv})
+		}
+		return obj
+	}
+	objs := []*unstructured.Unstructured{
+		owned("owned", testInventoryLabel),
+		owned("orphaned", "deleted-inventory"),
+		owned("unowned", ""),
+	}
+
+	tests := map[string]struct {
+		invs     []InventoryInfo
+		expected []string
+	}{
+		"No inventories": {
+			invs:     []InventoryInfo{},
+			expected: []string{"owned", "orphaned"},
+		},
+		"Inventory exists": {
+			invs:     []InventoryInfo{copyInventory()},
+			expected: []string{"orphaned"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			orphans := FindOrphans(tc.invs, objs)
+			if len(orphans) != len(tc.expected) {
+				t.Fatalf("expected %d orphans, got %d", len(tc.expected), len(orphans))
+			}
+			for i, obj := range orphans {
+				if obj.GetName() != tc.expected[i] {
+					t.Errorf("expected orphan %s, got %s", tc.expected[i], obj.GetName())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the revision history of an inventory. Every
+// time the set of objects stored in an inventory is replaced, a
+// revision with the new set of objects is recorded in an annotation
+// on the inventory object. Only the most recent revisions are kept.
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+const (
+	// RevisionsAnnotation is the annotation on the inventory object
+	// that stores the revision history as a JSON list, with the most
+	// recent revision first.
+	RevisionsAnnotation = "cli-utils.sigs.k8s.io/inventory-revisions"
+
+	// DefaultRevisionHistoryLimit is the default number of revisions
+	// kept in the inventory object.
+	DefaultRevisionHistoryLimit = 10
+
+	// maxRevisionsSize is the maximum size in bytes of the revisions
+	// annotation. The oldest revisions are dropped until the encoded
+	// history fits, since the total size of the annotations on an
+	// object is limited to 256KiB. The most recent revision is always
+	// kept, without its objects if they do not fit.
+	maxRevisionsSize = 128 * 1024
+)
+
+// Revision is a snapshot of the set of objects stored in an inventory.
+type Revision struct {
+	// Number is the revision number. It is incremented by one for
+	// every new revision.
+	Number int64
+	// Timestamp is the time the revision was recorded.
+	Timestamp time.Time
+	// Applier identifies the client that recorded the revision.
+	Applier string
+	// Hash is the hash of the set of objects, as computed by object.Hash.
+	Hash string
+	// Objects is the set of objects stored in the inventory. It is empty
+	// if the objects of a large inventory did not fit in the history.
+	Objects []object.ObjMetadata
+}
+
+// DefaultApplier returns the identity recorded in revisions when the
+// client doesn't set one, made of the name of the user running the
+// process and the hostname, like jane@laptop.
+func DefaultApplier() string {
+	username := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		username = u.Username
+	} else if env := os.Getenv("USER"); env != "" {
+		username = env
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s@%s", username, hostname)
+}
+
+// revisionRecord is the serialized form of a Revision.
+type revisionRecord struct {
+	Number    int64       `json:"revision"`
+	Timestamp metav1.Time `json:"timestamp"`
+	Applier   string      `json:"applier,omitempty"`
+	Hash      string      `json:"hash"`
+	Objects   []string    `json:"objects,omitempty"`
+}
+
+// RevisionNotFoundError is returned when a revision is not in the
+// revision history of an inventory.
+type RevisionNotFoundError struct {
+	Number int64
+}
+
+func (e RevisionNotFoundError) Error() string {
+	return fmt.Sprintf("revision %d not found in the inventory revision history", e.Number)
+}
+
+// LoadRevisions returns the revisions stored on the passed inventory
+// object, with the most recent revision first. An empty slice is
+// returned if the inventory object has no revisions.
+func LoadRevisions(inv *unstructured.Unstructured) ([]Revision, error) {
+	value, found := inv.GetAnnotations()[RevisionsAnnotation]
+	if !found || value == "" {
+		return []Revision{}, nil
+	}
+	var records []revisionRecord
+	if err := json.Unmarshal([]byte(value), &records); err != nil {
+		return nil, fmt.Errorf("unable to parse %s annotation on inventory %s/%s: %w",
+			RevisionsAnnotation, inv.GetNamespace(), inv.GetName(), err)
+	}
+	revisions := make([]Revision, 0, len(records))
+	for _, r := range records {
+		objs := make([]object.ObjMetadata, 0, len(r.Objects))
+		for _, s := range r.Objects {
+			obj, err := object.ParseObjMetadata(s)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, obj)
+		}
+		revisions = append(revisions, Revision{
+			Number:    r.Number,
+			Timestamp: r.Timestamp.Time,
+			Applier:   r.Applier,
+			Hash:      r.Hash,
+			Objects:   objs,
+		})
+	}
+	return revisions, nil
+}
+
+// FindRevision returns the revision with the passed number from the
+// revisions stored on the inventory object, or a RevisionNotFoundError
+// if it is not in the revision history.
+func FindRevision(inv *unstructured.Unstructured, number int64) (*Revision, error) {
+	revisions, err := LoadRevisions(inv)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if revisions[i].Number == number {
+			return &revisions[i], nil
+		}
+	}
+	return nil, RevisionNotFoundError{Number: number}
+}
+
+// hasLatestRevision returns true if the most recent revision stored on
+// the inventory object has the same set of objects as the passed set.
+func hasLatestRevision(inv *unstructured.Unstructured, objs []object.ObjMetadata) (bool, error) {
+	revisions, err := LoadRevisions(inv)
+	if err != nil {
+		return false, err
+	}
+	if len(revisions) == 0 {
+		return false, nil
+	}
+	hash, err := object.Hash(objs)
+	if err != nil {
+		return false, err
+	}
+	return revisions[0].Hash == hash, nil
+}
+
+// addRevision records a new revision with the passed set of objects
+// on the inventory object. At most limit revisions are kept.
+func addRevision(inv *unstructured.Unstructured, objs []object.ObjMetadata, applier string,
+	timestamp time.Time, limit int) error {
+	revisions, err := LoadRevisions(inv)
+	if err != nil {
+		return err
+	}
+	hash, err := object.Hash(objs)
+	if err != nil {
+		return err
+	}
+	var number int64 = 1
+	if len(revisions) > 0 {
+		number = revisions[0].Number + 1
+	}
+	revisions = append([]Revision{{
+		Number:    number,
+		Timestamp: timestamp,
+		Applier:   applier,
+		Hash:      hash,
+		Objects:   objs,
+	}}, revisions...)
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+
+	records := make([]revisionRecord, 0, len(revisions))
+	for _, r := range revisions {
+		objStrs := make([]string, 0, len(r.Objects))
+		for _, obj := range r.Objects {
+			objStrs = append(objStrs, obj.String())
+		}
+		sort.Strings(objStrs)
+		records = append(records, revisionRecord{
+			Number:    r.Number,
+			Timestamp: metav1.NewTime(r.Timestamp),
+			Applier:   r.Applier,
+			Hash:      r.Hash,
+			Objects:   objStrs,
+		})
+	}
+	var value []byte
+	for {
+		value, err = json.Marshal(records)
+		if err != nil {
+			return err
+		}
+		if len(value) <= maxRevisionsSize {
+			break
+		}
+		if len(records) == 1 {
+			// The objects of a large inventory may not fit even in a
+			// single revision. Keep the hash without the objects.
+			if len(records[0].Objects) == 0 {
+				break
+			}
+			records[0].Objects = nil
+			continue
+		}
+		records = records[:len(records)-1]
+	}
+
+	annotations := inv.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[RevisionsAnnotation] = string(value)
+	inv.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestAddRevision(t *testing.T) {
+	t1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	pod2 := ignoreErrInfoToObjMeta(pod2Info)
+	pod3 := ignoreErrInfoToObjMeta(pod3Info)
+
+	tests := map[string]struct {
+		objSets         [][]object.ObjMetadata
+		limit           int
+		expectedNumbers []int64
+	}{
+		"single revision": {
+			objSets:         [][]object.ObjMetadata{{pod1}},
+			limit:           DefaultRevisionHistoryLimit,
+			expectedNumbers: []int64{1},
+		},
+		"most recent revision first": {
+			objSets:         [][]object.ObjMetadata{{pod1}, {pod1, pod2}, {}},
+			limit:           DefaultRevisionHistoryLimit,
+			expectedNumbers: []int64{3, 2, 1},
+		},
+		"oldest revisions are dropped": {
+			objSets:         [][]object.ObjMetadata{{pod1}, {pod2}, {pod3}, {pod1, pod3}},
+			limit:           2,
+			expectedNumbers: []int64{4, 3},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			inv := copyInventoryInfo()
+			for i, objs := range tc.objSets {
+				ts := t1.Add(time.Duration(i) * time.Hour)
+				if err := addRevision(inv, objs, "kapply", ts, tc.limit); err != nil {
+					t.Fatalf("unexpected error received: %s", err)
+				}
+			}
+			revisions, err := LoadRevisions(inv)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if len(revisions) != len(tc.expectedNumbers) {
+				t.Fatalf("expected %d revisions, got %d", len(tc.expectedNumbers), len(revisions))
+			}
+			for i, rev := range revisions {
+				if rev.Number != tc.expectedNumbers[i] {
+					t.Errorf("expected revision %d, got %d", tc.expectedNumbers[i], rev.Number)
+				}
+				objs := tc.objSets[rev.Number-1]
+				if !object.SetEquals(objs, rev.Objects) {
+					t.Errorf("expected objects (%s), got (%s)", objs, rev.Objects)
+				}
+				hash, _ := object.Hash(objs)
+				if rev.Hash != hash {
+					t.Errorf("expected hash %s, got %s", hash, rev.Hash)
+				}
+				ts := t1.Add(time.Duration(rev.Number-1) * time.Hour)
+				if !rev.Timestamp.Equal(ts) {
+					t.Errorf("expected timestamp %s, got %s", ts, rev.Timestamp)
+				}
+				if rev.Applier != "kapply" {
+					t.Errorf("expected applier kapply, got %s", rev.Applier)
+				}
+			}
+		})
+	}
+}
+
+func TestAddRevision_SizeLimit(t *testing.T) {
+	var objs []object.ObjMetadata
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("pod-%s-%d", strings.Repeat("x", 80), i)
+		obj, err := object.CreateObjMetadata(testNamespace, name, schema.GroupKind{Kind: "Pod"})
+		if err != nil {
+			t.Fatalf("unexpected error received: %s", err)
+		}
+		objs = append(objs, obj)
+	}
+	inv := copyInventoryInfo()
+	for i := 0; i < DefaultRevisionHistoryLimit; i++ {
+		if err := addRevision(inv, objs, "kapply", time.Now(), DefaultRevisionHistoryLimit); err != nil {
+			t.Fatalf("unexpected error received: %s", err)
+		}
+	}
+	value := inv.GetAnnotations()[RevisionsAnnotation]
+	if len(value) > maxRevisionsSize {
+		t.Errorf("expected revisions annotation of at most %d bytes, got %d", maxRevisionsSize, len(value))
+	}
+	revisions, err := LoadRevisions(inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if len(revisions) == 0 || len(revisions) == DefaultRevisionHistoryLimit {
+		t.Errorf("expected the oldest revisions to be dropped, got %d revisions", len(revisions))
+	}
+	if revisions[0].Number != DefaultRevisionHistoryLimit {
+		t.Errorf("expected the most recent revision to be kept, got %d", revisions[0].Number)
+	}
+}
+
+func TestFindRevision(t *testing.T) {
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	pod2 := ignoreErrInfoToObjMeta(pod2Info)
+	inv := copyInventoryInfo()
+	for _, objs := range [][]object.ObjMetadata{{pod1}, {pod2}} {
+		if err := addRevision(inv, objs, "kapply", time.Now(), DefaultRevisionHistoryLimit); err != nil {
+			t.Fatalf("unexpected error received: %s", err)
+		}
+	}
+
+	rev, err := FindRevision(inv, 1)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if !object.SetEquals([]object.ObjMetadata{pod1}, rev.Objects) {
+		t.Errorf("expected objects (%s), got (%s)", pod1, rev.Objects)
+	}
+
+	_, err = FindRevision(inv, 3)
+	if _, ok := err.(RevisionNotFoundError); !ok {
+		t.Errorf("expected RevisionNotFoundError, got %v", err)
+	}
+}
+
+func TestLoadRevisions_Invalid(t *testing.T) {
+	inv := copyInventoryInfo()
+	inv.SetAnnotations(map[string]string{RevisionsAnnotation: "{"})
+	if _, err := LoadRevisions(inv); err == nil {
+		t.Errorf("expected error but received none")
+	}
+}
+
+func TestHasLatestRevision(t *testing.T) {
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	pod2 := ignoreErrInfoToObjMeta(pod2Info)
+	inv := copyInventoryInfo()
+
+	found, err := hasLatestRevision(inv, []object.ObjMetadata{pod1})
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if found {
+		t.Errorf("expected no latest revision without revisions")
+	}
+	if err := addRevision(inv, []object.ObjMetadata{pod1, pod2}, "kapply", time.Now(), DefaultRevisionHistoryLimit); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	found, err = hasLatestRevision(inv, []object.ObjMetadata{pod2, pod1})
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if !found {
+		t.Errorf("expected latest revision to have the same objects")
+	}
+	found, err = hasLatestRevision(inv, []object.ObjMetadata{pod1})
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if found {
+		t.Errorf("expected latest revision to have different objects")
+	}
+}
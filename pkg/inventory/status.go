@@ -0,0 +1,205 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the status of the objects stored in an inventory.
+// Along with the identity of each object, an inventory can store the
+// result of the last apply of the object, the generation and UID it
+// had after it was applied, and its last observed status. This makes
+// it possible to tell what the last apply did without polling the
+// cluster again.
+
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// ApplyResult is the result of the last apply of an object stored in
+// the inventory.
+type ApplyResult string
+
+const (
+	// ApplySucceeded means the object was applied.
+	ApplySucceeded ApplyResult = "Applied"
+	// ApplyFailed means the object failed to apply, and it is kept in
+	// the inventory since it was applied before.
+	ApplyFailed ApplyResult = "ApplyFailed"
+	// ApplyRolledBack means the object was applied, and then rolled
+	// back to the state it had before the apply.
+	ApplyRolledBack ApplyResult = "RolledBack"
+	// PruneFailed means the object failed to be pruned, and it is kept
+	// in the inventory so pruning is attempted again.
+	PruneFailed ApplyResult = "PruneFailed"
+)
+
+// ObjectStatus is the status of an object stored in an inventory.
+type ObjectStatus struct {
+	// Identifier identifies the object.
+	Identifier object.ObjMetadata
+	// ApplyResult is the result of the last apply of the object.
+	ApplyResult ApplyResult
+	// Generation is the generation of the object after it was applied.
+	Generation int64
+	// UID is the UID of the object after it was applied. It tells the
+	// applied object apart from an object with the same name that was
+	// deleted and recreated by someone else.
+	UID types.UID
+	// Status is the last observed status of the object.
+	Status status.Status
+	// LastApplied is the time the object was last applied.
+	LastApplied time.Time
+	// LastUpdated is the time the status was last updated.
+	LastUpdated time.Time
+}
+
+// objectStatusRecord is the serialized form of an ObjectStatus.
+type objectStatusRecord struct {
+	ApplyResult ApplyResult   `json:"applyResult,omitempty"`
+	Generation  int64         `json:"generation,omitempty"`
+	UID         types.UID     `json:"uid,omitempty"`
+	Status      status.Status `json:"status,omitempty"`
+	LastApplied *metav1.Time  `json:"lastApplied,omitempty"`
+	LastUpdated *metav1.Time  `json:"lastUpdated,omitempty"`
+}
+
+// StatusInventory is implemented by Inventory implementations that store
+// the status of each object along with its identity. The status is kept
+// for the objects that remain in the inventory when it is stored again.
+type StatusInventory interface {
+	Inventory
+	// LoadStatus returns the status of the stored objects. Objects
+	// without a stored status are not included.
+	LoadStatus() ([]ObjectStatus, error)
+	// StoreStatus sets the status of the objects to store. The status
+	// is only stored for the objects passed to Store. Actual storing
+	// happens in GetObject.
+	StoreStatus(statuses []ObjectStatus) error
+}
+
+// encodeObjectStatus returns the serialized form of the passed status.
+func encodeObjectStatus(s ObjectStatus) (string, error) {
+	record := objectStatusRecord{
+		ApplyResult: s.ApplyResult,
+		Generation:  s.Generation,
+		UID:         s.UID,
+		Status:      s.Status,
+	}
+	if !s.LastApplied.IsZero() {
+		t := metav1.NewTime(s.LastApplied)
+		record.LastApplied = &t
+	}
+	if !s.LastUpdated.IsZero() {
+		t := metav1.NewTime(s.LastUpdated)
+		record.LastUpdated = &t
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// decodeObjectStatus parses the serialized status of the object
+// identified by the passed id.
+func decodeObjectStatus(id object.ObjMetadata, value string) (ObjectStatus, error) {
+	var record objectStatusRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return ObjectStatus{}, fmt.Errorf("unable to parse inventory status of %s: %w", id, err)
+	}
+	s := ObjectStatus{
+		Identifier:  id,
+		ApplyResult: record.ApplyResult,
+		Generation:  record.Generation,
+		UID:         record.UID,
+		Status:      record.Status,
+	}
+	if record.LastApplied != nil {
+		s.LastApplied = record.LastApplied.Time
+	}
+	if record.LastUpdated != nil {
+		s.LastUpdated = record.LastUpdated.Time
+	}
+	return s, nil
+}
+
+// loadObjectStatuses returns the status stored in the values of the
+// passed inventory data. Keys with an empty value have no status.
+func loadObjectStatuses(objMap map[string]string) ([]ObjectStatus, error) {
+	statuses := []ObjectStatus{}
+	for objStr, value := range objMap {
+		if value == "" {
+			continue
+		}
+		id, err := object.ParseObjMetadata(objStr)
+		if err != nil {
+			return nil, err
+		}
+		s, err := decodeObjectStatus(id, value)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// buildObjMapWithStatus returns the inventory data for the passed
+// objects. The value of each object is its serialized status from the
+// passed statuses, or else the value in the passed previous data.
+func buildObjMapWithStatus(objMetas []object.ObjMetadata, statuses map[object.ObjMetadata]ObjectStatus,
+	prevObjMap map[string]string) (map[string]string, error) {
+	objMap := buildObjMap(objMetas)
+	for _, id := range objMetas {
+		key := id.String()
+		if s, found := statuses[id]; found {
+			value, err := encodeObjectStatus(s)
+			if err != nil {
+				return nil, err
+			}
+			objMap[key] = value
+		} else if value, found := prevObjMap[key]; found {
+			objMap[key] = value
+		}
+	}
+	return objMap, nil
+}
+
+// statusMap indexes the passed statuses by object.
+func statusMap(statuses []ObjectStatus) map[object.ObjMetadata]ObjectStatus {
+	m := make(map[object.ObjMetadata]ObjectStatus, len(statuses))
+	for _, s := range statuses {
+		m[s.Identifier] = s
+	}
+	return m
+}
+
+// mergeObjectStatus returns the passed status, with the fields that are
+// not set taken from the passed previous status of the same object.
+func mergeObjectStatus(prev, s ObjectStatus) ObjectStatus {
+	if s.ApplyResult == "" {
+		s.ApplyResult = prev.ApplyResult
+	}
+	if s.Generation == 0 {
+		s.Generation = prev.Generation
+	}
+	if s.UID == "" {
+		s.UID = prev.UID
+	}
+	if s.Status == "" {
+		s.Status = prev.Status
+	}
+	if s.LastApplied.IsZero() {
+		s.LastApplied = prev.LastApplied
+	}
+	if s.LastUpdated.IsZero() {
+		s.LastUpdated = prev.LastUpdated
+	}
+	return s
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestEncodeDecodeObjectStatus(t *testing.T) {
+	id := ignoreErrInfoToObjMeta(pod1Info)
+	applied := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]ObjectStatus{
+		"Empty status": {
+			Identifier: id,
+		},
+		"Full status": {
+			Identifier:  id,
+			ApplyResult: ApplySucceeded,
+			Generation:  3,
+			UID:         "uid-1",
+			Status:      status.CurrentStatus,
+			LastApplied: applied,
+			LastUpdated: applied.Add(time.Minute),
+		},
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := encodeObjectStatus(expected)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			actual, err := decodeObjectStatus(id, value)
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if !actual.LastApplied.Equal(expected.LastApplied) || !actual.LastUpdated.Equal(expected.LastUpdated) {
+				t.Errorf("expected times (%s, %s), got (%s, %s)", expected.LastApplied, expected.LastUpdated,
+					actual.LastApplied, actual.LastUpdated)
+			}
+			actual.LastApplied, actual.LastUpdated = expected.LastApplied, expected.LastUpdated
+			if actual != expected {
+				t.Errorf("expected status %+v, got %+v", expected, actual)
+			}
+		})
+	}
+
+	if _, err := decodeObjectStatus(id, "not json"); err == nil {
+		t.Errorf("expected error decoding invalid status")
+	}
+}
+
+func TestInventoryConfigMap_Status(t *testing.T) {
+	id1 := ignoreErrInfoToObjMeta(pod1Info)
+	id2 := ignoreErrInfoToObjMeta(pod2Info)
+	id3 := ignoreErrInfoToObjMeta(pod3Info)
+	for name, wrap := range map[string]InventoryFactoryFunc{
+		"ConfigMap":         WrapInventoryObj,
+		"Sharded ConfigMap": wrapTestShardedInventoryObj,
+	} {
+		t.Run(name, func(t *testing.T) {
+			wrapped := wrap(copyInventoryInfo())
+			if err := wrapped.Store([]object.ObjMetadata{id1, id2}); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			err := wrapped.(StatusInventory).StoreStatus([]ObjectStatus{
+				{Identifier: id1, ApplyResult: ApplySucceeded, UID: "uid-1"},
+				{Identifier: id2, ApplyResult: ApplyFailed},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			inv, err := wrapped.GetObject()
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+
+			// Storing the objects again keeps the status of the objects
+			// that remain in the inventory.
+			wrapped = wrap(inv)
+			if err := wrapped.Store([]object.ObjMetadata{id1, id3}); err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			inv, err = wrapped.GetObject()
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			statuses, err := wrap(inv).(StatusInventory).LoadStatus()
+			if err != nil {
+				t.Fatalf("unexpected error received: %s", err)
+			}
+			if len(statuses) != 1 {
+				t.Fatalf("expected 1 status, got %d", len(statuses))
+			}
+			if statuses[0].Identifier != id1 || statuses[0].UID != "uid-1" {
+				t.Errorf("expected status of %s with uid-1, got %+v", id1, statuses[0])
+			}
+		})
+	}
+}
+
+func TestMergeObjectStatus(t *testing.T) {
+	id := ignoreErrInfoToObjMeta(pod1Info)
+	applied := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := ObjectStatus{
+		Identifier:  id,
+		ApplyResult: ApplySucceeded,
+		Generation:  1,
+		UID:         "uid-1",
+		Status:      status.CurrentStatus,
+		LastApplied: applied,
+	}
+	merged := mergeObjectStatus(prev, ObjectStatus{
+		Identifier:  id,
+		ApplyResult: ApplyFailed,
+		LastUpdated: applied.Add(time.Minute),
+	})
+	expected := prev
+	expected.ApplyResult = ApplyFailed
+	expected.LastUpdated = applied.Add(time.Minute)
+	if merged != expected {
+		t.Errorf("expected status %+v, got %+v", expected, merged)
+	}
+}
+
+func TestReplaceWithStatus(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+	server := newFakeConfigMapServer()
+	tf.UnstructuredClient = server.client()
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	invClient, err := NewInventoryClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	invClient.builderFunc = server.builder
+	inv := copyInventory()
+	id1 := ignoreErrInfoToObjMeta(pod1Info)
+	id2 := ignoreErrInfoToObjMeta(pod2Info)
+	objs := []object.ObjMetadata{id1, id2}
+
+	statuses, err := invClient.GetClusterObjStatuses(inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	if len(statuses) != 0 {
+		t.Errorf("expected no statuses without an inventory object, got %d", len(statuses))
+	}
+
+	if _, err := invClient.Merge(inv, objs, common.DryRunNone); err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	err = invClient.ReplaceWithStatus(inv, objs, []ObjectStatus{
+		{Identifier: id1, ApplyResult: ApplySucceeded, Generation: 1, UID: "uid-1"},
+		{Identifier: id2, ApplyResult: ApplySucceeded, Generation: 1, UID: "uid-2"},
+	}, common.DryRunNone)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	// Fields not set in a later status keep their stored value.
+	err = invClient.ReplaceWithStatus(inv, objs, []ObjectStatus{
+		{Identifier: id2, ApplyResult: ApplyFailed},
+	}, common.DryRunNone)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+
+	statuses, err = invClient.GetClusterObjStatuses(inv)
+	if err != nil {
+		t.Fatalf("unexpected error received: %s", err)
+	}
+	expected := map[object.ObjMetadata]ObjectStatus{
+		id1: {Identifier: id1, ApplyResult: ApplySucceeded, Generation: 1, UID: "uid-1"},
+		id2: {Identifier: id2, ApplyResult: ApplyFailed, Generation: 1, UID: "uid-2"},
+	}
+	actual := statusMap(statuses)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d statuses, got %d", len(expected), len(actual))
+	}
+	for id, s := range expected {
+		if actual[id] != s {
+			t.Errorf("expected status %+v, got %+v", s, actual[id])
+		}
+	}
+	assertClusterObjs(t, invClient, inv, objs)
+}
@@ -41,6 +41,24 @@ var genGroupKinds = map[schema.GroupKind][]schema.GroupKind{
 			Kind:  "Pod",
 		},
 	},
+	schema.GroupKind{Group: "apps", Kind: "DaemonSet"}: { //nolint:gofmt
+		{
+			Group: "",
+			Kind:  "Pod",
+		},
+	},
+	schema.GroupKind{Group: "batch", Kind: "CronJob"}: { //nolint:gofmt
+		{
+			Group: "batch",
+			Kind:  "Job",
+		},
+	},
+	schema.GroupKind{Group: "batch", Kind: "Job"}: { //nolint:gofmt
+		{
+			Group: "",
+			Kind:  "Pod",
+		},
+	},
 }
 
 // NewCachingClusterReader returns a new instance of the ClusterReader. The
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -26,6 +27,8 @@ var (
 	deploymentGVK = appsv1.SchemeGroupVersion.WithKind("Deployment")
 	rsGVK         = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
 	podGVK        = v1.SchemeGroupVersion.WithKind("Pod")
+	cronJobGVK    = batchv1.SchemeGroupVersion.WithKind("CronJob")
+	jobGVK        = batchv1.SchemeGroupVersion.WithKind("Job")
 )
 
 func TestSync(t *testing.T) {
@@ -76,12 +79,37 @@ func TestSync(t *testing.T) {
 				},
 			},
 		},
+		"cronjob with jobs and pods": {
+			identifiers: []object.ObjMetadata{
+				{
+					GroupKind: cronJobGVK.GroupKind(),
+					Name:      "cronjob",
+					Namespace: "Foo",
+				},
+			},
+			expectedSynced: []gkNamespace{
+				{
+					GroupKind: cronJobGVK.GroupKind(),
+					Namespace: "Foo",
+				},
+				{
+					GroupKind: jobGVK.GroupKind(),
+					Namespace: "Foo",
+				},
+				{
+					GroupKind: podGVK.GroupKind(),
+					Namespace: "Foo",
+				},
+			},
+		},
 	}
 
 	fakeMapper := testutil.NewFakeRESTMapper(
 		appsv1.SchemeGroupVersion.WithKind("Deployment"),
 		appsv1.SchemeGroupVersion.WithKind("ReplicaSet"),
 		v1.SchemeGroupVersion.WithKind("Pod"),
+		cronJobGVK,
+		jobGVK,
 	)
 
 	for tn, tc := range testCases {
@@ -0,0 +1,290 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clusterreader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewWatchingClusterReader returns a new instance of the WatchingClusterReader.
+// Like the CachingClusterReader, it uses the identifiers to figure out which
+// GroupKind and namespace combinations it needs to watch, including the
+// generated resources needed to compute status.
+func NewWatchingClusterReader(client dynamic.Interface, mapper meta.RESTMapper, identifiers []object.ObjMetadata) (*WatchingClusterReader, error) {
+	gvkNamespaceSet := newGnSet()
+	for _, id := range identifiers {
+		err := buildGvkNamespaceSet([]schema.GroupKind{id.GroupKind}, id.Namespace, gvkNamespaceSet)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &WatchingClusterReader{
+		client:    client,
+		mapper:    mapper,
+		gns:       gvkNamespaceSet.gvkNamespaces,
+		informers: make(map[gkNamespace]cache.SharedIndexInformer),
+		errs:      make(map[gkNamespace]error),
+		updates:   make(chan struct{}, 1),
+		backoff: wait.Backoff{
+			Duration: 500 * time.Millisecond,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    math.MaxInt32,
+			Cap:      30 * time.Second,
+		},
+	}, nil
+}
+
+// WatchingClusterReader is an implementation of the ClusterReader interface that
+// keeps the resources needed to compute status in informer caches, rather than
+// listing them from the cluster before every polling cycle. The informers
+// watch the cluster for changes and re-establish their watches with backoff
+// if they are closed or fail. Every change is signaled on the channel
+// returned by Updates, so the PollerEngine can compute status as soon as
+// a change is observed.
+type WatchingClusterReader struct {
+	sync.RWMutex
+
+	// client is used by the informers to list and watch resources.
+	client dynamic.Interface
+
+	// mapper is the client-side representation of the server-side scheme. It is used
+	// to resolve GroupVersionResource from GroupKind.
+	mapper meta.RESTMapper
+
+	// gns contains the slice of all the GVK and namespace combinations that
+	// should be watched. See CachingClusterReader.
+	gns []gkNamespace
+
+	// informers contains the running informer for each of the gns.
+	informers map[gkNamespace]cache.SharedIndexInformer
+
+	// errs contains the error for each of the gns that an informer could
+	// not be started for. This happens if the type doesn't exist yet,
+	// for example if a CRD and its custom resources are applied together.
+	errs map[gkNamespace]error
+
+	// started is set when the informers have been started by the first
+	// call to Sync.
+	started bool
+
+	// updates receives a value whenever a watched resource has changed.
+	// It is buffered, so changes that arrive before the PollerEngine has
+	// computed status are coalesced into a single update.
+	updates chan struct{}
+
+	// backoff controls how often the informers that could not be started
+	// are retried.
+	backoff wait.Backoff
+}
+
+// Get looks up the resource identified by the key and the object GVK in the informer cache.
+// If the needed combination of GVK and namespace is not watched, that is considered an error.
+func (w *WatchingClusterReader) Get(_ context.Context, key client.ObjectKey, obj *unstructured.Unstructured) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	mapping, err := w.mapper.RESTMapping(gvk.GroupKind())
+	if err != nil {
+		return err
+	}
+	store, err := w.store(gkNamespace{
+		GroupKind: gvk.GroupKind(),
+		Namespace: key.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	storeKey := key.Name
+	if key.Namespace != "" {
+		storeKey = key.Namespace + "/" + key.Name
+	}
+	item, found, err := store.GetByKey(storeKey)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.NewNotFound(mapping.Resource.GroupResource(), key.Name)
+	}
+	obj.Object = item.(*unstructured.Unstructured).DeepCopy().Object
+	return nil
+}
+
+// ListNamespaceScoped lists all resource identifier by the GVK of the list, the namespace and the selector
+// from the informer cache. If the needed combination of GVK and namespace is not watched, that is
+// considered an error.
+func (w *WatchingClusterReader) ListNamespaceScoped(_ context.Context, list *unstructured.UnstructuredList, namespace string, selector labels.Selector) error {
+	store, err := w.store(gkNamespace{
+		GroupKind: list.GroupVersionKind().GroupKind(),
+		Namespace: namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	var items []unstructured.Unstructured
+	for _, item := range store.List() {
+		u := item.(*unstructured.Unstructured)
+		if selector.Matches(labels.Set(u.GetLabels())) {
+			items = append(items, *u.DeepCopy())
+		}
+	}
+	list.Items = items
+	return nil
+}
+
+// ListClusterScoped lists all resource identifier by the GVK of the list and selector
+// from the informer cache. If the needed combination of GVK and namespace (which for
+// clusterscoped resources will always be the empty string) is not watched, that is
+// considered an error.
+func (w *WatchingClusterReader) ListClusterScoped(ctx context.Context, list *unstructured.UnstructuredList, selector labels.Selector) error {
+	return w.ListNamespaceScoped(ctx, list, "", selector)
+}
+
+// store returns the informer store for the given gkNamespace, or the error
+// that prevented the informer from being started.
+func (w *WatchingClusterReader) store(gn gkNamespace) (cache.Store, error) {
+	w.RLock()
+	defer w.RUnlock()
+	if informer, found := w.informers[gn]; found {
+		return informer.GetStore(), nil
+	}
+	if err, found := w.errs[gn]; found {
+		return nil, err
+	}
+	return nil, fmt.Errorf("GroupKind %s and Namespace %s not watched", gn.GroupKind.String(), gn.Namespace)
+}
+
+// Updates returns the channel that receives a value whenever a watched
+// resource has changed.
+func (w *WatchingClusterReader) Updates() <-chan struct{} {
+	return w.updates
+}
+
+// Sync starts the informers for all the gkNamespace we know of the first time
+// it is called, and waits for their caches to be populated. The informers
+// keep running until the context is cancelled, so later calls don't need to
+// do anything.
+func (w *WatchingClusterReader) Sync(ctx context.Context) error {
+	w.Lock()
+	if w.started {
+		w.Unlock()
+		return nil
+	}
+	w.started = true
+
+	var synced []cache.InformerSynced
+	var pending []gkNamespace
+	for _, gn := range w.gns {
+		informer, err := w.newInformer(gn)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				// The type doesn't exist yet. Presumably the CRD is being
+				// applied, so keep retrying in the background.
+				w.errs[gn] = err
+				pending = append(pending, gn)
+				continue
+			}
+			w.Unlock()
+			return err
+		}
+		w.informers[gn] = informer
+		go informer.Run(ctx.Done())
+		synced = append(synced, informer.HasSynced)
+	}
+	w.Unlock()
+
+	if len(pending) > 0 {
+		go w.startPending(ctx, pending)
+	}
+
+	// The informers only stop waiting for the caches to sync if the
+	// context is cancelled, in which case the PollerEngine stops anyway.
+	cache.WaitForCacheSync(ctx.Done(), synced...)
+	return nil
+}
+
+// newInformer creates the informer that watches the resources for the
+// given gkNamespace.
+func (w *WatchingClusterReader) newInformer(gn gkNamespace) (cache.SharedIndexInformer, error) {
+	mapping, err := w.mapper.RESTMapping(gn.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	namespace := ""
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace = gn.Namespace
+	}
+	informer := dynamicinformer.NewFilteredDynamicInformer(w.client, mapping.Resource, namespace, 0,
+		cache.Indexers{}, nil).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { w.notify() },
+		UpdateFunc: func(interface{}, interface{}) { w.notify() },
+		DeleteFunc: func(interface{}) { w.notify() },
+	})
+	err = informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		klog.V(4).Infof("watch of %s in namespace %q failed, retrying: %v", gn.GroupKind, namespace, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return informer, nil
+}
+
+// startPending retries starting the informers for the given gkNamespaces
+// with backoff, until all of them are running or the context is cancelled.
+func (w *WatchingClusterReader) startPending(ctx context.Context, pending []gkNamespace) {
+	backoff := w.backoff
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff.Step()):
+		}
+		var remaining []gkNamespace
+		for _, gn := range pending {
+			informer, err := w.newInformer(gn)
+			if err != nil {
+				klog.V(4).Infof("unable to watch %s in namespace %q, retrying: %v", gn.GroupKind, gn.Namespace, err)
+				remaining = append(remaining, gn)
+				continue
+			}
+			go informer.Run(ctx.Done())
+			if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+				return
+			}
+			w.Lock()
+			w.informers[gn] = informer
+			delete(w.errs, gn)
+			w.Unlock()
+			w.notify()
+		}
+		pending = remaining
+	}
+}
+
+// notify signals an update without blocking. If an update is already
+// pending, there is no need to add another one.
+func (w *WatchingClusterReader) notify() {
+	select {
+	case w.updates <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clusterreader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestWatchingClusterReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mapper := testutil.NewFakeRESTMapper(deploymentGVK, rsGVK, podGVK)
+	fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			deploymentGVK.GroupVersion().WithResource("deployments"): "DeploymentList",
+			rsGVK.GroupVersion().WithResource("replicasets"):         "ReplicaSetList",
+			podGVK.GroupVersion().WithResource("pods"):               "PodList",
+		},
+		newUnstructured(deploymentGVK, "default", "foo", nil),
+		newUnstructured(rsGVK, "default", "foo-1", map[string]string{"app": "foo"}),
+		newUnstructured(rsGVK, "default", "bar-1", map[string]string{"app": "bar"}),
+	)
+
+	// Pods are watched with a FakeWatcher, so changes can be sent once the
+	// informer is watching.
+	podWatcher := watch.NewFake()
+	fakeClient.PrependWatchReactor("pods", clienttesting.DefaultWatchReactor(podWatcher, nil))
+
+	crdGK := schema.GroupKind{Group: "custom.io", Kind: "Custom"}
+	reader, err := NewWatchingClusterReader(fakeClient, mapper, []object.ObjMetadata{
+		{
+			GroupKind: deploymentGVK.GroupKind(),
+			Name:      "foo",
+			Namespace: "default",
+		},
+		{
+			GroupKind: crdGK,
+			Name:      "foo",
+			Namespace: "default",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, reader.Sync(ctx))
+
+	var deployment unstructured.Unstructured
+	deployment.SetGroupVersionKind(deploymentGVK)
+	err = reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "foo"}, &deployment)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", deployment.GetName())
+
+	err = reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "bar"}, &deployment)
+	assert.True(t, errors.IsNotFound(err))
+
+	var rsList unstructured.UnstructuredList
+	rsList.SetGroupVersionKind(rsGVK)
+	err = reader.ListNamespaceScoped(ctx, &rsList, "default", labels.SelectorFromSet(map[string]string{"app": "foo"}))
+	require.NoError(t, err)
+	require.Len(t, rsList.Items, 1)
+	assert.Equal(t, "foo-1", rsList.Items[0].GetName())
+
+	// The type of the custom resource doesn't exist, so it can't be watched.
+	var custom unstructured.Unstructured
+	custom.SetGroupVersionKind(crdGK.WithVersion("v1"))
+	err = reader.ListNamespaceScoped(ctx, &unstructured.UnstructuredList{Object: custom.Object}, "default", labels.Everything())
+	assert.True(t, meta.IsNoMatchError(err))
+
+	pod := newUnstructured(podGVK, "default", "foo-1-abc", map[string]string{"app": "foo"})
+	podWatcher.Add(pod)
+
+	// The reader notifies about the resources found when the informers
+	// started as well, so wait for the update that includes the pod.
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case <-reader.Updates():
+		case <-timeout:
+			t.Fatalf("expected an update after the pod was added")
+		}
+		var podList unstructured.UnstructuredList
+		podList.SetGroupVersionKind(podGVK)
+		err = reader.ListNamespaceScoped(ctx, &podList, "default", labels.Everything())
+		require.NoError(t, err)
+		if len(podList.Items) == 1 {
+			assert.Equal(t, "foo-1-abc", podList.Items[0].GetName())
+			return
+		}
+	}
+}
+
+func newUnstructured(gvk schema.GroupVersionKind, namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(labels)
+	return u
+}
@@ -59,6 +59,12 @@ func (s *PollerEngine) Poll(ctx context.Context, identifiers []object.ObjMetadat
 			handleError(eventChannel, fmt.Errorf("error creating new ClusterReader: %w", err))
 			return
 		}
+		// Without a ClusterReader that notifies about changes, the
+		// status is only computed at every polling interval.
+		if _, ok := clusterReader.(UpdateNotifier); !ok && options.PollInterval <= 0 {
+			handleError(eventChannel, fmt.Errorf("pollInterval must be positive"))
+			return
+		}
 		statusReaders, defaultStatusReader := options.StatusReadersFactoryFunc(clusterReader, s.Mapper)
 
 		runner := &statusPollerRunner{
@@ -123,7 +129,9 @@ func (s *PollerEngine) validateIdentifiers(identifiers []object.ObjMetadata) err
 type Options struct {
 
 	// PollInterval defines how often the PollerEngine should poll the cluster for the latest
-	// state of the resources.
+	// state of the resources. If the ClusterReader is an UpdateNotifier, the status is also
+	// computed whenever the ClusterReader notifies about a change, and the PollInterval can
+	// be zero to only compute status on changes.
 	PollInterval time.Duration
 
 	// ClusterReaderFactoryFunc provides the PollerEngine with a factory function for creating new
@@ -183,10 +191,22 @@ type statusPollerRunner struct {
 // Run starts the polling loop of the statusReaders.
 func (r *statusPollerRunner) Run() {
 	// Sets up ticker that will trigger the regular polling loop at a regular interval.
-	ticker := time.NewTicker(r.pollingInterval)
-	defer func() {
-		ticker.Stop()
-	}()
+	var tick <-chan time.Time
+	if r.pollingInterval > 0 {
+		ticker := time.NewTicker(r.pollingInterval)
+		defer func() {
+			ticker.Stop()
+		}()
+		tick = ticker.C
+	}
+
+	// If the ClusterReader watches the cluster, status is also computed
+	// whenever it has an update. Receiving from the nil channel blocks
+	// forever otherwise.
+	var updates <-chan struct{}
+	if notifier, ok := r.clusterReader.(UpdateNotifier); ok {
+		updates = notifier.Updates()
+	}
 
 	err := r.syncAndPoll()
 	if err != nil {
@@ -201,16 +221,17 @@ func (r *statusPollerRunner) Run() {
 		select {
 		case <-r.ctx.Done():
 			return
-		case <-ticker.C:
-			// First sync and then compute status for all resources.
-			err := r.syncAndPoll()
-			if err != nil {
-				r.eventChannel <- event.Event{
-					EventType: event.ErrorEvent,
-					Error:     err,
-				}
-				return
+		case <-tick:
+		case <-updates:
+		}
+		// First sync and then compute status for all resources.
+		err := r.syncAndPoll()
+		if err != nil {
+			r.eventChannel <- event.Event{
+				EventType: event.ErrorEvent,
+				Error:     err,
 			}
+			return
 		}
 	}
 }
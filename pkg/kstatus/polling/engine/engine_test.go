@@ -221,6 +221,93 @@ func TestNewStatusPollerRunnerIdentifierValidation(t *testing.T) {
 	}
 }
 
+func TestStatusPollerRunnerUpdateNotifier(t *testing.T) {
+	identifiers := []object.ObjMetadata{
+		{
+			GroupKind: schema.GroupKind{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine := PollerEngine{
+		Mapper: fakemapper.NewFakeRESTMapper(
+			appsv1.SchemeGroupVersion.WithKind("Deployment"),
+		),
+	}
+
+	clusterReader := &fakeNotifyingClusterReader{
+		NoopClusterReader: testutil.NewNoopClusterReader(),
+		updates:           make(chan struct{}),
+	}
+
+	// Without a PollInterval, status is only computed again when the
+	// ClusterReader notifies about a change.
+	eventChannel := engine.Poll(ctx, identifiers, Options{
+		ClusterReaderFactoryFunc: func(_ client.Reader, _ meta.RESTMapper, _ []object.ObjMetadata) (
+			ClusterReader, error) {
+			return clusterReader, nil
+		},
+		StatusReadersFactoryFunc: func(_ ClusterReader, _ meta.RESTMapper) (
+			statusReaders map[schema.GroupKind]StatusReader, defaultStatusReader StatusReader) {
+			return make(map[schema.GroupKind]StatusReader), &fakeStatusReader{
+				resourceStatuses: map[schema.GroupKind][]status.Status{
+					schema.GroupKind{Group: "apps", Kind: "Deployment"}: { //nolint:gofmt
+						status.InProgressStatus,
+						status.CurrentStatus,
+					},
+				},
+				resourceStatusCount: make(map[schema.GroupKind]int),
+			}
+		},
+	})
+
+	e := <-eventChannel
+	assert.Equal(t, event.ResourceUpdateEvent, e.EventType)
+	assert.Equal(t, status.InProgressStatus, e.Resource.Status)
+
+	clusterReader.updates <- struct{}{}
+
+	e = <-eventChannel
+	assert.Equal(t, event.ResourceUpdateEvent, e.EventType)
+	assert.Equal(t, status.CurrentStatus, e.Resource.Status)
+}
+
+func TestNewStatusPollerRunnerPollIntervalValidation(t *testing.T) {
+	engine := PollerEngine{}
+
+	eventChannel := engine.Poll(context.Background(), []object.ObjMetadata{}, Options{
+		ClusterReaderFactoryFunc: func(_ client.Reader, _ meta.RESTMapper, _ []object.ObjMetadata) (
+			ClusterReader, error) {
+			return testutil.NewNoopClusterReader(), nil
+		},
+		StatusReadersFactoryFunc: func(_ ClusterReader, _ meta.RESTMapper) (
+			statusReaders map[schema.GroupKind]StatusReader, defaultStatusReader StatusReader) {
+			return make(map[schema.GroupKind]StatusReader), nil
+		},
+	})
+
+	e := <-eventChannel
+	if assert.Equal(t, event.ErrorEvent, e.EventType) {
+		assert.Contains(t, e.Error.Error(), "pollInterval must be positive")
+	}
+}
+
+type fakeNotifyingClusterReader struct {
+	*testutil.NoopClusterReader
+	updates chan struct{}
+}
+
+func (f *fakeNotifyingClusterReader) Updates() <-chan struct{} {
+	return f.updates
+}
+
 type fakeStatusReader struct {
 	resourceStatuses    map[schema.GroupKind][]status.Status
 	resourceStatusCount map[schema.GroupKind]int
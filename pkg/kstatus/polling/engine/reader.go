@@ -30,3 +30,13 @@ type ClusterReader interface {
 	// to sync caches.
 	Sync(ctx context.Context) error
 }
+
+// UpdateNotifier is implemented by ClusterReaders that keep their state up
+// to date by watching the cluster. The engine computes the status of the
+// resources whenever the ClusterReader notifies about a change, instead of
+// waiting for the next polling interval.
+type UpdateNotifier interface {
+	// Updates returns a channel that receives a value when any of the
+	// resources known to the ClusterReader has changed.
+	Updates() <-chan struct{}
+}
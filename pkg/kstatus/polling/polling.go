@@ -8,8 +8,10 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/clusterreader"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
@@ -39,28 +41,68 @@ type StatusPoller struct {
 // back on the event channel returned. The statusPollerRunner can be cancelled at any time by cancelling the
 // context passed in.
 func (s *StatusPoller) Poll(ctx context.Context, identifiers []object.ObjMetadata, options Options) <-chan event.Event {
-	statusReaderFactory := createStatusReaders
-	if options.CustomStatusReadersFactoryFunc != nil {
-		statusReaderFactory = func(reader engine.ClusterReader, mapper meta.RESTMapper) (map[schema.GroupKind]engine.StatusReader, engine.StatusReader) {
-			readers, defaultReader := createStatusReaders(reader, mapper)
-			for gk, r := range options.CustomStatusReadersFactoryFunc(reader, mapper) {
-				readers[gk] = r
-			}
-			return readers, defaultReader
-		}
-	}
 	return s.engine.Poll(ctx, identifiers, engine.Options{
 		PollInterval:             options.PollInterval,
 		ClusterReaderFactoryFunc: clusterReaderFactoryFunc(options.UseCache),
-		StatusReadersFactoryFunc: statusReaderFactory,
+		StatusReadersFactoryFunc: statusReadersFactoryFunc(options),
 	})
 }
 
+// NewStatusWatcher creates a new StatusWatcher using the given client and mapper. The StatusWatcher
+// will use the client to watch the resources in the cluster.
+func NewStatusWatcher(client dynamic.Interface, mapper meta.RESTMapper) *StatusWatcher {
+	return &StatusWatcher{
+		client: client,
+		engine: &engine.PollerEngine{
+			Mapper: mapper,
+		},
+	}
+}
+
+// StatusWatcher provides functionality for watching a cluster for status for a set of resources.
+// Unlike the StatusPoller, which lists the resources from the cluster at every polling interval,
+// the StatusWatcher keeps the resources up to date with watches and computes status as soon as
+// a change is observed.
+type StatusWatcher struct {
+	client dynamic.Interface
+	engine *engine.PollerEngine
+}
+
+// Poll will create a new statusPollerRunner that will watch all the resources provided and report their
+// status back on the event channel returned. The statusPollerRunner can be cancelled at any time by
+// cancelling the context passed in. The PollInterval of the options is optional, and sets how often the
+// status is computed even if no change has been observed. The UseCache option is ignored.
+func (s *StatusWatcher) Poll(ctx context.Context, identifiers []object.ObjMetadata, options Options) <-chan event.Event {
+	return s.engine.Poll(ctx, identifiers, engine.Options{
+		PollInterval: options.PollInterval,
+		ClusterReaderFactoryFunc: func(_ client.Reader, mapper meta.RESTMapper, identifiers []object.ObjMetadata) (engine.ClusterReader, error) {
+			return clusterreader.NewWatchingClusterReader(s.client, mapper, identifiers)
+		},
+		StatusReadersFactoryFunc: statusReadersFactoryFunc(options),
+	})
+}
+
+// statusReadersFactoryFunc returns a factory function for creating the statusreaders, including
+// any custom statusreaders from the options.
+func statusReadersFactoryFunc(options Options) engine.StatusReadersFactoryFunc {
+	if options.CustomStatusReadersFactoryFunc == nil {
+		return createStatusReaders
+	}
+	return func(reader engine.ClusterReader, mapper meta.RESTMapper) (map[schema.GroupKind]engine.StatusReader, engine.StatusReader) {
+		readers, defaultReader := createStatusReaders(reader, mapper)
+		for gk, r := range options.CustomStatusReadersFactoryFunc(reader, mapper) {
+			readers[gk] = r
+		}
+		return readers, defaultReader
+	}
+}
+
 // Options defines the levers available for tuning the behavior of the
 // StatusPoller.
 type Options struct {
 	// PollInterval defines how often the PollerEngine should poll the cluster for the latest
-	// state of the resources.
+	// state of the resources. It is optional for the StatusWatcher, which computes status whenever
+	// a change is observed.
 	PollInterval time.Duration
 
 	// UseCache defines whether the ClusterReader should use LIST calls to fetch
@@ -86,11 +128,17 @@ func createStatusReaders(reader engine.ClusterReader, mapper meta.RESTMapper) (m
 	replicaSetStatusReader := statusreaders.NewReplicaSetStatusReader(reader, mapper, defaultStatusReader)
 	deploymentStatusReader := statusreaders.NewDeploymentResourceReader(reader, mapper, replicaSetStatusReader)
 	statefulSetStatusReader := statusreaders.NewStatefulSetResourceReader(reader, mapper, defaultStatusReader)
+	daemonSetStatusReader := statusreaders.NewDaemonSetResourceReader(reader, mapper, defaultStatusReader)
+	jobStatusReader := statusreaders.NewJobResourceReader(reader, mapper, defaultStatusReader)
+	cronJobStatusReader := statusreaders.NewCronJobResourceReader(reader, mapper, jobStatusReader)
 
 	statusReaders := map[schema.GroupKind]engine.StatusReader{
 		appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind():  deploymentStatusReader,
 		appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind(): statefulSetStatusReader,
 		appsv1.SchemeGroupVersion.WithKind("ReplicaSet").GroupKind():  replicaSetStatusReader,
+		appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind():   daemonSetStatusReader,
+		batchv1.SchemeGroupVersion.WithKind("Job").GroupKind():        jobStatusReader,
+		batchv1.SchemeGroupVersion.WithKind("CronJob").GroupKind():    cronJobStatusReader,
 	}
 
 	return statusReaders, defaultStatusReader
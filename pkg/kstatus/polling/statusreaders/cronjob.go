@@ -0,0 +1,125 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func NewCronJobResourceReader(reader engine.ClusterReader, mapper meta.RESTMapper, jobStatusReader resourceTypeStatusReader) engine.StatusReader {
+	return &baseStatusReader{
+		reader: reader,
+		mapper: mapper,
+		resourceStatusReader: &cronJobResourceReader{
+			reader:          reader,
+			mapper:          mapper,
+			jobStatusReader: jobStatusReader,
+		},
+	}
+}
+
+// cronJobResourceReader is a resourceTypeStatusReader that can fetch CronJob
+// resources from the cluster, knows how to find the most recent Job created
+// by the CronJob, and compute status for the CronJob.
+type cronJobResourceReader struct {
+	reader engine.ClusterReader
+	mapper meta.RESTMapper
+
+	// jobStatusReader is the implementation of the resourceTypeStatusReader
+	// that knows how to compute the status for Jobs.
+	jobStatusReader resourceTypeStatusReader
+}
+
+var _ resourceTypeStatusReader = &cronJobResourceReader{}
+
+func (c *cronJobResourceReader) ReadStatusForObject(ctx context.Context, cronJob *unstructured.Unstructured) *event.ResourceStatus {
+	identifier := object.UnstructuredToObjMetaOrDie(cronJob)
+
+	// Jobs don't have labels that select them from the CronJob, so the
+	// Jobs of the CronJob are found through their owner references.
+	job, err := c.latestJob(ctx, cronJob)
+	if err != nil {
+		return &event.ResourceStatus{
+			Identifier: identifier,
+			Status:     status.UnknownStatus,
+			Resource:   cronJob,
+			Error:      err,
+		}
+	}
+	var jobStatuses event.ResourceStatuses
+	if job != nil {
+		jobStatuses = append(jobStatuses, c.jobStatusReader.ReadStatusForObject(ctx, job))
+	}
+
+	res, err := status.Compute(cronJob)
+	if err != nil {
+		return &event.ResourceStatus{
+			Identifier:         identifier,
+			Status:             status.UnknownStatus,
+			Resource:           cronJob,
+			Error:              err,
+			GeneratedResources: jobStatuses,
+		}
+	}
+
+	return &event.ResourceStatus{
+		Identifier:         identifier,
+		Status:             res.Status,
+		Resource:           cronJob,
+		Message:            res.Message,
+		GeneratedResources: jobStatuses,
+	}
+}
+
+// latestJob returns the most recently created Job owned by the CronJob,
+// or nil if the CronJob doesn't own any Jobs.
+func (c *cronJobResourceReader) latestJob(ctx context.Context, cronJob *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	gvk, err := gvk(batchv1.SchemeGroupVersion.WithKind("Job").GroupKind(), c.mapper)
+	if err != nil {
+		return nil, err
+	}
+	var jobList unstructured.UnstructuredList
+	jobList.SetGroupVersionKind(gvk)
+	err = c.reader.ListNamespaceScoped(ctx, &jobList, cronJob.GetNamespace(), labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *unstructured.Unstructured
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if !isOwnedBy(job, cronJob) {
+			continue
+		}
+		if latest == nil {
+			latest = job
+			continue
+		}
+		latestCreated, jobCreated := latest.GetCreationTimestamp(), job.GetCreationTimestamp()
+		if latestCreated.Before(&jobCreated) {
+			latest = job
+		}
+	}
+	return latest, nil
+}
+
+// isOwnedBy returns true if the passed owner is in the owner references
+// of the object.
+func isOwnedBy(obj, owner *unstructured.Unstructured) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	fakemapper "sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var (
+	cronJobGVK = batchv1.SchemeGroupVersion.WithKind("CronJob")
+	jobGVK     = batchv1.SchemeGroupVersion.WithKind("Job")
+)
+
+func newJob(name string, owner types.UID, created time.Time) unstructured.Unstructured {
+	job := unstructured.Unstructured{}
+	job.SetGroupVersionKind(jobGVK)
+	job.SetName(name)
+	job.SetNamespace(namespace)
+	job.SetCreationTimestamp(metav1.NewTime(created))
+	job.SetOwnerReferences([]metav1.OwnerReference{{UID: owner}})
+	return job
+}
+
+func TestCronJobStatusReader(t *testing.T) {
+	now := time.Now()
+	testCases := map[string]struct {
+		jobs         []unstructured.Unstructured
+		expectedJobs []string
+	}{
+		"no jobs": {
+			expectedJobs: []string{},
+		},
+		"most recent owned job": {
+			jobs: []unstructured.Unstructured{
+				newJob("old", "cronjob-uid", now.Add(-2*time.Hour)),
+				newJob("latest", "cronjob-uid", now.Add(-time.Hour)),
+				newJob("other", "other-uid", now),
+			},
+			expectedJobs: []string{"latest"},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			cronJob := &unstructured.Unstructured{}
+			cronJob.SetGroupVersionKind(cronJobGVK)
+			cronJob.SetName(name)
+			cronJob.SetNamespace(namespace)
+			cronJob.SetUID("cronjob-uid")
+
+			fakeReader := &fakeClusterReader{
+				listResources: &unstructured.UnstructuredList{Items: tc.jobs},
+			}
+			fakeMapper := fakemapper.NewFakeRESTMapper(cronJobGVK, jobGVK)
+			statusReader := NewCronJobResourceReader(fakeReader, fakeMapper, &fakeStatusReader{})
+
+			resourceStatus := statusReader.ReadStatusForObject(context.Background(), cronJob)
+			assert.NoError(t, resourceStatus.Error)
+			assert.Equal(t, status.CurrentStatus, resourceStatus.Status)
+			jobs := []string{}
+			for _, rs := range resourceStatus.GeneratedResources {
+				jobs = append(jobs, rs.Identifier.Name)
+			}
+			assert.Equal(t, tc.expectedJobs, jobs)
+		})
+	}
+}
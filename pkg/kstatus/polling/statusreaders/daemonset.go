@@ -0,0 +1,41 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+)
+
+func NewDaemonSetResourceReader(reader engine.ClusterReader, mapper meta.RESTMapper, podResourceReader resourceTypeStatusReader) engine.StatusReader {
+	return &baseStatusReader{
+		reader: reader,
+		mapper: mapper,
+		resourceStatusReader: &daemonSetResourceReader{
+			reader:            reader,
+			mapper:            mapper,
+			podResourceReader: podResourceReader,
+		},
+	}
+}
+
+// daemonSetResourceReader is an implementation of the ResourceReader interface
+// that can fetch DaemonSet resources from the cluster, knows how to find any
+// Pods belonging to the DaemonSet, and compute status for the DaemonSet.
+type daemonSetResourceReader struct {
+	reader engine.ClusterReader
+	mapper meta.RESTMapper
+
+	podResourceReader resourceTypeStatusReader
+}
+
+var _ resourceTypeStatusReader = &daemonSetResourceReader{}
+
+func (d *daemonSetResourceReader) ReadStatusForObject(ctx context.Context, daemonSet *unstructured.Unstructured) *event.ResourceStatus {
+	return newPodControllerStatusReader(d.reader, d.mapper, d.podResourceReader).readStatus(ctx, daemonSet)
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	fakemapper "sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var (
+	daemonSetGVK = appsv1.SchemeGroupVersion.WithKind("DaemonSet")
+	podGVK       = corev1.SchemeGroupVersion.WithKind("Pod")
+)
+
+// newPod returns a running Pod that is ready, or whose container is in
+// a crash loop.
+func newPod(name string, crashLooping bool) unstructured.Unstructured {
+	pod := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"phase": "Running",
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   "Ready",
+						"status": "True",
+					},
+				},
+			},
+		},
+	}
+	if crashLooping {
+		pod.Object["status"] = map[string]interface{}{
+			"phase": "Running",
+			"containerStatuses": []interface{}{
+				map[string]interface{}{
+					"name": "app",
+					"state": map[string]interface{}{
+						"waiting": map[string]interface{}{
+							"reason": "CrashLoopBackOff",
+						},
+					},
+				},
+			},
+		}
+	}
+	pod.SetGroupVersionKind(podGVK)
+	pod.SetName(name)
+	pod.SetNamespace(namespace)
+	return pod
+}
+
+// newPodController returns a pod controller of the passed kind that
+// selects its pods with a label, and has the passed status.
+func newPodController(gvk schema.GroupVersionKind, controllerStatus map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{
+						"app": name,
+					},
+				},
+			},
+			"status": controllerStatus,
+		},
+	}
+	u.SetGroupVersionKind(gvk)
+	u.SetName(name)
+	u.SetNamespace(namespace)
+	return u
+}
+
+func TestDaemonSetStatusReader(t *testing.T) {
+	testCases := map[string]struct {
+		daemonSetStatus map[string]interface{}
+		pods            []unstructured.Unstructured
+		expectedStatus  status.Status
+		expectedPods    map[string]status.Status
+	}{
+		"all pods ready": {
+			daemonSetStatus: map[string]interface{}{
+				"desiredNumberScheduled": int64(2),
+				"currentNumberScheduled": int64(2),
+				"updatedNumberScheduled": int64(2),
+				"numberAvailable":        int64(2),
+				"numberReady":            int64(2),
+			},
+			pods: []unstructured.Unstructured{
+				newPod("pod-a", false),
+				newPod("pod-b", false),
+			},
+			expectedStatus: status.CurrentStatus,
+			expectedPods: map[string]status.Status{
+				"pod-a": status.CurrentStatus,
+				"pod-b": status.CurrentStatus,
+			},
+		},
+		"pod in crash loop": {
+			daemonSetStatus: map[string]interface{}{
+				"desiredNumberScheduled": int64(2),
+				"currentNumberScheduled": int64(2),
+				"updatedNumberScheduled": int64(2),
+				"numberAvailable":        int64(1),
+				"numberReady":            int64(1),
+			},
+			pods: []unstructured.Unstructured{
+				newPod("pod-a", false),
+				newPod("pod-b", true),
+			},
+			expectedStatus: status.FailedStatus,
+			expectedPods: map[string]status.Status{
+				"pod-a": status.CurrentStatus,
+				"pod-b": status.FailedStatus,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			daemonSet := newPodController(daemonSetGVK, tc.daemonSetStatus)
+			fakeReader := &fakeClusterReader{
+				listResources: &unstructured.UnstructuredList{Items: tc.pods},
+			}
+			fakeMapper := fakemapper.NewFakeRESTMapper(daemonSetGVK, podGVK)
+			podStatusReader := NewGenericStatusReader(fakeReader, fakeMapper, status.Compute)
+			statusReader := NewDaemonSetResourceReader(fakeReader, fakeMapper, podStatusReader)
+
+			resourceStatus := statusReader.ReadStatusForObject(context.Background(), daemonSet)
+			assert.NoError(t, resourceStatus.Error)
+			assert.Equal(t, tc.expectedStatus, resourceStatus.Status)
+			pods := map[string]status.Status{}
+			for _, rs := range resourceStatus.GeneratedResources {
+				pods[rs.Identifier.Name] = rs.Status
+			}
+			assert.Equal(t, tc.expectedPods, pods)
+		})
+	}
+}
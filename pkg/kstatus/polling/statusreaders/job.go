@@ -0,0 +1,75 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+func NewJobResourceReader(reader engine.ClusterReader, mapper meta.RESTMapper, podResourceReader resourceTypeStatusReader) engine.StatusReader {
+	return &baseStatusReader{
+		reader: reader,
+		mapper: mapper,
+		resourceStatusReader: &jobResourceReader{
+			reader:            reader,
+			mapper:            mapper,
+			podResourceReader: podResourceReader,
+		},
+	}
+}
+
+// jobResourceReader is an implementation of the ResourceReader interface
+// that can fetch Job resources from the cluster, knows how to find any
+// Pods belonging to the Job, and compute status for the Job.
+type jobResourceReader struct {
+	reader engine.ClusterReader
+	mapper meta.RESTMapper
+
+	podResourceReader resourceTypeStatusReader
+}
+
+var _ resourceTypeStatusReader = &jobResourceReader{}
+
+func (j *jobResourceReader) ReadStatusForObject(ctx context.Context, job *unstructured.Unstructured) *event.ResourceStatus {
+	resourceStatus := newPodControllerStatusReader(j.reader, j.mapper, j.podResourceReader).readStatus(ctx, job)
+	// A Job is Current as soon as it has started, so the pods of a Job
+	// that has not finished yet are checked for failures here.
+	if resourceStatus.Status != status.CurrentStatus || jobFinished(job) {
+		return resourceStatus
+	}
+	failedPods := 0
+	for _, podResourceStatus := range resourceStatus.GeneratedResources {
+		if podResourceStatus.Status == status.FailedStatus {
+			failedPods++
+		}
+	}
+	if failedPods > 0 {
+		resourceStatus.Status = status.FailedStatus
+		resourceStatus.Message = fmt.Sprintf("%d pods have failed", failedPods)
+	}
+	return resourceStatus
+}
+
+// jobFinished returns true if the passed Job has the Complete or the
+// Failed condition.
+func jobFinished(job *unstructured.Unstructured) bool {
+	objc, err := status.GetObjectWithConditions(job.UnstructuredContent())
+	if err != nil {
+		return false
+	}
+	for _, c := range objc.Status.Conditions {
+		if (c.Type == "Complete" || c.Type == "Failed") && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	fakemapper "sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestJobStatusReader(t *testing.T) {
+	testCases := map[string]struct {
+		jobStatus      map[string]interface{}
+		pods           []unstructured.Unstructured
+		expectedStatus status.Status
+		expectedPods   map[string]status.Status
+	}{
+		"running job": {
+			jobStatus: map[string]interface{}{
+				"startTime": "2021-01-01T00:00:00Z",
+				"active":    int64(1),
+			},
+			pods: []unstructured.Unstructured{
+				newPod("pod-a", false),
+			},
+			expectedStatus: status.CurrentStatus,
+			expectedPods: map[string]status.Status{
+				"pod-a": status.CurrentStatus,
+			},
+		},
+		"running job with pod in crash loop": {
+			jobStatus: map[string]interface{}{
+				"startTime": "2021-01-01T00:00:00Z",
+				"active":    int64(1),
+			},
+			pods: []unstructured.Unstructured{
+				newPod("pod-a", true),
+			},
+			expectedStatus: status.FailedStatus,
+			expectedPods: map[string]status.Status{
+				"pod-a": status.FailedStatus,
+			},
+		},
+		"completed job with pod in crash loop": {
+			jobStatus: map[string]interface{}{
+				"startTime": "2021-01-01T00:00:00Z",
+				"succeeded": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   "Complete",
+						"status": "True",
+					},
+				},
+			},
+			pods: []unstructured.Unstructured{
+				newPod("pod-a", true),
+			},
+			expectedStatus: status.CurrentStatus,
+			expectedPods: map[string]status.Status{
+				"pod-a": status.FailedStatus,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			job := newPodController(jobGVK, tc.jobStatus)
+			fakeReader := &fakeClusterReader{
+				listResources: &unstructured.UnstructuredList{Items: tc.pods},
+			}
+			fakeMapper := fakemapper.NewFakeRESTMapper(jobGVK, podGVK)
+			podStatusReader := NewGenericStatusReader(fakeReader, fakeMapper, status.Compute)
+			statusReader := NewJobResourceReader(fakeReader, fakeMapper, podStatusReader)
+
+			resourceStatus := statusReader.ReadStatusForObject(context.Background(), job)
+			assert.NoError(t, resourceStatus.Error)
+			assert.Equal(t, tc.expectedStatus, resourceStatus.Status)
+			pods := map[string]status.Status{}
+			for _, rs := range resourceStatus.GeneratedResources {
+				pods[rs.Identifier.Name] = rs.Status
+			}
+			assert.Equal(t, tc.expectedPods, pods)
+		})
+	}
+}
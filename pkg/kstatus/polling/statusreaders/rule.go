@@ -0,0 +1,50 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/engine"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// NewRuleStatusReader returns a StatusReader that computes the status
+// of resources with the declarative status.Rule, after the generic
+// checks done by status.Compute. It is meant for custom resources that
+// don't follow the kstatus conventions.
+func NewRuleStatusReader(reader engine.ClusterReader, mapper meta.RESTMapper, rule status.Rule) (engine.StatusReader, error) {
+	registry := status.NewRegistry()
+	if err := registry.RegisterRules(rule); err != nil {
+		return nil, err
+	}
+	return NewGenericStatusReader(reader, mapper, registry.Compute), nil
+}
+
+// NewExpressionStatusReader returns a StatusReader that computes the
+// status of resources with the given GroupKind with the expressions of
+// the status.ExpressionPolicy.
+func NewExpressionStatusReader(reader engine.ClusterReader, mapper meta.RESTMapper, gk schema.GroupKind,
+	policy status.ExpressionPolicy) (engine.StatusReader, error) {
+	return NewRuleStatusReader(reader, mapper, policy.Rule(gk))
+}
+
+// RuleStatusReadersFactoryFunc returns a function that creates a
+// StatusReader for each of the passed rules. It can be used as the
+// CustomStatusReadersFactoryFunc of the polling options. An error is
+// returned if any of the rules is not valid.
+func RuleStatusReadersFactoryFunc(rules ...status.Rule) (func(engine.ClusterReader, meta.RESTMapper) map[schema.GroupKind]engine.StatusReader, error) {
+	registry := status.NewRegistry()
+	if err := registry.RegisterRules(rules...); err != nil {
+		return nil, err
+	}
+	return func(reader engine.ClusterReader, mapper meta.RESTMapper) map[schema.GroupKind]engine.StatusReader {
+		statusReader := NewGenericStatusReader(reader, mapper, registry.Compute)
+		statusReaders := make(map[schema.GroupKind]engine.StatusReader)
+		for _, rule := range rules {
+			statusReaders[rule.GroupKind()] = statusReader
+		}
+		return statusReaders
+	}, nil
+}
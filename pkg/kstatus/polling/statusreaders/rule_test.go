@@ -0,0 +1,73 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package statusreaders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/testutil"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	fakemapper "sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestExpressionStatusReader(t *testing.T) {
+	testCases := map[string]struct {
+		phase          string
+		expectedStatus status.Status
+	}{
+		"current": {
+			phase:          "Ready",
+			expectedStatus: status.CurrentStatus,
+		},
+		"failed": {
+			phase:          "Error",
+			expectedStatus: status.FailedStatus,
+		},
+		"in progress": {
+			phase:          "Provisioning",
+			expectedStatus: status.InProgressStatus,
+		},
+	}
+
+	policy := status.ExpressionPolicy{
+		Current: "status.phase == 'Ready'",
+		Failed:  "status.phase == 'Error'",
+	}
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			o := &unstructured.Unstructured{}
+			o.SetGroupVersionKind(customGVK)
+			o.SetName(name)
+			o.SetNamespace(namespace)
+			_ = unstructured.SetNestedField(o.Object, tc.phase, "status", "phase")
+
+			fakeReader := &fakeClusterReader{getResource: o}
+			fakeMapper := fakemapper.NewFakeRESTMapper(customGVK)
+			statusReader, err := NewExpressionStatusReader(fakeReader, fakeMapper, customGVK.GroupKind(), policy)
+			assert.NoError(t, err)
+
+			resourceStatus := statusReader.ReadStatusForObject(context.Background(), o)
+			assert.NoError(t, resourceStatus.Error)
+			assert.Equal(t, tc.expectedStatus, resourceStatus.Status)
+		})
+	}
+}
+
+func TestRuleStatusReadersFactoryFunc(t *testing.T) {
+	_, err := RuleStatusReadersFactoryFunc(status.ExpressionPolicy{
+		Current: "status.phase ==",
+	}.Rule(customGVK.GroupKind()))
+	assert.Error(t, err)
+
+	factoryFunc, err := RuleStatusReadersFactoryFunc(status.ExpressionPolicy{
+		Current: "status.phase == 'Ready'",
+	}.Rule(customGVK.GroupKind()))
+	assert.NoError(t, err)
+	statusReaders := factoryFunc(testutil.NewNoopClusterReader(), fakemapper.NewFakeRESTMapper())
+	assert.Len(t, statusReaders, 1)
+	assert.Contains(t, statusReaders, customGVK.GroupKind())
+}
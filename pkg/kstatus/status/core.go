@@ -4,6 +4,7 @@
 package status
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"strings"
@@ -36,6 +37,14 @@ var legacyTypes = map[string]GetConditionsFn{
 	"ConfigMap":                  alwaysReady,
 	"batch/Job":                  jobConditions,
 	"apiextensions.k8s.io/CustomResourceDefinition": crdConditions,
+	"networking.k8s.io/Ingress":                     ingressConditions,
+	"extensions/Ingress":                            ingressConditions,
+	"autoscaling/HorizontalPodAutoscaler":           hpaConditions,
+	"PersistentVolume":                              pvConditions,
+	"storage.k8s.io/StorageClass":                   alwaysReady,
+	"Namespace":                                     namespaceConditions,
+	"Endpoints":                                     endpointsConditions,
+	"apiregistration.k8s.io/APIService":             apiServiceConditions,
 }
 
 const (
@@ -47,6 +56,10 @@ const (
 
 	onDeleteUpdateStrategy = "OnDelete"
 
+	// hpaConditionsAnnotation is the annotation holding the conditions
+	// of autoscaling/v1 HorizontalPodAutoscalers.
+	hpaConditionsAnnotation = "autoscaling.alpha.kubernetes.io/conditions"
+
 	// How long a pod can be unscheduled before it is reported as
 	// unschedulable.
 	scheduleWindow = 15 * time.Second
@@ -583,3 +596,168 @@ func crdConditions(u *unstructured.Unstructured) (*Result, error) {
 	}
 	return newInProgressStatus("Installing", "Install in progress"), nil
 }
+
+// ingressConditions return standardized Conditions for Ingress
+//
+// An Ingress is current once the ingress controller has assigned it a
+// load balancer.
+func ingressConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+
+	lbIngress, _, err := unstructured.NestedSlice(obj, "status", "loadBalancer", "ingress")
+	if err != nil {
+		return nil, err
+	}
+	if len(lbIngress) == 0 {
+		return newInProgressStatus("NoLoadBalancer", "Ingress has no load balancer assigned"), nil
+	}
+	return &Result{
+		Status:     CurrentStatus,
+		Message:    "Ingress has a load balancer assigned",
+		Conditions: []Condition{},
+	}, nil
+}
+
+// hpaConditions return standardized Conditions for HorizontalPodAutoscaler
+//
+// The autoscaling/v1 API version of the HorizontalPodAutoscaler has no
+// conditions in its status, and exposes them in an annotation instead.
+func hpaConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+	objc, err := GetObjectWithConditions(obj)
+	if err != nil {
+		return nil, err
+	}
+	conditions := objc.Status.Conditions
+	if value, found := u.GetAnnotations()[hpaConditionsAnnotation]; found && len(conditions) == 0 {
+		if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+			return nil, fmt.Errorf("unable to parse %s annotation: %w", hpaConditionsAnnotation, err)
+		}
+	}
+
+	ableToScale, foundAbleToScale := getCondition(conditions, "AbleToScale")
+	scalingActive, foundScalingActive := getCondition(conditions, "ScalingActive")
+	if !foundAbleToScale && !foundScalingActive {
+		return newInProgressStatus("NoConditions", "HorizontalPodAutoscaler has not been observed"), nil
+	}
+
+	if foundAbleToScale && ableToScale.Status == corev1.ConditionFalse {
+		// The autoscaler can't get or update the scale of its target.
+		// Other reasons, like backoff, are transient.
+		switch ableToScale.Reason {
+		case "FailedGetScale", "FailedUpdateScale":
+			return newFailedStatus(ableToScale.Reason, ableToScale.Message), nil
+		}
+		return newInProgressStatus(ableToScale.Reason, ableToScale.Message), nil
+	}
+
+	// The autoscaler is disabled when the target is scaled to zero.
+	if foundScalingActive && scalingActive.Status == corev1.ConditionFalse &&
+		scalingActive.Reason != "ScalingDisabled" {
+		return newInProgressStatus(scalingActive.Reason, scalingActive.Message), nil
+	}
+
+	return &Result{
+		Status:     CurrentStatus,
+		Message:    "HorizontalPodAutoscaler is active",
+		Conditions: []Condition{},
+	}, nil
+}
+
+// pvConditions return standardized Conditions for PersistentVolume
+func pvConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+
+	phase := GetStringField(obj, ".status.phase", "unknown")
+	switch phase {
+	case "Available", "Bound", "Released": // corev1.VolumeAvailable, corev1.VolumeBound, corev1.VolumeReleased
+		return &Result{
+			Status:     CurrentStatus,
+			Message:    fmt.Sprintf("PV is %s", phase),
+			Conditions: []Condition{},
+		}, nil
+	case "Failed": // corev1.VolumeFailed
+		message := GetStringField(obj, ".status.message", "PV reclamation failed")
+		return newFailedStatus(GetStringField(obj, ".status.reason", "VolumeFailed"), message), nil
+	default:
+		message := fmt.Sprintf("PV is not Available. phase: %s", phase)
+		return newInProgressStatus("NotAvailable", message), nil
+	}
+}
+
+// namespaceConditions return standardized Conditions for Namespace
+func namespaceConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+
+	phase := GetStringField(obj, ".status.phase", "")
+	switch phase {
+	case "Active": // corev1.NamespaceActive
+		return &Result{
+			Status:     CurrentStatus,
+			Message:    "Namespace is Active",
+			Conditions: []Condition{},
+		}, nil
+	case "Terminating": // corev1.NamespaceTerminating
+		return &Result{
+			Status:     TerminatingStatus,
+			Message:    "Namespace is Terminating",
+			Conditions: []Condition{},
+		}, nil
+	default:
+		return newInProgressStatus("NotActive", "Namespace is not Active"), nil
+	}
+}
+
+// endpointsConditions return standardized Conditions for Endpoints
+//
+// Endpoints are current once they have at least one ready address.
+func endpointsConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+
+	subsets, _, err := unstructured.NestedSlice(obj, "subsets")
+	if err != nil {
+		return nil, err
+	}
+	ready := 0
+	for _, subset := range subsets {
+		s, ok := subset.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		addresses, _, err := unstructured.NestedSlice(s, "addresses")
+		if err != nil {
+			return nil, err
+		}
+		ready += len(addresses)
+	}
+	if ready == 0 {
+		return newInProgressStatus("NoReadyAddresses", "Endpoints have no ready addresses"), nil
+	}
+	return &Result{
+		Status:     CurrentStatus,
+		Message:    fmt.Sprintf("Endpoints have %d ready addresses", ready),
+		Conditions: []Condition{},
+	}, nil
+}
+
+// apiServiceConditions return standardized Conditions for APIService
+func apiServiceConditions(u *unstructured.Unstructured) (*Result, error) {
+	obj := u.UnstructuredContent()
+	objc, err := GetObjectWithConditions(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	available, found := getCondition(objc.Status.Conditions, "Available")
+	if found && available.Status == corev1.ConditionTrue {
+		return &Result{
+			Status:     CurrentStatus,
+			Message:    "APIService is Available",
+			Conditions: []Condition{},
+		}, nil
+	}
+	if found {
+		return newInProgressStatus(available.Reason, available.Message), nil
+	}
+	return newInProgressStatus("NotAvailable", "APIService is not Available"), nil
+}
@@ -0,0 +1,497 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Expression is a boolean expression over the fields of a resource. The
+// syntax is a small subset of CEL:
+//
+//	status.phase == 'Ready' && status.readyReplicas >= spec.replicas
+//	!has(status.error) || status.conditions[0].status == "True"
+//	{.status.conditions[?(@.type=="Ready")].status} == 'True'
+//
+// Operands are fields, given as paths from the root of the resource or
+// as JSONPath templates in braces, and string, number, boolean and null
+// literals. Operands can be compared with ==, !=, <, <=, > and >=, and
+// the comparisons combined with &&, || and !. A field that doesn't exist
+// is equal to null, and is never less or greater than another value.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// ParseExpression parses the passed expression.
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse expression %q: %w", source, err)
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse expression %q: %w", source, err)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate returns the value of the expression for the passed object.
+// An error is returned if the value is not a boolean.
+func (e *Expression) Evaluate(obj map[string]interface{}) (bool, error) {
+	b, err := evalBool(e.root, obj)
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate expression %q: %w", e.source, err)
+	}
+	return b, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenJSONPath
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// operators are the operators of the expression language, longest first.
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", ".", "[", "]"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexRune(source[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[i+1 : i+1+end]})
+			i += end + 2
+		case c == '{':
+			end := strings.IndexRune(source[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated JSONPath")
+			}
+			tokens = append(tokens, token{kind: tokenJSONPath, text: source[i : i+end+1]})
+			i += end + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1]))):
+			start := i
+			i++
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i]})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(source) && (source[i] == '_' || unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i]})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(source[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser for expressions.
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF, text: "end of expression"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// accept consumes the next token if it is the passed operator.
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q, found %q", op, p.peek().text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.accept("||") {
+		var right exprNode
+		right, err = p.parseAnd()
+		left = orNode{left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.accept("&&") {
+		var right exprNode
+		right, err = p.parseUnary()
+		left = andNode{left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		return notNode{operand: operand}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokenOperator {
+		switch t.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseOperand()
+			return compareNode{op: t.text, left: left, right: right}, err
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return literalNode{value: t.text}, nil
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return literalNode{value: f}, nil
+	case tokenJSONPath:
+		parser := jsonpath.New("expression").AllowMissingKeys(true)
+		if err := parser.Parse(t.text); err != nil {
+			return nil, fmt.Errorf("invalid JSONPath %q: %w", t.text, err)
+		}
+		return jsonPathNode{parser: parser}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		case "has":
+			if p.accept("(") {
+				operand, err := p.parseOperand()
+				if err != nil {
+					return nil, err
+				}
+				return hasNode{operand: operand}, p.expect(")")
+			}
+		}
+		return p.parsePath(t.text)
+	case tokenOperator:
+		if t.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (p *exprParser) parsePath(first string) (exprNode, error) {
+	path := []interface{}{first}
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokenIdent {
+				return nil, fmt.Errorf("expected field name, found %q", t.text)
+			}
+			path = append(path, t.text)
+		case p.accept("["):
+			t := p.next()
+			switch t.kind {
+			case tokenString:
+				path = append(path, t.text)
+			case tokenNumber:
+				index, err := strconv.Atoi(t.text)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index %q", t.text)
+				}
+				path = append(path, index)
+			default:
+				return nil, fmt.Errorf("expected index, found %q", t.text)
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		default:
+			return pathNode{path: path}, nil
+		}
+	}
+}
+
+// exprNode is a node of a parsed expression. Eval returns the value of
+// the node and false if the value is a field that doesn't exist.
+type exprNode interface {
+	eval(obj map[string]interface{}) (interface{}, bool, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(map[string]interface{}) (interface{}, bool, error) {
+	return n.value, true, nil
+}
+
+type pathNode struct {
+	// path holds the field names and the slice indexes of the path.
+	path []interface{}
+}
+
+func (n pathNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	var current interface{} = obj
+	for _, p := range n.path {
+		switch key := p.(type) {
+		case string:
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if current, ok = m[key]; !ok {
+				return nil, false, nil
+			}
+		case int:
+			s, ok := current.([]interface{})
+			if !ok || key >= len(s) {
+				return nil, false, nil
+			}
+			current = s[key]
+		}
+	}
+	return current, true, nil
+}
+
+type jsonPathNode struct {
+	parser *jsonpath.JSONPath
+}
+
+func (n jsonPathNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	results, err := n.parser.FindResults(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		return nil, false, nil
+	}
+	return results[0][0].Interface(), true, nil
+}
+
+type hasNode struct {
+	operand exprNode
+}
+
+func (n hasNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	_, found, err := n.operand.eval(obj)
+	return found, true, err
+}
+
+type notNode struct {
+	operand exprNode
+}
+
+func (n notNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	b, err := evalBool(n.operand, obj)
+	return !b, true, err
+}
+
+type andNode struct {
+	left, right exprNode
+}
+
+func (n andNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	b, err := evalBool(n.left, obj)
+	if err != nil || !b {
+		return false, true, err
+	}
+	b, err = evalBool(n.right, obj)
+	return b, true, err
+}
+
+type orNode struct {
+	left, right exprNode
+}
+
+func (n orNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	b, err := evalBool(n.left, obj)
+	if err != nil || b {
+		return b, true, err
+	}
+	b, err = evalBool(n.right, obj)
+	return b, true, err
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n compareNode) eval(obj map[string]interface{}) (interface{}, bool, error) {
+	left, leftFound, err := n.left.eval(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	right, rightFound, err := n.right.eval(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), true, nil
+	case "!=":
+		return !equal(left, right), true, nil
+	}
+	if !leftFound || !rightFound {
+		return false, true, nil
+	}
+	cmp, err := compare(left, right)
+	if err != nil {
+		return nil, false, err
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, true, nil
+	case "<=":
+		return cmp <= 0, true, nil
+	case ">":
+		return cmp > 0, true, nil
+	default:
+		return cmp >= 0, true, nil
+	}
+}
+
+// evalBool evaluates the passed node, which must have a boolean value. A
+// field that doesn't exist is false.
+func evalBool(n exprNode, obj map[string]interface{}) (bool, error) {
+	val, found, err := n.eval(obj)
+	if err != nil || !found {
+		return false, err
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("%v is not a boolean", val)
+	}
+	return b, nil
+}
+
+func equal(left, right interface{}) bool {
+	if l, ok := toFloat(left); ok {
+		r, ok := toFloat(right)
+		return ok && l == r
+	}
+	switch l := left.(type) {
+	case nil:
+		return right == nil
+	case string, bool:
+		return l == right
+	default:
+		return false
+	}
+}
+
+func compare(left, right interface{}) (int, error) {
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	}
+	return 0, fmt.Errorf("unable to compare %v and %v", left, right)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
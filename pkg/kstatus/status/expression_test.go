@@ -0,0 +1,126 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pipeline = `
+apiVersion: example.com/v1
+kind: Pipeline
+metadata:
+   name: test
+   labels:
+      app-name: pipeline
+spec:
+   replicas: 3
+status:
+   phase: Ready
+   readyReplicas: 3
+   stages:
+   - name: build
+     done: true
+   conditions:
+   - type: Ready
+     status: "True"
+`
+
+func TestExpression(t *testing.T) {
+	testCases := map[string]struct {
+		expression string
+		expected   bool
+		isError    bool
+	}{
+		"string equal": {
+			expression: "status.phase == 'Ready'",
+			expected:   true,
+		},
+		"string not equal": {
+			expression: `status.phase != "Ready"`,
+			expected:   false,
+		},
+		"number compare fields": {
+			expression: "status.readyReplicas >= spec.replicas",
+			expected:   true,
+		},
+		"number compare literal": {
+			expression: "spec.replicas < 2.5",
+			expected:   false,
+		},
+		"index and bool field": {
+			expression: "status.stages[0].done",
+			expected:   true,
+		},
+		"bracket key": {
+			expression: "metadata.labels['app-name'] == 'pipeline'",
+			expected:   true,
+		},
+		"missing field is null": {
+			expression: "status.error == null",
+			expected:   true,
+		},
+		"missing field is never greater": {
+			expression: "status.missing > 1",
+			expected:   false,
+		},
+		"has": {
+			expression: "has(status.phase) && !has(status.error)",
+			expected:   true,
+		},
+		"or and parentheses": {
+			expression: "(status.phase == 'Error' || status.phase == 'Ready') && true",
+			expected:   true,
+		},
+		"jsonpath": {
+			expression: `{.status.conditions[?(@.type=="Ready")].status} == 'True'`,
+			expected:   true,
+		},
+		"missing jsonpath": {
+			expression: `{.status.conditions[?(@.type=="Stalled")].status} == 'True'`,
+			expected:   false,
+		},
+		"not a boolean": {
+			expression: "status.phase",
+			isError:    true,
+		},
+		"incomparable types": {
+			expression: "status.phase < 3",
+			isError:    true,
+		},
+	}
+
+	obj := y2u(t, pipeline).Object
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			expr, err := ParseExpression(tc.expression)
+			assert.NoError(t, err)
+			actual, err := expr.Evaluate(obj)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParseExpressionInvalid(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"status.phase ==",
+		"status.phase == 'Ready",
+		"(status.phase == 'Ready'",
+		"status.phase == 'Ready' 'Error'",
+		"status.[0]",
+		"status.phase = 'Ready'",
+		"{.status.phase",
+	} {
+		_, err := ParseExpression(expression)
+		assert.Errorf(t, err, "expected error for %q", expression)
+	}
+}
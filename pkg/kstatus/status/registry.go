@@ -0,0 +1,77 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Registry holds functions to compute the status of resources that are
+// not built-in types, like the custom resources of an operator. The
+// functions are looked up by GroupKind.
+type Registry struct {
+	mu  sync.RWMutex
+	fns map[schema.GroupKind]GetConditionsFn
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		fns: make(map[schema.GroupKind]GetConditionsFn),
+	}
+}
+
+// DefaultRegistry is the Registry used by Compute.
+var DefaultRegistry = NewRegistry()
+
+// Register registers the function to compute the status of resources
+// with the given GroupKind in the DefaultRegistry.
+func Register(gk schema.GroupKind, fn GetConditionsFn) {
+	DefaultRegistry.Register(gk, fn)
+}
+
+// Register registers the function to compute the status of resources
+// with the given GroupKind. It replaces any function previously
+// registered for the GroupKind, and takes precedence over the rules
+// for the built-in types.
+func (r *Registry) Register(gk schema.GroupKind, fn GetConditionsFn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fns[gk] = fn
+}
+
+// Unregister removes the function registered for the given GroupKind.
+func (r *Registry) Unregister(gk schema.GroupKind) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.fns, gk)
+}
+
+// Get returns the function registered for the given GroupKind, or nil
+// if there is none.
+func (r *Registry) Get(gk schema.GroupKind) GetConditionsFn {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.fns[gk]
+}
+
+// GetConditionsFn returns the function that computes the status for the
+// given resource. A function registered in the Registry is returned
+// before the one for a built-in type. Nil is returned if the resource
+// type is not known.
+func (r *Registry) GetConditionsFn(u *unstructured.Unstructured) GetConditionsFn {
+	if fn := r.Get(u.GroupVersionKind().GroupKind()); fn != nil {
+		return fn
+	}
+	return GetLegacyConditionsFn(u)
+}
+
+// Compute finds the status of the given resource like the Compute
+// function, using the functions registered in the Registry.
+func (r *Registry) Compute(u *unstructured.Unstructured) (*Result, error) {
+	return compute(u, r.GetConditionsFn(u))
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var widget = `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+   name: test
+   generation: 1
+status:
+   observedGeneration: 1
+   phase: Pending
+`
+
+func widgetConditions(u *unstructured.Unstructured) (*Result, error) {
+	if GetStringField(u.Object, ".status.phase", "") == "Ready" {
+		return &Result{Status: CurrentStatus, Message: "Widget is ready"}, nil
+	}
+	return newInProgressStatus("WidgetPending", "Widget is pending"), nil
+}
+
+func TestRegistry(t *testing.T) {
+	gk := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	r := NewRegistry()
+
+	res, err := r.Compute(y2u(t, widget))
+	assert.NoError(t, err)
+	assert.Equal(t, CurrentStatus, res.Status)
+
+	r.Register(gk, widgetConditions)
+	res, err = r.Compute(y2u(t, widget))
+	assert.NoError(t, err)
+	assert.Equal(t, InProgressStatus, res.Status)
+	assert.Equal(t, "Widget is pending", res.Message)
+
+	// The generic checks still come first.
+	u := y2u(t, widget)
+	u.SetGeneration(2)
+	res, err = r.Compute(u)
+	assert.NoError(t, err)
+	assert.Equal(t, InProgressStatus, res.Status)
+	assert.NotEqual(t, "Widget is pending", res.Message)
+
+	r.Unregister(gk)
+	assert.Nil(t, r.Get(gk))
+}
+
+func TestRegistryOverridesBuiltInTypes(t *testing.T) {
+	r := NewRegistry()
+	r.Register(schema.GroupKind{Kind: "Secret"}, widgetConditions)
+	res, err := r.Compute(y2u(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+   name: test
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, InProgressStatus, res.Status)
+}
+
+func TestComputeUsesDefaultRegistry(t *testing.T) {
+	gk := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	Register(gk, widgetConditions)
+	defer DefaultRegistry.Unregister(gk)
+
+	res, err := Compute(y2u(t, widget))
+	assert.NoError(t, err)
+	assert.Equal(t, InProgressStatus, res.Status)
+}
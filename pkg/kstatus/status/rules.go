@@ -0,0 +1,379 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// StatusRulesAnnotation is the annotation on a CustomResourceDefinition
+// that declares the Rule for its custom resources. The group and kind of
+// the rule default to those of the CRD.
+const StatusRulesAnnotation = "kstatus.cli-utils.sigs.k8s.io/status-rules"
+
+const (
+	// ruleMatchedReason is the reason of the conditions set on the
+	// result when a Rule matches, if the match didn't include a condition.
+	ruleMatchedReason = "StatusRuleMatched"
+	// ruleNotCurrentReason is the reason of the conditions set on the
+	// result when none of the Current matches of a Rule hold.
+	ruleNotCurrentReason = "StatusRuleNotCurrent"
+)
+
+// Rule declares how the status of the resources with the given group and
+// kind is computed. The Failed matches are checked first, then the
+// InProgress matches and then the Current matches. The resource has the
+// status of the first list with a Match that holds. If none of them
+// hold, the resource is InProgress when Current matches are declared,
+// and Current otherwise.
+//
+// A rule for cert-manager certificates looks like:
+//
+//	group: cert-manager.io
+//	kind: Certificate
+//	current:
+//	- conditions:
+//	  - type: Ready
+//	failed:
+//	- conditions:
+//	  - type: Issuing
+//	    status: "False"
+//	    reason: Failed
+type Rule struct {
+	Group      string  `json:"group,omitempty"`
+	Kind       string  `json:"kind"`
+	Current    []Match `json:"current,omitempty"`
+	InProgress []Match `json:"inProgress,omitempty"`
+	Failed     []Match `json:"failed,omitempty"`
+}
+
+// Match holds when all of its conditions and fields match the resource,
+// and its Expression, if any, is true.
+type Match struct {
+	Conditions []ConditionMatch `json:"conditions,omitempty"`
+	Fields     []FieldMatch     `json:"fields,omitempty"`
+	// Expression is an Expression over the fields of the resource, like
+	// status.phase == 'Ready'.
+	Expression string `json:"expression,omitempty"`
+}
+
+// ConditionMatch matches a condition in .status.conditions of the
+// resource. Status defaults to True, and an empty Reason matches any
+// reason.
+type ConditionMatch struct {
+	Type   string                 `json:"type"`
+	Status corev1.ConditionStatus `json:"status,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// FieldMatch matches the field at Path, for example .status.phase. The
+// field must be equal to Value, or to the field at ValueFrom. If neither
+// is set, the field must exist. Values are compared in their string
+// form, so 3 matches "3".
+type FieldMatch struct {
+	Path      string `json:"path"`
+	Value     string `json:"value,omitempty"`
+	ValueFrom string `json:"valueFrom,omitempty"`
+}
+
+// ExpressionPolicy declares the status of resources as expressions over
+// the resource, for example:
+//
+//	current: status.phase == 'Ready'
+//	failed: status.phase == 'Error'
+//
+// See Expression for the syntax. An empty expression is ignored.
+type ExpressionPolicy struct {
+	Current    string `json:"current,omitempty"`
+	InProgress string `json:"inProgress,omitempty"`
+	Failed     string `json:"failed,omitempty"`
+}
+
+// Rule returns the Rule with the expressions of the policy for resources
+// with the given GroupKind.
+func (p ExpressionPolicy) Rule(gk schema.GroupKind) Rule {
+	rule := Rule{Group: gk.Group, Kind: gk.Kind}
+	if p.Current != "" {
+		rule.Current = []Match{{Expression: p.Current}}
+	}
+	if p.InProgress != "" {
+		rule.InProgress = []Match{{Expression: p.InProgress}}
+	}
+	if p.Failed != "" {
+		rule.Failed = []Match{{Expression: p.Failed}}
+	}
+	return rule
+}
+
+// GroupKind returns the GroupKind of the resources the rule applies to.
+func (r Rule) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: r.Group, Kind: r.Kind}
+}
+
+// Validate returns an error if the rule is not well-formed.
+func (r Rule) Validate() error {
+	if r.Kind == "" {
+		return fmt.Errorf("status rule must have a kind")
+	}
+	for _, list := range []struct {
+		name    string
+		matches []Match
+	}{
+		{"current", r.Current},
+		{"inProgress", r.InProgress},
+		{"failed", r.Failed},
+	} {
+		for _, m := range list.matches {
+			if err := m.validate(); err != nil {
+				return fmt.Errorf("invalid %s match in status rule for %s: %w", list.name, r.GroupKind(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m Match) validate() error {
+	if len(m.Conditions) == 0 && len(m.Fields) == 0 && m.Expression == "" {
+		return fmt.Errorf("match must have conditions, fields or an expression")
+	}
+	if m.Expression != "" {
+		if _, err := ParseExpression(m.Expression); err != nil {
+			return err
+		}
+	}
+	for _, c := range m.Conditions {
+		if c.Type == "" {
+			return fmt.Errorf("condition must have a type")
+		}
+		switch c.Status {
+		case "", corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown:
+		default:
+			return fmt.Errorf("condition %s has invalid status %q", c.Type, c.Status)
+		}
+	}
+	for _, f := range m.Fields {
+		if f.Path == "" {
+			return fmt.Errorf("field must have a path")
+		}
+		if f.Value != "" && f.ValueFrom != "" {
+			return fmt.Errorf("field %s can't have both value and valueFrom", f.Path)
+		}
+	}
+	return nil
+}
+
+// ConditionsFn returns a function that computes the status of resources
+// using the rule.
+func (r Rule) ConditionsFn() GetConditionsFn {
+	return func(u *unstructured.Unstructured) (*Result, error) {
+		objWithConditions, err := GetObjectWithConditions(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		conditions := objWithConditions.Status.Conditions
+
+		cond, ok, err := firstMatch(r.Failed, u.Object, conditions)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return newFailedStatus(reasonOrDefault(cond.Reason), messageOrDefault(cond.Message, "Resource has failed")), nil
+		}
+		cond, ok, err = firstMatch(r.InProgress, u.Object, conditions)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return newInProgressStatus(reasonOrDefault(cond.Reason), messageOrDefault(cond.Message, "Resource is in progress")), nil
+		}
+		cond, ok, err = firstMatch(r.Current, u.Object, conditions)
+		if err != nil {
+			return nil, err
+		}
+		if ok || len(r.Current) == 0 {
+			return &Result{
+				Status:     CurrentStatus,
+				Message:    messageOrDefault(cond.Message, "Resource is current"),
+				Conditions: []Condition{},
+			}, nil
+		}
+		return newInProgressStatus(ruleNotCurrentReason, "Resource is not current"), nil
+	}
+}
+
+// firstMatch returns true if one of the passed matches holds for the
+// object, together with the first condition matched by it, if any.
+func firstMatch(matches []Match, obj map[string]interface{}, conditions []BasicCondition) (BasicCondition, bool, error) {
+	for _, m := range matches {
+		cond, ok, err := m.match(obj, conditions)
+		if err != nil || ok {
+			return cond, ok, err
+		}
+	}
+	return BasicCondition{}, false, nil
+}
+
+func (m Match) match(obj map[string]interface{}, conditions []BasicCondition) (BasicCondition, bool, error) {
+	var first BasicCondition
+	for i, c := range m.Conditions {
+		cond, found := c.match(conditions)
+		if !found {
+			return BasicCondition{}, false, nil
+		}
+		if i == 0 {
+			first = cond
+		}
+	}
+	for _, f := range m.Fields {
+		if !f.match(obj) {
+			return BasicCondition{}, false, nil
+		}
+	}
+	if m.Expression != "" {
+		// The expression is parsed on every match, since matches are
+		// plain data.
+		expr, err := ParseExpression(m.Expression)
+		if err != nil {
+			return BasicCondition{}, false, err
+		}
+		if ok, err := expr.Evaluate(obj); err != nil || !ok {
+			return BasicCondition{}, false, err
+		}
+	}
+	return first, true, nil
+}
+
+func (c ConditionMatch) match(conditions []BasicCondition) (BasicCondition, bool) {
+	status := c.Status
+	if status == "" {
+		status = corev1.ConditionTrue
+	}
+	for _, cond := range conditions {
+		if cond.Type == c.Type && cond.Status == status && (c.Reason == "" || cond.Reason == c.Reason) {
+			return cond, true
+		}
+	}
+	return BasicCondition{}, false
+}
+
+func (f FieldMatch) match(obj map[string]interface{}) bool {
+	val, found := getField(obj, f.Path)
+	if !found {
+		return false
+	}
+	switch {
+	case f.ValueFrom != "":
+		other, found := getField(obj, f.ValueFrom)
+		return found && fmt.Sprint(val) == fmt.Sprint(other)
+	case f.Value != "":
+		return fmt.Sprint(val) == f.Value
+	default:
+		return true
+	}
+}
+
+// getField returns the field at the given path, like .status.phase.
+func getField(obj map[string]interface{}, fieldPath string) (interface{}, bool) {
+	fields := strings.Split(fieldPath, ".")
+	if fields[0] == "" {
+		fields = fields[1:]
+	}
+	val, found, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if !found || err != nil {
+		return nil, false
+	}
+	return val, true
+}
+
+func reasonOrDefault(reason string) string {
+	if reason == "" {
+		return ruleMatchedReason
+	}
+	return reason
+}
+
+func messageOrDefault(message, defaultMessage string) string {
+	if message == "" {
+		return defaultMessage
+	}
+	return message
+}
+
+// ParseRules parses a YAML or JSON list of rules.
+func ParseRules(data []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("unable to parse status rules: %w", err)
+	}
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// LoadRulesFile reads the list of rules in the file at the given path.
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// RuleFromCRD returns the rule declared in the StatusRulesAnnotation of
+// the passed CustomResourceDefinition. False is returned if the CRD has
+// no such annotation.
+func RuleFromCRD(crd *unstructured.Unstructured) (Rule, bool, error) {
+	value, found := crd.GetAnnotations()[StatusRulesAnnotation]
+	if !found || value == "" {
+		return Rule{}, false, nil
+	}
+	var rule Rule
+	if err := yaml.Unmarshal([]byte(value), &rule); err != nil {
+		return Rule{}, false, fmt.Errorf("unable to parse %s annotation on %s: %w",
+			StatusRulesAnnotation, crd.GetName(), err)
+	}
+	if rule.Group == "" {
+		rule.Group = GetStringField(crd.Object, ".spec.group", "")
+	}
+	if rule.Kind == "" {
+		rule.Kind = GetStringField(crd.Object, ".spec.names.kind", "")
+	}
+	if err := rule.Validate(); err != nil {
+		return Rule{}, false, err
+	}
+	return rule, true, nil
+}
+
+// RegisterRules registers the passed rules in the DefaultRegistry.
+func RegisterRules(rules ...Rule) error {
+	return DefaultRegistry.RegisterRules(rules...)
+}
+
+// RegisterRules registers the passed rules. No rule is registered if
+// any of them is not valid.
+func (r *Registry) RegisterRules(rules ...Rule) error {
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, rule := range rules {
+		r.Register(rule.GroupKind(), rule.ConditionsFn())
+	}
+	return nil
+}
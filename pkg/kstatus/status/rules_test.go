@@ -0,0 +1,188 @@
+// Copyright 2021 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package status
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var certificateRule = `
+- group: cert-manager.io
+  kind: Certificate
+  current:
+  - conditions:
+    - type: Ready
+  failed:
+  - conditions:
+    - type: Issuing
+      status: "False"
+      reason: Failed
+`
+
+var certificate = `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+   name: test
+status:
+   conditions:
+   - type: %s
+     status: "%s"
+     reason: %s
+     message: certificate message
+`
+
+var replicasRule = `
+- group: example.com
+  kind: Widget
+  current:
+  - fields:
+    - path: .status.replicas
+      valueFrom: .spec.replicas
+    - path: .status.phase
+      value: Running
+`
+
+var widgetReplicas = `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+   name: test
+spec:
+   replicas: 3
+status:
+   replicas: %d
+   phase: Running
+`
+
+func TestRuleConditionsFn(t *testing.T) {
+	testCases := map[string]struct {
+		rules           string
+		spec            string
+		expectedStatus  Status
+		expectedMessage string
+	}{
+		"condition matches current": {
+			rules:           certificateRule,
+			spec:            fmt.Sprintf(certificate, "Ready", "True", "Ready"),
+			expectedStatus:  CurrentStatus,
+			expectedMessage: "certificate message",
+		},
+		"condition matches failed": {
+			rules:           certificateRule,
+			spec:            fmt.Sprintf(certificate, "Issuing", "False", "Failed"),
+			expectedStatus:  FailedStatus,
+			expectedMessage: "certificate message",
+		},
+		"no match is in progress": {
+			rules:           certificateRule,
+			spec:            fmt.Sprintf(certificate, "Ready", "False", "Pending"),
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "Resource is not current",
+		},
+		"fields match current": {
+			rules:           replicasRule,
+			spec:            fmt.Sprintf(widgetReplicas, 3),
+			expectedStatus:  CurrentStatus,
+			expectedMessage: "Resource is current",
+		},
+		"fields don't match": {
+			rules:           replicasRule,
+			spec:            fmt.Sprintf(widgetReplicas, 2),
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "Resource is not current",
+		},
+		"expression matches failed": {
+			rules: `
+- group: example.com
+  kind: Widget
+  current:
+  - expression: status.replicas == spec.replicas
+  failed:
+  - expression: status.phase == 'Crashed' || status.replicas == 0
+`,
+			spec:            fmt.Sprintf(widgetReplicas, 0),
+			expectedStatus:  FailedStatus,
+			expectedMessage: "Resource has failed",
+		},
+		"no current matches is current": {
+			rules: `
+- group: example.com
+  kind: Widget
+  failed:
+  - fields:
+    - path: .status.phase
+      value: Crashed
+`,
+			spec:            fmt.Sprintf(widgetReplicas, 2),
+			expectedStatus:  CurrentStatus,
+			expectedMessage: "Resource is current",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			rules, err := ParseRules([]byte(tc.rules))
+			assert.NoError(t, err)
+			r := NewRegistry()
+			assert.NoError(t, r.RegisterRules(rules...))
+
+			res, err := r.Compute(y2u(t, tc.spec))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, res.Status)
+			assert.Equal(t, tc.expectedMessage, res.Message)
+		})
+	}
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"not a list":     `kind: Widget`,
+		"no kind":        `[{"group": "example.com"}]`,
+		"empty match":    `[{"kind": "Widget", "current": [{}]}]`,
+		"no type":        `[{"kind": "Widget", "current": [{"conditions": [{"status": "True"}]}]}]`,
+		"invalid status": `[{"kind": "Widget", "current": [{"conditions": [{"type": "Ready", "status": "Yes"}]}]}]`,
+		"no path":        `[{"kind": "Widget", "failed": [{"fields": [{"value": "Failed"}]}]}]`,
+		"value and from": `[{"kind": "Widget", "failed": [{"fields": [{"path": ".a", "value": "b", "valueFrom": ".c"}]}]}]`,
+	}
+
+	for tn, data := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			_, err := ParseRules([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRuleFromCRD(t *testing.T) {
+	crd := y2u(t, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+  annotations:
+    kstatus.cli-utils.sigs.k8s.io/status-rules: |
+      current:
+      - conditions:
+        - type: Ready
+spec:
+  group: cert-manager.io
+  names:
+    kind: Certificate
+`)
+	rule, found, err := RuleFromCRD(crd)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}, rule.GroupKind())
+	assert.Len(t, rule.Current, 1)
+
+	crd.SetAnnotations(nil)
+	_, found, err = RuleFromCRD(crd)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
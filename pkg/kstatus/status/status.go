@@ -98,7 +98,17 @@ type Condition struct {
 // It also contains a message that provides more information on why
 // the resource has the given status. Finally, the result also contains
 // a list of standard resources that would belong on the given resource.
+//
+// The status of resources that are not built-in types can be computed
+// by functions registered in the DefaultRegistry.
 func Compute(u *unstructured.Unstructured) (*Result, error) {
+	return DefaultRegistry.Compute(u)
+}
+
+// compute finds the status of the given resource, using the passed
+// function for the type-specific rules. The function can be nil if the
+// resource type is not known.
+func compute(u *unstructured.Unstructured, fn GetConditionsFn) (*Result, error) {
 	res, err := checkGenericProperties(u)
 	if err != nil {
 		return nil, err
@@ -111,7 +121,6 @@ func Compute(u *unstructured.Unstructured) (*Result, error) {
 		return res, nil
 	}
 
-	fn := GetLegacyConditionsFn(u)
 	if fn != nil {
 		return fn(u)
 	}
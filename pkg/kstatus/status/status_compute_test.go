@@ -1495,3 +1495,463 @@ func TestCRDStatus(t *testing.T) {
 		})
 	}
 }
+
+var ingressNoStatus = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+`
+
+var ingressLB = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+   name: test
+   namespace: qual
+   generation: 1
+status:
+   loadBalancer:
+      ingress:
+      - ip: 1.2.3.4
+`
+
+func TestIngressStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"ingressNoStatus": {
+			spec:           ingressNoStatus,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NoLoadBalancer",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"ingressLB": {
+			spec:               ingressLB,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
+
+var hpaNoStatus = `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+`
+
+var hpaActive = `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+status:
+   conditions:
+   - type: AbleToScale
+     status: "True"
+     reason: ReadyForNewScale
+   - type: ScalingActive
+     status: "True"
+     reason: ValidMetricFound
+`
+
+var hpaNoMetrics = `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+status:
+   conditions:
+   - type: AbleToScale
+     status: "True"
+     reason: SucceededGetScale
+   - type: ScalingActive
+     status: "False"
+     reason: FailedGetResourceMetric
+`
+
+var hpaScalingDisabled = `
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+status:
+   conditions:
+   - type: AbleToScale
+     status: "True"
+     reason: SucceededGetScale
+   - type: ScalingActive
+     status: "False"
+     reason: ScalingDisabled
+`
+
+var hpaV1NoTarget = `
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+   name: test
+   namespace: qual
+   annotations:
+      autoscaling.alpha.kubernetes.io/conditions: '[{"type":"AbleToScale","status":"False","reason":"FailedGetScale"}]'
+`
+
+func TestHPAStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"hpaNoStatus": {
+			spec:           hpaNoStatus,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NoConditions",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"hpaActive": {
+			spec:               hpaActive,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"hpaNoMetrics": {
+			spec:           hpaNoMetrics,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "FailedGetResourceMetric",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"hpaScalingDisabled": {
+			spec:               hpaScalingDisabled,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"hpaV1NoTarget": {
+			spec:           hpaV1NoTarget,
+			expectedStatus: FailedStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionStalled,
+				Status: corev1.ConditionTrue,
+				Reason: "FailedGetScale",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionReconciling,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
+
+var pvPending = `
+apiVersion: v1
+kind: PersistentVolume
+metadata:
+   name: test
+status:
+   phase: Pending
+`
+
+var pvBound = `
+apiVersion: v1
+kind: PersistentVolume
+metadata:
+   name: test
+status:
+   phase: Bound
+`
+
+var pvFailed = `
+apiVersion: v1
+kind: PersistentVolume
+metadata:
+   name: test
+status:
+   phase: Failed
+   message: recycler failed
+`
+
+func TestPVStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"pvPending": {
+			spec:           pvPending,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NotAvailable",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"pvBound": {
+			spec:               pvBound,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"pvFailed": {
+			spec:           pvFailed,
+			expectedStatus: FailedStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionStalled,
+				Status: corev1.ConditionTrue,
+				Reason: "VolumeFailed",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionReconciling,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
+
+var namespaceNoStatus = `
+apiVersion: v1
+kind: Namespace
+metadata:
+   name: test
+`
+
+var namespaceActive = `
+apiVersion: v1
+kind: Namespace
+metadata:
+   name: test
+status:
+   phase: Active
+`
+
+var namespaceTerminating = `
+apiVersion: v1
+kind: Namespace
+metadata:
+   name: test
+status:
+   phase: Terminating
+`
+
+func TestNamespaceStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"namespaceNoStatus": {
+			spec:           namespaceNoStatus,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NotActive",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"namespaceActive": {
+			spec:               namespaceActive,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"namespaceTerminating": {
+			spec:               namespaceTerminating,
+			expectedStatus:     TerminatingStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
+
+var endpointsNoSubsets = `
+apiVersion: v1
+kind: Endpoints
+metadata:
+   name: test
+   namespace: qual
+`
+
+var endpointsNotReady = `
+apiVersion: v1
+kind: Endpoints
+metadata:
+   name: test
+   namespace: qual
+subsets:
+- notReadyAddresses:
+  - ip: 10.0.0.1
+`
+
+var endpointsReady = `
+apiVersion: v1
+kind: Endpoints
+metadata:
+   name: test
+   namespace: qual
+subsets:
+- addresses:
+  - ip: 10.0.0.1
+  - ip: 10.0.0.2
+`
+
+func TestEndpointsStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"endpointsNoSubsets": {
+			spec:           endpointsNoSubsets,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NoReadyAddresses",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"endpointsNotReady": {
+			spec:           endpointsNotReady,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "NoReadyAddresses",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+		"endpointsReady": {
+			spec:               endpointsReady,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
+
+var apiServiceAvailable = `
+apiVersion: apiregistration.k8s.io/v1
+kind: APIService
+metadata:
+   name: v1beta1.metrics.k8s.io
+status:
+   conditions:
+   - type: Available
+     status: "True"
+     reason: Passed
+`
+
+var apiServiceUnavailable = `
+apiVersion: apiregistration.k8s.io/v1
+kind: APIService
+metadata:
+   name: v1beta1.metrics.k8s.io
+status:
+   conditions:
+   - type: Available
+     status: "False"
+     reason: MissingEndpoints
+`
+
+func TestAPIServiceStatus(t *testing.T) {
+	testCases := map[string]testSpec{
+		"apiServiceAvailable": {
+			spec:               apiServiceAvailable,
+			expectedStatus:     CurrentStatus,
+			expectedConditions: []Condition{},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+				ConditionReconciling,
+			},
+		},
+		"apiServiceUnavailable": {
+			spec:           apiServiceUnavailable,
+			expectedStatus: InProgressStatus,
+			expectedConditions: []Condition{{
+				Type:   ConditionReconciling,
+				Status: corev1.ConditionTrue,
+				Reason: "MissingEndpoints",
+			}},
+			absentConditionTypes: []ConditionType{
+				ConditionStalled,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			runStatusTest(t, tc)
+		})
+	}
+}
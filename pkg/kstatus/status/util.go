@@ -99,6 +99,15 @@ func getConditionWithStatus(conditions []BasicCondition, conditionType string, s
 	return BasicCondition{}, false
 }
 
+func getCondition(conditions []BasicCondition, conditionType string) (BasicCondition, bool) {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c, true
+		}
+	}
+	return BasicCondition{}, false
+}
+
 // GetStringField return field as string defaulting to value if not found
 func GetStringField(obj map[string]interface{}, fieldPath string, defaultValue string) string {
 	var rv = defaultValue
@@ -29,6 +29,14 @@ const (
 	NamespacesField = "namespaces"
 )
 
+// WaitForAnnotation is the annotation used to override the condition
+// that must be met before an applied object is considered reconciled.
+// The supported values are:
+//   none                       (do not wait for the object)
+//   condition=<type>           (wait until the named condition is True)
+//   jsonpath={<path>}=<value>  (wait until the field has the given value)
+const WaitForAnnotation = "config.kubernetes.io/wait-for"
+
 // HasAnnotation returns the annotation value and true if the passed annotation
 // is present in the as one of the keys in the annotations map for the passed
 // object; empty string and false otherwise.
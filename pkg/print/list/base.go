@@ -16,6 +16,10 @@ type Formatter interface {
 	FormatStatusEvent(se event.StatusEvent) error
 	FormatPruneEvent(pe event.PruneEvent) error
 	FormatDeleteEvent(de event.DeleteEvent) error
+	FormatRollbackEvent(re event.RollbackEvent) error
+	FormatHookEvent(he event.HookEvent) error
+	FormatDriftEvent(de event.DriftEvent) error
+	FormatInventoryEvent(ie event.InventoryEvent) error
 	FormatErrorEvent(ee event.ErrorEvent) error
 	FormatActionGroupEvent(age event.ActionGroupEvent, ags []event.ActionGroup, as *ApplyStats, ps *PruneStats, ds *DeleteStats, c Collector) error
 }
@@ -94,6 +98,54 @@ func (d *DeleteStats) incFailed() {
 	d.Failed++
 }
 
+// RollbackStats counts the rollback failures. The rolled back objects
+// are reported by the formatters for each RollbackEvent.
+type RollbackStats struct {
+	Failed int
+}
+
+func (r *RollbackStats) incFailed() {
+	r.Failed++
+}
+
+type HookStats struct {
+	Succeeded int
+	Failed    int
+}
+
+func (h *HookStats) incSucceeded() {
+	h.Succeeded++
+}
+
+func (h *HookStats) incFailed() {
+	h.Failed++
+}
+
+type DriftStats struct {
+	InSync  int
+	Drifted int
+	Missing int
+	Failed  int
+}
+
+func (d *DriftStats) inc(op event.DriftEventOperation) {
+	switch op {
+	case event.DriftUnspecified:
+	case event.InSync:
+		d.InSync++
+	case event.Drifted:
+		d.Drifted++
+	case event.DriftMissing:
+		d.Missing++
+	default:
+		panic(fmt.Errorf("unknown drift operation %s", op.String()))
+	}
+}
+
+func (d *DriftStats) incFailed() {
+	d.Failed++
+}
+
 type Collector interface {
 	LatestStatus() map[object.ObjMetadata]event.StatusEvent
 }
@@ -120,9 +172,15 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 	applyStats := &ApplyStats{}
 	pruneStats := &PruneStats{}
 	deleteStats := &DeleteStats{}
+	rollbackStats := &RollbackStats{}
+	hookStats := &HookStats{}
+	driftStats := &DriftStats{}
 	statusCollector := &StatusCollector{
 		latestStatus: make(map[object.ObjMetadata]event.StatusEvent),
 	}
+	// failedSum counts the failures of the inventories already
+	// processed, when a tree of nested inventories is processed.
+	failedSum := 0
 	formatter := b.FormatterFactory(previewStrategy)
 	for e := range ch {
 		switch e.Type {
@@ -172,6 +230,44 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 			if err := formatter.FormatDeleteEvent(e.DeleteEvent); err != nil {
 				return err
 			}
+		case event.RollbackType:
+			if e.RollbackEvent.Error != nil {
+				rollbackStats.incFailed()
+			}
+			if err := formatter.FormatRollbackEvent(e.RollbackEvent); err != nil {
+				return err
+			}
+		case event.HookType:
+			switch e.HookEvent.Operation {
+			case event.HookSucceeded:
+				hookStats.incSucceeded()
+			case event.HookFailed:
+				hookStats.incFailed()
+			}
+			if err := formatter.FormatHookEvent(e.HookEvent); err != nil {
+				return err
+			}
+		case event.DriftType:
+			driftStats.inc(e.DriftEvent.Operation)
+			if e.DriftEvent.Error != nil {
+				driftStats.incFailed()
+			}
+			if err := formatter.FormatDriftEvent(e.DriftEvent); err != nil {
+				return err
+			}
+		case event.InventoryType:
+			// The stats are reported for each inventory of the tree.
+			failedSum += applyStats.Failed + pruneStats.Failed + deleteStats.Failed + rollbackStats.Failed +
+				hookStats.Failed + driftStats.Failed
+			applyStats = &ApplyStats{}
+			pruneStats = &PruneStats{}
+			deleteStats = &DeleteStats{}
+			rollbackStats = &RollbackStats{}
+			hookStats = &HookStats{}
+			driftStats = &DriftStats{}
+			if err := formatter.FormatInventoryEvent(e.InventoryEvent); err != nil {
+				return err
+			}
 		case event.ActionGroupType:
 			if err := formatter.FormatActionGroupEvent(e.ActionGroupEvent, actionGroups, applyStats,
 				pruneStats, deleteStats, statusCollector); err != nil {
@@ -179,7 +275,8 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 			}
 		}
 	}
-	failedSum := applyStats.Failed + pruneStats.Failed + deleteStats.Failed
+	failedSum += applyStats.Failed + pruneStats.Failed + deleteStats.Failed + rollbackStats.Failed +
+		hookStats.Failed + driftStats.Failed
 	if failedSum > 0 {
 		return fmt.Errorf("%d resources failed", failedSum)
 	}
@@ -136,3 +136,36 @@ func (d dependsOnMutator) Mutate(u *unstructured.Unstructured) {
 		d.t.FailNow()
 	}
 }
+
+// AddWaitFor returns a Mutator which adds the passed value as the
+// wait-for annotation to the object which is mutated.
+func AddWaitFor(t *testing.T, value string) Mutator {
+	return waitForMutator{
+		t:     t,
+		value: value,
+	}
+}
+
+// waitForMutator encapsulates the fields for adding the wait-for
+// annotation to a test object. Implements the Mutator interface.
+type waitForMutator struct {
+	t     *testing.T
+	value string
+}
+
+// Mutate for waitForMutator adds the wait-for annotation to the
+// passed mutated object.
+func (w waitForMutator) Mutate(u *unstructured.Unstructured) {
+	annos, found, err := unstructured.NestedStringMap(u.Object, "metadata", "annotations")
+	if !assert.NoError(w.t, err) {
+		w.t.FailNow()
+	}
+	if !found {
+		annos = make(map[string]string)
+	}
+	annos[object.WaitForAnnotation] = w.value
+	err = unstructured.SetNestedStringMap(u.Object, annos, "metadata", "annotations")
+	if !assert.NoError(w.t, err) {
+		w.t.FailNow()
+	}
+}
@@ -32,3 +32,19 @@ func NewStatusPoller(f cmdutil.Factory) (*polling.StatusPoller, error) {
 
 	return polling.NewStatusPoller(c, mapper), nil
 }
+
+// NewStatusWatcher creates a new StatusWatcher instance from the
+// passed in factory.
+func NewStatusWatcher(f cmdutil.Factory) (*polling.StatusWatcher, error) {
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("error getting RESTMapper: %w", err)
+	}
+
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %w", err)
+	}
+
+	return polling.NewStatusWatcher(dynamicClient, mapper), nil
+}